@@ -1,11 +1,20 @@
 package binding
 
 import (
+	"container/list"
+	"context"
+	"database/sql/driver"
 	"fmt"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"gobatis/logger"
+	"gobatis/reflection"
+	"gobatis/types"
 )
 
 // ParameterBinder 参数绑定器接口
@@ -14,123 +23,437 @@ type ParameterBinder interface {
 }
 
 // DefaultParameterBinder 默认参数绑定器
-type DefaultParameterBinder struct{}
+type DefaultParameterBinder struct {
+	typeHandlers   *types.TypeHandlerRegistry
+	namingStrategy reflection.NamingStrategy
+	logger         logger.Interface
+}
+
+// Options 参数绑定器选项，零值字段使用默认值
+type Options struct {
+	TypeHandlers   *types.TypeHandlerRegistry
+	NamingStrategy reflection.NamingStrategy
+	Logger         logger.Interface
+}
 
 // NewParameterBinder 创建新的参数绑定器
 func NewParameterBinder() ParameterBinder {
-	return &DefaultParameterBinder{}
+	return NewParameterBinderWithOptions(Options{})
 }
 
-// BindParameters 绑定参数
-func (b *DefaultParameterBinder) BindParameters(sql string, parameter interface{}) (string, []interface{}, error) {
-	if parameter == nil {
-		return sql, nil, nil
+// NewParameterBinderWithTypeHandlers 创建使用指定类型处理器注册表的参数绑定器
+func NewParameterBinderWithTypeHandlers(typeHandlers *types.TypeHandlerRegistry) ParameterBinder {
+	return NewParameterBinderWithOptions(Options{TypeHandlers: typeHandlers})
+}
+
+// NewParameterBinderWithOptions 创建使用指定选项的参数绑定器
+func NewParameterBinderWithOptions(options Options) ParameterBinder {
+	if options.TypeHandlers == nil {
+		options.TypeHandlers = types.Default
+	}
+	if options.NamingStrategy == nil {
+		options.NamingStrategy = reflection.DefaultNamingStrategy
+	}
+	if options.Logger == nil {
+		options.Logger = logger.Default
+	}
+	return &DefaultParameterBinder{
+		typeHandlers:   options.TypeHandlers,
+		namingStrategy: options.NamingStrategy,
+		logger:         options.Logger,
+	}
+}
+
+// parameterExpression 参数表达式 #{name,jdbcType=VARCHAR,typeHandler=json}
+type parameterExpression struct {
+	name        string
+	jdbcType    string
+	typeHandler string
+}
+
+// parseParameterExpression 解析参数表达式
+func parseParameterExpression(expression string) parameterExpression {
+	parts := strings.Split(expression, ",")
+	expr := parameterExpression{name: strings.TrimSpace(parts[0])}
+
+	for _, part := range parts[1:] {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(kv[1])
+		switch strings.TrimSpace(kv[0]) {
+		case "jdbcType":
+			expr.jdbcType = value
+		case "typeHandler":
+			expr.typeHandler = value
+		}
+	}
+
+	return expr
+}
+
+// convertParameter 使用类型处理器转换参数值
+func (b *DefaultParameterBinder) convertParameter(value interface{}, expr parameterExpression) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	if expr.typeHandler != "" {
+		handler, exists := b.typeHandlers.GetNamedHandler(expr.typeHandler)
+		if !exists {
+			return nil, fmt.Errorf("type handler not found: %s", expr.typeHandler)
+		}
+		return handler.SetParameter(value)
+	}
+
+	// driver.Valuer 由驱动自行转换，原样传递
+	if _, ok := value.(driver.Valuer); ok {
+		return value, nil
+	}
+
+	if handler, exists := b.typeHandlers.GetHandler(reflect.TypeOf(value), expr.jdbcType); exists {
+		return handler.SetParameter(value)
 	}
 
-	// 查找所有的具名参数 #{paramName}
-	re := regexp.MustCompile(`#\{([^}]+)\}`)
-	matches := re.FindAllStringSubmatch(sql, -1)
+	return value, nil
+}
+
+// placeholderPattern 具名参数占位符 #{paramName}
+var placeholderPattern = regexp.MustCompile(`#\{([^}]+)\}`)
+
+// Plan 解析后的语句占位符计划，可在多次绑定间复用
+type Plan struct {
+	source      string                // 原始 SQL
+	sql         string                // 占位符替换为 ? 后的 SQL
+	expressions []parameterExpression // 按出现顺序排列的参数表达式
+}
+
+// NewPlan 解析 SQL 中的 #{} 占位符
+func NewPlan(sql string) *Plan {
+	matches := placeholderPattern.FindAllStringSubmatch(sql, -1)
+	plan := &Plan{source: sql, sql: sql}
+	if len(matches) > 0 {
+		plan.sql = placeholderPattern.ReplaceAllLiteralString(sql, "?")
+		plan.expressions = make([]parameterExpression, len(matches))
+		for i, match := range matches {
+			plan.expressions[i] = parseParameterExpression(match[1])
+		}
+	}
+	return plan
+}
+
+// Source 返回解析前的 SQL
+func (p *Plan) Source() string {
+	return p.source
+}
+
+// PlanBinder 可按预先解析的计划绑定参数的绑定器，执行器优先使用语句缓存的计划
+type PlanBinder interface {
+	BindPlan(plan *Plan, parameter interface{}) (string, []interface{}, error)
+}
+
+// planCacheSize BindParameters 按 SQL 文本缓存的计划数量上限
+const planCacheSize = 512
+
+// planCache BindParameters 按 SQL 文本缓存的计划，超出上限时淘汰最久未使用的计划
+var planCache = newPlanLRU(planCacheSize)
+
+// getPlaceholderPlan 获取 SQL 的占位符计划
+func getPlaceholderPlan(sql string) *Plan {
+	if plan, ok := planCache.get(sql); ok {
+		return plan
+	}
+	plan := NewPlan(sql)
+	planCache.add(sql, plan)
+	return plan
+}
+
+// planLRU 有容量上限的计划缓存
+type planLRU struct {
+	mutex    sync.Mutex
+	capacity int
+	order    *list.List // 最近使用的在前，元素值为 *Plan
+	entries  map[string]*list.Element
+}
+
+func newPlanLRU(capacity int) *planLRU {
+	return &planLRU{capacity: capacity, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (c *planLRU) get(sql string) (*Plan, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	element, ok := c.entries[sql]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*Plan), true
+}
+
+func (c *planLRU) add(sql string, plan *Plan) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if element, ok := c.entries[sql]; ok {
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[sql] = c.order.PushFront(plan)
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*Plan).source)
+	}
+}
+
+// len 当前缓存的计划数量
+func (c *planLRU) len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.order.Len()
+}
+
+// structParam 结构体参数字段
+type structParam struct {
+	name        string
+	index       []int
+	typeHandler string
+}
 
-	if len(matches) == 0 {
+// structParams 结构体参数名到字段的映射
+type structParams struct {
+	names  map[string]structParam
+	folded map[string][]string // 小写参数名 -> 参数名，按字段声明顺序
+}
+
+// paramsKey 结构体参数缓存键
+type paramsKey struct {
+	structType     reflect.Type
+	namingStrategy reflection.NamingStrategy
+}
+
+// structParamCache 按类型和命名策略缓存的结构体参数字段
+var structParamCache sync.Map // map[paramsKey]*structParams
+
+// getStructParams 获取结构体参数名到字段的映射，字段可按 db 标签、字段名或命名策略转换后的列名引用
+func getStructParams(t reflect.Type, namingStrategy reflection.NamingStrategy) *structParams {
+	cacheable := reflect.TypeOf(namingStrategy).Comparable()
+	key := paramsKey{structType: t}
+	if cacheable {
+		key.namingStrategy = namingStrategy
+		if cached, ok := structParamCache.Load(key); ok {
+			return cached.(*structParams)
+		}
+	}
+
+	params := &structParams{
+		names:  make(map[string]structParam),
+		folded: make(map[string][]string),
+	}
+	fields := reflection.StructFields(t)
+	for _, field := range fields {
+		// 获取字段名，优先使用 db 标签
+		fieldName := field.Name
+		if field.Tag.Name != "" {
+			fieldName = field.Tag.Name
+		}
+		fieldName = field.Prefix + fieldName
+
+		params.names[fieldName] = structParam{
+			name:        field.Name,
+			index:       field.Index,
+			typeHandler: field.Tag.TypeHandler(),
+		}
+		lower := strings.ToLower(fieldName)
+		params.folded[lower] = append(params.folded[lower], fieldName)
+	}
+
+	// 未声明标签的字段也可按命名策略转换后的列名引用，如 #{user_id}
+	for _, field := range fields {
+		if field.Tag.Name != "" {
+			continue
+		}
+		columnName := field.Prefix + namingStrategy.ColumnName(field.Name)
+		if _, exists := params.names[columnName]; exists {
+			continue
+		}
+		params.names[columnName] = params.names[field.Prefix+field.Name]
+		if lower := strings.ToLower(columnName); lower != strings.ToLower(field.Prefix+field.Name) {
+			params.folded[lower] = append(params.folded[lower], columnName)
+		}
+	}
+
+	if !cacheable {
+		return params
+	}
+	cached, _ := structParamCache.LoadOrStore(key, params)
+	return cached.(*structParams)
+}
+
+// lookup 按参数名查找字段，精确匹配优先，其次忽略大小写匹配
+func (b *DefaultParameterBinder) lookup(params *structParams, structType reflect.Type, name string) (structParam, bool) {
+	if param, exists := params.names[name]; exists {
+		return param, true
+	}
+
+	candidates := params.folded[strings.ToLower(name)]
+	if len(candidates) == 0 {
+		return structParam{}, false
+	}
+	param := params.names[candidates[0]]
+	if len(candidates) > 1 {
+		b.logger.Warn(context.Background(), "parameter %s matches multiple fields of %s ignoring case %v, using %s",
+			name, structType, candidates, param.name)
+	}
+	return param, true
+}
+
+// BindParameters 绑定参数，计划按 SQL 文本缓存在有上限的 LRU 中
+func (b *DefaultParameterBinder) BindParameters(sql string, parameter interface{}) (string, []interface{}, error) {
+	if parameter == nil {
 		return sql, nil, nil
 	}
+	return b.BindPlan(getPlaceholderPlan(sql), parameter)
+}
 
-	var args []interface{}
-	processedSQL := sql
+// BindPlan 实现 PlanBinder
+func (b *DefaultParameterBinder) BindPlan(plan *Plan, parameter interface{}) (string, []interface{}, error) {
+	if parameter == nil || len(plan.expressions) == 0 {
+		return plan.source, nil, nil
+	}
 
 	// 根据参数类型处理
+	var args []interface{}
+	var err error
 	switch v := parameter.(type) {
 	case map[string]interface{}:
-		args, processedSQL = b.bindMapParameters(sql, matches, v)
+		args, err = b.bindMapParameters(plan, v)
+	case *AdditionalParameters:
+		args, err = b.bindAdditionalParameters(plan, v)
 	default:
-		var err error
-		args, processedSQL, err = b.bindStructParameters(sql, matches, parameter)
-		if err != nil {
-			return "", nil, err
-		}
+		args, err = b.bindStructParameters(plan, parameter)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	return plan.sql, args, nil
+}
+
+// AdditionalParameters 在原参数之外附加具名参数，同名时附加参数优先，
+// 插件改写 SQL 时可借此绑定新增的占位符
+type AdditionalParameters struct {
+	Parameter  interface{}
+	Additional map[string]interface{}
+}
+
+// bindAdditionalParameters 先按原参数绑定，再以附加参数覆盖同名占位符
+func (b *DefaultParameterBinder) bindAdditionalParameters(plan *Plan, params *AdditionalParameters) ([]interface{}, error) {
+	args := make([]interface{}, len(plan.expressions))
+	var err error
+	switch v := params.Parameter.(type) {
+	case nil:
+	case map[string]interface{}:
+		args, err = b.bindMapParameters(plan, v)
+	default:
+		args, err = b.bindStructParameters(plan, v)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return processedSQL, args, nil
+	for i, expr := range plan.expressions {
+		value, exists := params.Additional[expr.name]
+		if !exists {
+			continue
+		}
+		if args[i], err = b.convertParameter(value, expr); err != nil {
+			return nil, fmt.Errorf("failed to convert parameter %s: %w", expr.name, err)
+		}
+	}
+	return args, nil
 }
 
 // bindMapParameters 绑定 Map 参数
-func (b *DefaultParameterBinder) bindMapParameters(sql string, matches [][]string, params map[string]interface{}) ([]interface{}, string) {
-	var args []interface{}
-	processedSQL := sql
+func (b *DefaultParameterBinder) bindMapParameters(plan *Plan, params map[string]interface{}) ([]interface{}, error) {
+	args := make([]interface{}, len(plan.expressions))
 
-	for _, match := range matches {
-		paramName := strings.TrimSpace(match[1])
-		if value, exists := params[paramName]; exists {
-			args = append(args, value)
-			processedSQL = strings.Replace(processedSQL, match[0], "?", 1)
-		} else {
-			args = append(args, nil)
-			processedSQL = strings.Replace(processedSQL, match[0], "?", 1)
+	for i, expr := range plan.expressions {
+		value, err := b.convertParameter(params[expr.name], expr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert parameter %s: %w", expr.name, err)
 		}
+		args[i] = value
 	}
 
-	return args, processedSQL
+	return args, nil
 }
 
 // bindStructParameters 绑定结构体参数
-func (b *DefaultParameterBinder) bindStructParameters(sql string, matches [][]string, parameter interface{}) ([]interface{}, string, error) {
-	var args []interface{}
-	processedSQL := sql
+func (b *DefaultParameterBinder) bindStructParameters(plan *Plan, parameter interface{}) ([]interface{}, error) {
+	args := make([]interface{}, len(plan.expressions))
 
 	v := reflect.ValueOf(parameter)
-	t := reflect.TypeOf(parameter)
 
 	// 如果是指针，获取实际值
 	if v.Kind() == reflect.Ptr {
 		if v.IsNil() {
-			return nil, "", fmt.Errorf("parameter is nil pointer")
+			return nil, fmt.Errorf("parameter is nil pointer")
 		}
 		v = v.Elem()
-		t = t.Elem()
 	}
 
-	// 如果是基础类型，直接使用
-	if isBasicType(v.Kind()) {
-		for _, match := range matches {
-			args = append(args, parameter)
-			processedSQL = strings.Replace(processedSQL, match[0], "?", 1)
+	// 如果是基础类型或驱动可直接处理的值，直接使用
+	if isBasicType(v.Kind()) || isDriverValue(parameter) {
+		for i, expr := range plan.expressions {
+			value, err := b.convertParameter(parameter, expr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert parameter %s: %w", expr.name, err)
+			}
+			args[i] = value
 		}
-		return args, processedSQL, nil
+		return args, nil
 	}
 
 	// 如果是结构体，按字段名绑定
 	if v.Kind() == reflect.Struct {
-		fieldMap := make(map[string]interface{})
+		params := getStructParams(v.Type(), b.namingStrategy)
 
-		for i := 0; i < v.NumField(); i++ {
-			field := t.Field(i)
-			fieldValue := v.Field(i)
-
-			// 获取字段名，优先使用 db 标签
-			fieldName := field.Name
-			if dbTag := field.Tag.Get("db"); dbTag != "" {
-				fieldName = dbTag
-			}
-
-			if fieldValue.CanInterface() {
-				fieldMap[fieldName] = fieldValue.Interface()
+		for i, expr := range plan.expressions {
+			var value interface{}
+			if param, exists := b.lookup(params, v.Type(), expr.name); exists {
+				// 嵌入的结构体指针为 nil 时，其字段按 nil 绑定
+				if fieldValue, ok := reflection.FieldByIndex(v, param.index, false); ok {
+					value = fieldValue.Interface()
+				}
+				if expr.typeHandler == "" {
+					// 使用字段标签声明的类型处理器，如 db:"settings,json"
+					expr.typeHandler = param.typeHandler
+				}
 			}
-		}
 
-		for _, match := range matches {
-			paramName := strings.TrimSpace(match[1])
-			if value, exists := fieldMap[paramName]; exists {
-				args = append(args, value)
-			} else {
-				args = append(args, nil)
+			value, err := b.convertParameter(value, expr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert parameter %s: %w", expr.name, err)
 			}
-			processedSQL = strings.Replace(processedSQL, match[0], "?", 1)
+			args[i] = value
 		}
 
-		return args, processedSQL, nil
+		return args, nil
 	}
 
-	return nil, "", fmt.Errorf("unsupported parameter type: %T", parameter)
+	return nil, fmt.Errorf("unsupported parameter type: %T", parameter)
+}
+
+// isDriverValue 判断是否为驱动可直接处理的值（driver.Valuer、time.Time、[]byte）
+func isDriverValue(value interface{}) bool {
+	switch value.(type) {
+	case driver.Valuer, time.Time, []byte:
+		return true
+	default:
+		return false
+	}
 }
 
 // isBasicType 判断是否为基础类型
@@ -153,6 +476,11 @@ func ConvertValue(value interface{}, targetType reflect.Type) (interface{}, erro
 		return nil, nil
 	}
 
+	// 优先使用注册的类型处理器
+	if handler, exists := types.Default.GetHandler(targetType, ""); exists {
+		return handler.GetResult(value, targetType)
+	}
+
 	sourceValue := reflect.ValueOf(value)
 	if sourceValue.Type().ConvertibleTo(targetType) {
 		return sourceValue.Convert(targetType).Interface(), nil
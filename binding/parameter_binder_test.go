@@ -1,9 +1,13 @@
 package binding
 
 import (
+	"database/sql"
 	"reflect"
 	"testing"
 	"time"
+
+	"gobatis/reflection"
+	"gobatis/types"
 )
 
 // TestUser 测试用户结构体
@@ -258,6 +262,174 @@ func TestBindParameters_UnsupportedType(t *testing.T) {
 	}
 }
 
+// TestBindParameters_TypeHandlers 测试类型处理器参数转换
+func TestBindParameters_TypeHandlers(t *testing.T) {
+	binder := NewParameterBinder()
+	sql := "UPDATE users SET tags = #{tags, typeHandler=strings}, settings = #{settings, typeHandler=json} WHERE id = #{id}"
+	params := map[string]interface{}{
+		"tags":     []string{"go", "sql"},
+		"settings": map[string]string{"theme": "dark"},
+		"id":       int64(1),
+	}
+
+	processedSQL, args, err := binder.BindParameters(sql, params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedSQL := "UPDATE users SET tags = ?, settings = ? WHERE id = ?"
+	if processedSQL != expectedSQL {
+		t.Fatalf("Expected SQL: %s, got: %s", expectedSQL, processedSQL)
+	}
+
+	if args[0] != "go,sql" {
+		t.Fatalf("Expected tags to be joined, got: %v", args[0])
+	}
+
+	if args[1] != `{"theme":"dark"}` {
+		t.Fatalf("Expected settings as json, got: %v", args[1])
+	}
+
+	if args[2] != int64(1) {
+		t.Fatalf("Expected id to be unchanged, got: %v", args[2])
+	}
+}
+
+// TestBindParameters_UnknownTypeHandler 测试未注册的具名类型处理器
+func TestBindParameters_UnknownTypeHandler(t *testing.T) {
+	binder := NewParameterBinder()
+	sql := "SELECT * FROM users WHERE id = #{id,typeHandler=missing}"
+
+	_, _, err := binder.BindParameters(sql, map[string]interface{}{"id": 1})
+	if err == nil {
+		t.Fatal("Expected error for unknown type handler")
+	}
+}
+
+// TestNewParameterBinderWithTypeHandlers 测试自定义注册表
+func TestNewParameterBinderWithTypeHandlers(t *testing.T) {
+	registry := types.NewTypeHandlerRegistry()
+	registry.Register(reflect.TypeOf([]string{}), &types.StringSliceTypeHandler{Delimiter: ";"})
+
+	binder := NewParameterBinderWithTypeHandlers(registry)
+	_, args, err := binder.BindParameters("SELECT #{tags}", map[string]interface{}{"tags": []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if args[0] != "a;b" {
+		t.Fatalf("Expected custom delimiter, got: %v", args[0])
+	}
+}
+
+// TestBindParameters_DriverValuer 测试 driver.Valuer 参数原样传递
+func TestBindParameters_DriverValuer(t *testing.T) {
+	binder := NewParameterBinder()
+	nickname := sql.NullString{String: "johnny", Valid: true}
+
+	// 整个参数是 Valuer 时不应按结构体字段展开
+	processedSQL, args, err := binder.BindParameters("SELECT * FROM users WHERE nickname = #{nickname}", nickname)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if processedSQL != "SELECT * FROM users WHERE nickname = ?" {
+		t.Fatalf("Unexpected SQL: %s", processedSQL)
+	}
+	if args[0] != nickname {
+		t.Fatalf("Expected Valuer to pass through, got: %v", args[0])
+	}
+
+	// 字段是 Valuer 时原样传递
+	type profile struct {
+		Nickname sql.NullString `db:"nickname"`
+	}
+	_, args, err = binder.BindParameters("UPDATE users SET nickname = #{nickname}", profile{Nickname: nickname})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if args[0] != nickname {
+		t.Fatalf("Expected Valuer field to pass through, got: %v", args[0])
+	}
+
+	// time.Time 作为单个参数
+	now := time.Now()
+	_, args, err = binder.BindParameters("SELECT * FROM users WHERE create_at > #{since}", now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(args) != 1 || args[0] != now {
+		t.Fatalf("Expected time parameter to pass through, got: %v", args)
+	}
+}
+
+// TestBindParameters_JSONTag 测试 json 标签字段序列化
+func TestBindParameters_JSONTag(t *testing.T) {
+	type account struct {
+		ID       int64             `db:"id"`
+		Settings map[string]string `db:"settings,json"`
+		Scores   []int             `db:"scores,json"`
+	}
+
+	binder := NewParameterBinder()
+	param := account{ID: 7, Settings: map[string]string{"theme": "dark"}, Scores: []int{1, 2}}
+
+	processedSQL, args, err := binder.BindParameters("UPDATE accounts SET settings = #{settings}, scores = #{scores} WHERE id = #{id}", param)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if processedSQL != "UPDATE accounts SET settings = ?, scores = ? WHERE id = ?" {
+		t.Fatalf("Unexpected SQL: %s", processedSQL)
+	}
+
+	if args[0] != `{"theme":"dark"}` || args[1] != "[1,2]" || args[2] != int64(7) {
+		t.Fatalf("Unexpected args: %v", args)
+	}
+}
+
+// TestBindParameters_EmbeddedStruct 测试嵌入结构体字段绑定
+func TestBindParameters_EmbeddedStruct(t *testing.T) {
+	type Base struct {
+		ID int64 `db:"id"`
+	}
+	type address struct {
+		City string `db:"city"`
+	}
+	type order struct {
+		*Base
+		Name     string  `db:"name"`
+		Billing  address `db:",prefix=billing_"`
+		Shipping address `db:",prefix=shipping_"`
+	}
+
+	binder := NewParameterBinder()
+	sql := "INSERT INTO orders (id, name, billing_city, shipping_city) VALUES (#{id}, #{name}, #{billing_city}, #{shipping_city})"
+
+	_, args, err := binder.BindParameters(sql, &order{
+		Base:     &Base{ID: 9},
+		Name:     "order-9",
+		Billing:  address{City: "Berlin"},
+		Shipping: address{City: "Paris"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []interface{}{int64(9), "order-9", "Berlin", "Paris"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Fatalf("Expected args %v, got: %v", expected, args)
+	}
+
+	// 嵌入指针为 nil 时字段绑定为 nil
+	_, args, err = binder.BindParameters(sql, order{Name: "order-10"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if args[0] != nil {
+		t.Fatalf("Expected nil id for nil embedded pointer, got: %v", args[0])
+	}
+}
+
 // TestIsBasicType 测试基础类型判断
 func TestIsBasicType(t *testing.T) {
 	testCases := []struct {
@@ -353,3 +525,131 @@ func TestIsNumericType(t *testing.T) {
 		}
 	}
 }
+
+// TestBindParameters_NamingStrategy 测试按命名策略和忽略大小写匹配参数
+func TestBindParameters_NamingStrategy(t *testing.T) {
+	type account struct {
+		UserID   int64
+		HTTPCode int
+	}
+
+	binder := NewParameterBinder()
+	sql := "SELECT * FROM accounts WHERE user_id = #{user_id} AND http_code = #{HTTP_CODE} AND id = #{userid}"
+
+	_, args, err := binder.BindParameters(sql, account{UserID: 7, HTTPCode: 200})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []interface{}{int64(7), 200, int64(7)}
+	if !reflect.DeepEqual(args, expected) {
+		t.Fatalf("Expected args %v, got: %v", expected, args)
+	}
+
+	binder = NewParameterBinderWithOptions(Options{NamingStrategy: reflection.LowerCamelStrategy{}})
+	_, args, err = binder.BindParameters("SELECT * FROM accounts WHERE userID = #{userID}", account{UserID: 8})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if args[0] != int64(8) {
+		t.Fatalf("Expected 8, got: %v", args[0])
+	}
+}
+
+// TestGetPlaceholderPlan 测试占位符计划的解析与缓存
+func TestGetPlaceholderPlan(t *testing.T) {
+	sql := "UPDATE users SET settings = #{settings,typeHandler=json} WHERE id = #{id}"
+
+	plan := getPlaceholderPlan(sql)
+	if plan.sql != "UPDATE users SET settings = ? WHERE id = ?" {
+		t.Fatalf("Unexpected SQL: %s", plan.sql)
+	}
+	if len(plan.expressions) != 2 || plan.expressions[0].typeHandler != "json" || plan.expressions[1].name != "id" {
+		t.Fatalf("Unexpected expressions: %+v", plan.expressions)
+	}
+
+	if getPlaceholderPlan(sql) != plan {
+		t.Fatal("Expected plan to be cached")
+	}
+}
+
+// BenchmarkBindParameters_Struct 绑定结构体参数
+func BenchmarkBindParameters_Struct(b *testing.B) {
+	binder := NewParameterBinder()
+	sql := "INSERT INTO users (id, username, email, create_at) VALUES (#{id}, #{username}, #{email}, #{create_at})"
+	user := &TestUser{ID: 1, Username: "john", Email: "john@example.com", CreateAt: time.Now()}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := binder.BindParameters(sql, user); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkBindParameters_Map 绑定 Map 参数
+func BenchmarkBindParameters_Map(b *testing.B) {
+	binder := NewParameterBinder()
+	sql := "SELECT * FROM users WHERE name = #{name} AND age > #{age}"
+	params := map[string]interface{}{"name": "John", "age": 30}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := binder.BindParameters(sql, params); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestBindParameters_AdditionalParameters 测试附加参数与原参数一起绑定
+func TestBindParameters_AdditionalParameters(t *testing.T) {
+	binder := NewParameterBinder()
+	sql := "SELECT * FROM users WHERE username = #{username} AND id > #{__after}"
+
+	testCases := []interface{}{
+		TestUser{Username: "john"},
+		map[string]interface{}{"username": "john", "__after": 1},
+	}
+	for _, parameter := range testCases {
+		_, args, err := binder.BindParameters(sql, &AdditionalParameters{
+			Parameter:  parameter,
+			Additional: map[string]interface{}{"__after": 10},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(args) != 2 || args[0] != "john" || args[1] != 10 {
+			t.Errorf("Unexpected args for %T: %v", parameter, args)
+		}
+	}
+
+	_, args, err := binder.BindParameters(sql, &AdditionalParameters{Additional: map[string]interface{}{"__after": 10}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if args[0] != nil || args[1] != 10 {
+		t.Errorf("Expected nil original parameter to bind nil, got %v", args)
+	}
+}
+
+// TestPlanCache_Bounded 测试按 SQL 文本缓存的计划数量有上限，淘汰最久未使用的计划
+func TestPlanCache_Bounded(t *testing.T) {
+	cache := newPlanLRU(2)
+	for _, sql := range []string{"SELECT 1", "SELECT 2"} {
+		cache.add(sql, NewPlan(sql))
+	}
+	cache.get("SELECT 1")
+	cache.add("SELECT 3", NewPlan("SELECT 3"))
+
+	if cache.len() != 2 {
+		t.Errorf("Expected 2 cached plans, got %d", cache.len())
+	}
+	if _, ok := cache.get("SELECT 2"); ok {
+		t.Error("Expected least recently used plan to be evicted")
+	}
+	if _, ok := cache.get("SELECT 1"); !ok {
+		t.Error("Expected recently used plan to be kept")
+	}
+}
@@ -0,0 +1,246 @@
+package cache
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache 二级缓存接口，实现需要并发安全
+type Cache interface {
+	// ID 缓存标识，通常为命名空间
+	ID() string
+	Get(key string) (interface{}, bool)
+	Put(key string, value interface{})
+	Remove(key string)
+	Clear()
+	Size() int
+}
+
+// Factory 自定义缓存后端的工厂，properties 来自 <cache> 下的 <property>
+type Factory func(id string, properties map[string]string) (Cache, error)
+
+// 淘汰策略
+const (
+	EvictionLRU  = "LRU"
+	EvictionFIFO = "FIFO"
+)
+
+// DefaultSize 默认的最大缓存项数
+const DefaultSize = 1024
+
+// Options 内存缓存配置，对应 <cache> 的属性
+type Options struct {
+	Eviction      string        // LRU（默认）或 FIFO
+	FlushInterval time.Duration // 缓存项的存活时间，0 表示不过期
+	Size          int           // 最大缓存项数，不大于 0 时使用 DefaultSize
+	ReadOnly      bool          // 只读缓存直接返回缓存的对象，否则读写时深拷贝
+}
+
+// New 按配置创建内存缓存
+func New(id string, options Options) (Cache, error) {
+	var c Cache
+	switch strings.ToUpper(options.Eviction) {
+	case "", EvictionLRU:
+		c = NewLRUCache(id, options.Size)
+	case EvictionFIFO:
+		c = NewFIFOCache(id, options.Size)
+	default:
+		return nil, fmt.Errorf("unknown cache eviction %s", options.Eviction)
+	}
+
+	if options.FlushInterval > 0 {
+		c = NewTTLCache(c, options.FlushInterval)
+	}
+	if !options.ReadOnly {
+		c = &copyingCache{delegate: c}
+	}
+	return c, nil
+}
+
+// entry 链表中的缓存项
+type entry struct {
+	key   string
+	value interface{}
+}
+
+// memoryCache 容量有限的内存缓存，超出容量时淘汰链表尾部的缓存项
+type memoryCache struct {
+	mu    sync.Mutex
+	id    string
+	size  int
+	lru   bool // 为 true 时读取会将缓存项移到头部，否则按写入顺序淘汰
+	order *list.List
+	items map[string]*list.Element
+}
+
+func newMemoryCache(id string, size int, lru bool) *memoryCache {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return &memoryCache{
+		id:    id,
+		size:  size,
+		lru:   lru,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// NewLRUCache 创建淘汰最久未使用项的内存缓存
+func NewLRUCache(id string, size int) Cache {
+	return newMemoryCache(id, size, true)
+}
+
+// NewFIFOCache 创建淘汰最早写入项的内存缓存
+func NewFIFOCache(id string, size int) Cache {
+	return newMemoryCache(id, size, false)
+}
+
+// ID 实现 Cache
+func (c *memoryCache) ID() string { return c.id }
+
+// Get 实现 Cache
+func (c *memoryCache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, exists := c.items[key]
+	if !exists {
+		return nil, false
+	}
+	if c.lru {
+		c.order.MoveToFront(elem)
+	}
+	return elem.Value.(*entry).value, true
+}
+
+// Put 实现 Cache
+func (c *memoryCache) Put(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, exists := c.items[key]; exists {
+		elem.Value.(*entry).value = value
+		if c.lru {
+			c.order.MoveToFront(elem)
+		}
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry{key: key, value: value})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*entry).key)
+	}
+}
+
+// Remove 实现 Cache
+func (c *memoryCache) Remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, exists := c.items[key]; exists {
+		c.order.Remove(elem)
+		delete(c.items, key)
+	}
+}
+
+// Clear 实现 Cache
+func (c *memoryCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+// Size 实现 Cache
+func (c *memoryCache) Size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// ttlEntry 带过期时间的缓存值
+type ttlEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// TTLCache 缓存项写入 ttl 时间后过期的装饰器
+type TTLCache struct {
+	delegate Cache
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// NewTTLCache 为缓存增加过期时间
+func NewTTLCache(delegate Cache, ttl time.Duration) *TTLCache {
+	return &TTLCache{delegate: delegate, ttl: ttl, now: time.Now}
+}
+
+// ID 实现 Cache
+func (c *TTLCache) ID() string { return c.delegate.ID() }
+
+// Get 实现 Cache，过期的缓存项视为未命中并移除
+func (c *TTLCache) Get(key string) (interface{}, bool) {
+	value, exists := c.delegate.Get(key)
+	if !exists {
+		return nil, false
+	}
+	e := value.(ttlEntry)
+	if !c.now().Before(e.expires) {
+		c.delegate.Remove(key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Put 实现 Cache
+func (c *TTLCache) Put(key string, value interface{}) {
+	c.delegate.Put(key, ttlEntry{value: value, expires: c.now().Add(c.ttl)})
+}
+
+// Remove 实现 Cache
+func (c *TTLCache) Remove(key string) { c.delegate.Remove(key) }
+
+// Clear 实现 Cache
+func (c *TTLCache) Clear() { c.delegate.Clear() }
+
+// Size 实现 Cache，包含尚未清理的过期项
+func (c *TTLCache) Size() int { return c.delegate.Size() }
+
+// copyingCache 读写时深拷贝的装饰器，调用方修改结果不会影响缓存
+type copyingCache struct {
+	delegate Cache
+}
+
+// ID 实现 Cache
+func (c *copyingCache) ID() string { return c.delegate.ID() }
+
+// Get 实现 Cache
+func (c *copyingCache) Get(key string) (interface{}, bool) {
+	value, exists := c.delegate.Get(key)
+	if !exists {
+		return nil, false
+	}
+	return DeepCopy(value), true
+}
+
+// Put 实现 Cache
+func (c *copyingCache) Put(key string, value interface{}) {
+	c.delegate.Put(key, DeepCopy(value))
+}
+
+// Remove 实现 Cache
+func (c *copyingCache) Remove(key string) { c.delegate.Remove(key) }
+
+// Clear 实现 Cache
+func (c *copyingCache) Clear() { c.delegate.Clear() }
+
+// Size 实现 Cache
+func (c *copyingCache) Size() int { return c.delegate.Size() }
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUCache_Eviction(t *testing.T) {
+	c := NewLRUCache("test", 2)
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Get("a")
+	c.Put("c", 3)
+
+	if _, exists := c.Get("b"); exists {
+		t.Error("Expected least recently used entry b to be evicted")
+	}
+	if _, exists := c.Get("a"); !exists {
+		t.Error("Expected recently used entry a to remain")
+	}
+	if c.Size() != 2 {
+		t.Errorf("Expected size 2, got %d", c.Size())
+	}
+}
+
+func TestFIFOCache_Eviction(t *testing.T) {
+	c := NewFIFOCache("test", 2)
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Get("a")
+	c.Put("c", 3)
+
+	if _, exists := c.Get("a"); exists {
+		t.Error("Expected first entry a to be evicted")
+	}
+	if _, exists := c.Get("b"); !exists {
+		t.Error("Expected entry b to remain")
+	}
+
+	c.Remove("b")
+	c.Clear()
+	if c.Size() != 0 {
+		t.Errorf("Expected empty cache, got %d", c.Size())
+	}
+}
+
+func TestTTLCache_Expiration(t *testing.T) {
+	now := time.Now()
+	c := NewTTLCache(NewLRUCache("test", 10), time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Put("a", 1)
+	if value, exists := c.Get("a"); !exists || value != 1 {
+		t.Fatalf("Expected cached value, got %v %v", value, exists)
+	}
+
+	now = now.Add(time.Minute)
+	if _, exists := c.Get("a"); exists {
+		t.Error("Expected entry to expire")
+	}
+	if c.Size() != 0 {
+		t.Errorf("Expected expired entry to be removed, got %d", c.Size())
+	}
+}
+
+func TestNew_ReadWriteCopies(t *testing.T) {
+	type user struct {
+		Name string
+		Tags []string
+	}
+
+	c, err := New("test", Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	original := []interface{}{&user{Name: "john", Tags: []string{"a"}}}
+	c.Put("k", original)
+	original[0].(*user).Tags[0] = "changed"
+
+	value, _ := c.Get("k")
+	cached := value.([]interface{})[0].(*user)
+	if cached.Tags[0] != "a" {
+		t.Error("Expected cache to hold a copy of the value")
+	}
+	cached.Name = "jane"
+	value, _ = c.Get("k")
+	if value.([]interface{})[0].(*user).Name != "john" {
+		t.Error("Expected callers to receive a copy of the cached value")
+	}
+
+	readOnly, _ := New("test", Options{ReadOnly: true})
+	readOnly.Put("k", original)
+	value, _ = readOnly.Get("k")
+	if value.([]interface{})[0] != original[0] {
+		t.Error("Expected read-only cache to share values")
+	}
+
+	if _, err := New("test", Options{Eviction: "RANDOM"}); err == nil {
+		t.Error("Expected error for unknown eviction")
+	}
+}
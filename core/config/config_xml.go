@@ -0,0 +1,106 @@
+package config
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// XMLConfiguration 框架配置文件
+type XMLConfiguration struct {
+	XMLName xml.Name          `xml:"configuration"`
+	Plugins []XMLPlugin       `xml:"plugins>plugin"`
+	Mappers []XMLMapperSource `xml:"mappers>mapper"`
+}
+
+// XMLPlugin 配置文件中的插件，interceptor 为 RegisterPlugin 注册的名称，也是语句 plugins 属性中的名称；
+// 其余属性以逗号分隔，限定插件适用的语句，见 StatementScope
+type XMLPlugin struct {
+	Interceptor       string        `xml:"interceptor,attr"`
+	Statements        string        `xml:"statements,attr"`
+	ExcludeStatements string        `xml:"excludeStatements,attr"`
+	Namespaces        string        `xml:"namespaces,attr"`
+	ExcludeNamespaces string        `xml:"excludeNamespaces,attr"`
+	StatementTypes    string        `xml:"statementTypes,attr"`
+	Properties        []XMLProperty `xml:"property"`
+}
+
+// scope 解析插件的适用范围
+func (xp XMLPlugin) scope() (StatementScope, error) {
+	scope := StatementScope{
+		Statements:        splitList(xp.Statements),
+		ExcludeStatements: splitList(xp.ExcludeStatements),
+		Namespaces:        splitList(xp.Namespaces),
+		ExcludeNamespaces: splitList(xp.ExcludeNamespaces),
+	}
+	for _, name := range splitList(xp.StatementTypes) {
+		statementType, err := ParseStatementType(name)
+		if err != nil {
+			return scope, err
+		}
+		scope.StatementTypes = append(scope.StatementTypes, statementType)
+	}
+	return scope, scope.Validate()
+}
+
+// splitList 拆分逗号分隔的列表，忽略空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// XMLMapperSource 配置文件中引用的 Mapper XML，相对路径相对于配置文件所在目录
+type XMLMapperSource struct {
+	Resource string `xml:"resource,attr"`
+}
+
+// LoadConfigXML 加载框架配置文件，按声明顺序添加插件并加载 Mapper XML
+func (c *Configuration) LoadConfigXML(xmlPath string) error {
+	data, err := ioutil.ReadFile(xmlPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config xml: %w", err)
+	}
+
+	var configuration XMLConfiguration
+	if err := xml.Unmarshal(data, &configuration); err != nil {
+		return fmt.Errorf("failed to parse config xml: %w", err)
+	}
+
+	for _, xp := range configuration.Plugins {
+		properties := make(map[string]string, len(xp.Properties))
+		for _, property := range xp.Properties {
+			properties[property.Name] = property.Value
+		}
+		plugin, err := NewPlugin(xp.Interceptor, properties)
+		if err != nil {
+			return fmt.Errorf("config xml %s: %w", xmlPath, err)
+		}
+		scope, err := xp.scope()
+		if err != nil {
+			return fmt.Errorf("config xml %s: plugin %s: %w", xmlPath, xp.Interceptor, err)
+		}
+		// 以注册名称命名插件，使语句的 plugins 属性与配置文件一致
+		if !scope.IsEmpty() || PluginName(plugin) != xp.Interceptor {
+			plugin = &scopedPlugin{Plugin: plugin, name: xp.Interceptor, scope: scope}
+		}
+		c.AddPlugin(plugin)
+	}
+
+	for _, mapper := range configuration.Mappers {
+		resource := mapper.Resource
+		if !filepath.IsAbs(resource) {
+			resource = filepath.Join(filepath.Dir(xmlPath), resource)
+		}
+		if err := c.AddMapperXML(resource); err != nil {
+			return fmt.Errorf("config xml %s: mapper %s: %w", xmlPath, mapper.Resource, err)
+		}
+	}
+	return nil
+}
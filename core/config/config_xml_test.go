@@ -0,0 +1,108 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadConfigXML 测试从配置文件添加插件与 Mapper
+func TestLoadConfigXML(t *testing.T) {
+	RegisterPlugin("mock", func() Plugin { return &MockPlugin{} })
+
+	dir := t.TempDir()
+	mapperXML := `<mapper namespace="UserMapper">
+    <select id="GetUser">SELECT id FROM users WHERE id = #{id}</select>
+</mapper>`
+	if err := os.WriteFile(filepath.Join(dir, "user.xml"), []byte(mapperXML), 0o644); err != nil {
+		t.Fatalf("Failed to write mapper xml: %v", err)
+	}
+	configXML := `<?xml version="1.0" encoding="UTF-8"?>
+<configuration>
+    <plugins>
+        <plugin interceptor="mock">
+            <property name="dialect" value="mysql"/>
+        </plugin>
+    </plugins>
+    <mappers>
+        <mapper resource="user.xml"/>
+    </mappers>
+</configuration>`
+	configPath := filepath.Join(dir, "gobatis.xml")
+	if err := os.WriteFile(configPath, []byte(configXML), 0o644); err != nil {
+		t.Fatalf("Failed to write config xml: %v", err)
+	}
+
+	config := NewConfiguration()
+	if err := config.LoadConfigXML(configPath); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(config.Plugins) != 1 {
+		t.Fatalf("Expected 1 plugin, got %d", len(config.Plugins))
+	}
+	if plugin := config.Plugins[0].(*MockPlugin); plugin.properties["dialect"] != "mysql" {
+		t.Errorf("Expected plugin properties to be set, got %v", plugin.properties)
+	}
+	if _, exists := config.GetMapperStatement("UserMapper.GetUser"); !exists {
+		t.Error("Expected mapper to be loaded relative to the config file")
+	}
+
+	unknown := filepath.Join(dir, "unknown.xml")
+	os.WriteFile(unknown, []byte(`<configuration><plugins><plugin interceptor="missing"/></plugins></configuration>`), 0o644)
+	if err := NewConfiguration().LoadConfigXML(unknown); err == nil {
+		t.Error("Expected error for unregistered plugin")
+	}
+}
+
+// TestLoadConfigXML_PluginScope 测试配置文件限定插件适用的语句
+func TestLoadConfigXML_PluginScope(t *testing.T) {
+	RegisterPlugin("tenant", func() Plugin { return &MockPlugin{} })
+
+	dir := t.TempDir()
+	mapperXML := `<mapper namespace="UserMapper">
+    <select id="GetUser">SELECT id FROM users WHERE id = #{id}</select>
+    <select id="GetAll" plugins="!tenant">SELECT id FROM users</select>
+</mapper>`
+	os.WriteFile(filepath.Join(dir, "user.xml"), []byte(mapperXML), 0o644)
+	configXML := `<configuration>
+    <plugins>
+        <plugin interceptor="tenant" excludeNamespaces="admin*" statementTypes="select, update"/>
+    </plugins>
+    <mappers>
+        <mapper resource="user.xml"/>
+    </mappers>
+</configuration>`
+	configPath := filepath.Join(dir, "gobatis.xml")
+	os.WriteFile(configPath, []byte(configXML), 0o644)
+
+	config := NewConfiguration()
+	if err := config.LoadConfigXML(configPath); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	plugin := config.Plugins[0]
+	if name := PluginName(plugin); name != "tenant" {
+		t.Errorf("Expected plugin to be named after its interceptor, got %s", name)
+	}
+
+	getUser, _ := config.GetMapperStatement("UserMapper.GetUser")
+	getAll, _ := config.GetMapperStatement("UserMapper.GetAll")
+	if getAll.Plugins != "!tenant" {
+		t.Errorf("Expected plugins attribute to be parsed, got %q", getAll.Plugins)
+	}
+	if !PluginAppliesTo(plugin, getUser) {
+		t.Error("Expected plugin to apply to UserMapper.GetUser")
+	}
+	if PluginAppliesTo(plugin, getAll) {
+		t.Error("Expected statement to opt out of the plugin")
+	}
+	admin := &MapperStatement{ID: "adminUser.GetAll", Namespace: "adminUser", StatementType: SELECT}
+	if PluginAppliesTo(plugin, admin) {
+		t.Error("Expected admin namespaces to be excluded")
+	}
+
+	invalid := filepath.Join(dir, "invalid.xml")
+	os.WriteFile(invalid, []byte(`<configuration><plugins><plugin interceptor="tenant" statementTypes="merge"/></plugins></configuration>`), 0o644)
+	if err := NewConfiguration().LoadConfigXML(invalid); err == nil {
+		t.Error("Expected error for unknown statement type")
+	}
+}
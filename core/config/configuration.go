@@ -4,18 +4,55 @@ import (
 	"database/sql"
 	"encoding/xml"
 	"fmt"
+	"gobatis/binding"
+	"gobatis/cache"
+	"gobatis/dialect"
+	"gobatis/idgen"
 	"gobatis/logger"
+	"gobatis/mapping"
+	"gobatis/reflection"
+	"gobatis/types"
 	"io/ioutil"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Configuration 框架配置
 type Configuration struct {
-	DataSource   *DataSource
-	MapperConfig *MapperConfig
-	Plugins      []Plugin
-	Logger       logger.Interface
+	DataSource          *DataSource
+	MapperConfig        *MapperConfig
+	Plugins             []Plugin
+	Logger              logger.Interface
+	TypeHandlerRegistry *types.TypeHandlerRegistry
+	NamingStrategy      reflection.NamingStrategy
+	Dialect             dialect.Dialect
+	IdGenerators        map[string]idgen.IdGenerator
+	// AutoMappingUnknownColumnBehavior 结果集中存在无对应字段的列时的行为
+	AutoMappingUnknownColumnBehavior mapping.AutoMappingUnknownColumnBehavior
+	// RequireAllFieldsMapped 要求结果结构体中除 db:",optional" 外的字段都有对应的列
+	RequireAllFieldsMapped bool
+	// DefaultExecutorType 会话默认使用的执行器类型
+	DefaultExecutorType ExecutorType
+	// StatementCacheSize ReuseExecutor 每个会话缓存的预编译语句数量上限
+	StatementCacheSize int
+	// BatchFlushStatements 批量执行器累计多少条语句后自动刷新，0 表示不限制
+	BatchFlushStatements int
+	// BatchFlushBytes 批量执行器累计的 SQL 与参数达到多少字节后自动刷新，0 表示不限制
+	BatchFlushBytes int
+	// BatchRewriteInserts 方言支持时将同一 INSERT 的连续批量语句改写为多行 VALUES
+	BatchRewriteInserts bool
+	// LocalCacheScope 会话一级缓存的作用范围
+	LocalCacheScope LocalCacheScope
+	// CacheEnabled 启用命名空间二级缓存，默认为 true
+	CacheEnabled bool
+	// Caches 各命名空间的二级缓存，由 <cache> 声明
+	Caches map[string]cache.Cache
+	// CacheRefs 由 <cache-ref> 声明的命名空间到被引用命名空间的映射
+	CacheRefs map[string]string
+	// CacheFactories 自定义缓存后端，由 <cache type="..."> 引用
+	CacheFactories map[string]cache.Factory
 }
 
 // DataSource 数据源配置
@@ -27,17 +64,62 @@ type DataSource struct {
 
 // MapperConfig Mapper 配置
 type MapperConfig struct {
-	Mappers map[string]*MapperStatement
+	Mappers    map[string]*MapperStatement
+	ResultMaps map[string]*mapping.ResultMap
 }
 
 // MapperStatement SQL 语句配置
 type MapperStatement struct {
 	ID            string
+	Namespace     string
 	SQL           string
 	ResultType    reflect.Type
+	ResultMap     *mapping.ResultMap
 	StatementType StatementType
+	// 以下用于 INSERT 回填生成的主键
+	UseGeneratedKeys bool
+	KeyProperty      string // 主键属性，多个以逗号分隔
+	KeyColumn        string // 主键列，多个以逗号分隔
+	KeyGenerator     string // 客户端主键生成器名称，在绑定参数前填充为零值的 keyProperty
+	SelectKey        *SelectKey
+	// FlushCache 执行前清空缓存，XML 中 select 默认为 false，其余语句默认为 true
+	FlushCache bool
+	// UseCache 缓存 select 的结果，XML 中 select 默认为 true
+	UseCache bool
+	// Cache 命名空间的二级缓存，命名空间未声明 <cache> 或 <cache-ref> 时为 nil
+	Cache cache.Cache
+	// CountStatement 分页时使用的计数语句 ID，为空时由 SQL 推导
+	CountStatement string
+	// Plugins 语句对插件的启用与禁用，如 "!pagination"，见 PluginSelection
+	Plugins string
+	// plan 加载时解析的占位符计划
+	plan *binding.Plan
 }
 
+// PlaceholderPlan 语句的占位符计划，加载时解析并随语句缓存；
+// 插件改写过 SQL 的副本及未经配置加载的语句按需解析，不做缓存
+func (s *MapperStatement) PlaceholderPlan() *binding.Plan {
+	if s.plan != nil && s.plan.Source() == s.SQL {
+		return s.plan
+	}
+	return binding.NewPlan(s.SQL)
+}
+
+// SelectKey 主键查询配置，对应 insert 中的 <selectKey>
+type SelectKey struct {
+	SQL         string
+	KeyProperty string // 主键属性，多个以逗号分隔
+	KeyColumn   string // 查询结果中的列，多个以逗号分隔，为空时按位置对应
+	ResultType  string
+	Order       string // SelectKeyBefore 或 SelectKeyAfter
+}
+
+// selectKey 执行时机
+const (
+	SelectKeyBefore = "BEFORE"
+	SelectKeyAfter  = "AFTER"
+)
+
 // StatementType SQL 语句类型
 type StatementType int
 
@@ -48,27 +130,58 @@ const (
 	DELETE
 )
 
-// Plugin 插件接口
-type Plugin interface {
-	Intercept(invocation *Invocation) (interface{}, error)
-	SetProperties(properties map[string]string)
-}
+// ExecutorType 执行器类型
+type ExecutorType int
 
-// Invocation 拦截调用信息
-type Invocation struct {
-	Target interface{}
-	Method reflect.Method
-	Args   []interface{}
-}
+const (
+	// ExecutorSimple 每次执行都直接发送 SQL
+	ExecutorSimple ExecutorType = iota
+	// ExecutorReuse 按 SQL 缓存并复用预编译语句
+	ExecutorReuse
+	// ExecutorBatch 累计更新语句，刷新时批量执行
+	ExecutorBatch
+)
+
+// LocalCacheScope 一级缓存作用范围
+type LocalCacheScope int
+
+const (
+	// LocalCacheSession 查询结果在会话内缓存，直到执行更新、提交、回滚或 ClearCache
+	LocalCacheSession LocalCacheScope = iota
+	// LocalCacheStatement 查询结果不跨语句缓存，相当于关闭一级缓存
+	LocalCacheStatement
+)
+
+// 执行器默认配置
+const (
+	// DefaultStatementCacheSize 默认的预编译语句缓存容量
+	DefaultStatementCacheSize = 256
+	// DefaultBatchFlushStatements 批量执行器默认的自动刷新语句数
+	DefaultBatchFlushStatements = 1000
+	// DefaultBatchFlushBytes 批量执行器默认的自动刷新字节数
+	DefaultBatchFlushBytes = 4 << 20
+)
 
 // NewConfiguration 创建新的配置
 func NewConfiguration() *Configuration {
 	return &Configuration{
 		MapperConfig: &MapperConfig{
-			Mappers: make(map[string]*MapperStatement),
+			Mappers:    make(map[string]*MapperStatement),
+			ResultMaps: make(map[string]*mapping.ResultMap),
 		},
-		Plugins: make([]Plugin, 0),
-		Logger:  logger.Default,
+		Plugins:              make([]Plugin, 0),
+		Logger:               logger.Default,
+		TypeHandlerRegistry:  types.NewTypeHandlerRegistry(),
+		NamingStrategy:       reflection.DefaultNamingStrategy,
+		Dialect:              dialect.GenericDialect{},
+		IdGenerators:         idgen.Defaults(),
+		StatementCacheSize:   DefaultStatementCacheSize,
+		BatchFlushStatements: DefaultBatchFlushStatements,
+		BatchFlushBytes:      DefaultBatchFlushBytes,
+		CacheEnabled:         true,
+		Caches:               make(map[string]cache.Cache),
+		CacheRefs:            make(map[string]string),
+		CacheFactories:       make(map[string]cache.Factory),
 	}
 }
 
@@ -88,6 +201,7 @@ func (c *Configuration) SetDataSource(driverName, dataSourceName string) error {
 		DataSourceName: dataSourceName,
 		DB:             db,
 	}
+	c.Dialect = dialect.ForDriver(driverName)
 
 	return nil
 }
@@ -104,54 +218,299 @@ func (c *Configuration) AddMapperXML(xmlPath string) error {
 		return fmt.Errorf("failed to parse mapper xml: %w", err)
 	}
 
+	// 解析 resultMap
+	if c.MapperConfig.ResultMaps == nil {
+		c.MapperConfig.ResultMaps = make(map[string]*mapping.ResultMap)
+	}
+	for _, rm := range mapper.ResultMaps {
+		resultMapId := mapper.Namespace + "." + rm.ID
+		resultMap := &mapping.ResultMap{
+			ID:   resultMapId,
+			Type: rm.Type,
+		}
+		for _, result := range append(rm.IDs, rm.Results...) {
+			resultMap.Mappings = append(resultMap.Mappings, mapping.ResultMapping{
+				Property:    result.Property,
+				Column:      result.Column,
+				JdbcType:    result.JdbcType,
+				TypeHandler: result.TypeHandler,
+			})
+		}
+		c.MapperConfig.ResultMaps[resultMapId] = resultMap
+	}
+
 	// 解析 select 语句
 	for _, sel := range mapper.Selects {
 		statementId := mapper.Namespace + "." + sel.ID
-		c.MapperConfig.Mappers[statementId] = &MapperStatement{
+		stmt := &MapperStatement{
 			ID:            statementId,
+			Namespace:     mapper.Namespace,
 			SQL:           strings.TrimSpace(sel.SQL),
 			StatementType: SELECT,
+			FlushCache:    boolAttr(sel.FlushCache, false),
+			UseCache:      boolAttr(sel.UseCache, true),
+			Plugins:       sel.Plugins,
 		}
+		if sel.CountStatement != "" {
+			stmt.CountStatement = qualifyId(mapper.Namespace, sel.CountStatement)
+		}
+		if sel.ResultMap != "" {
+			resultMap, exists := c.GetResultMap(qualifyId(mapper.Namespace, sel.ResultMap))
+			if !exists {
+				return fmt.Errorf("result map %s not found for statement %s", sel.ResultMap, statementId)
+			}
+			stmt.ResultMap = resultMap
+		}
+		c.addMapperStatement(stmt)
 	}
 
 	// 解析 insert 语句
 	for _, ins := range mapper.Inserts {
 		statementId := mapper.Namespace + "." + ins.ID
-		c.MapperConfig.Mappers[statementId] = &MapperStatement{
-			ID:            statementId,
-			SQL:           strings.TrimSpace(ins.SQL),
-			StatementType: INSERT,
+		stmt := &MapperStatement{
+			ID:               statementId,
+			Namespace:        mapper.Namespace,
+			SQL:              strings.TrimSpace(ins.SQL),
+			StatementType:    INSERT,
+			UseGeneratedKeys: ins.UseGeneratedKeys,
+			KeyProperty:      ins.KeyProperty,
+			KeyColumn:        ins.KeyColumn,
+			KeyGenerator:     ins.KeyGenerator,
+			FlushCache:       boolAttr(ins.FlushCache, true),
+			Plugins:          ins.Plugins,
 		}
+		if stmt.KeyGenerator != "" && stmt.KeyProperty == "" {
+			return fmt.Errorf("keyGenerator of statement %s requires keyProperty", statementId)
+		}
+		if ins.SelectKey != nil {
+			selectKey, err := parseSelectKey(statementId, ins.SelectKey)
+			if err != nil {
+				return err
+			}
+			stmt.SelectKey = selectKey
+		}
+		c.addMapperStatement(stmt)
 	}
 
 	// 解析 update 语句
 	for _, upd := range mapper.Updates {
 		statementId := mapper.Namespace + "." + upd.ID
-		c.MapperConfig.Mappers[statementId] = &MapperStatement{
+		c.addMapperStatement(&MapperStatement{
 			ID:            statementId,
+			Namespace:     mapper.Namespace,
 			SQL:           strings.TrimSpace(upd.SQL),
 			StatementType: UPDATE,
-		}
+			FlushCache:    boolAttr(upd.FlushCache, true),
+			Plugins:       upd.Plugins,
+		})
 	}
 
 	// 解析 delete 语句
 	for _, del := range mapper.Deletes {
 		statementId := mapper.Namespace + "." + del.ID
-		c.MapperConfig.Mappers[statementId] = &MapperStatement{
+		c.addMapperStatement(&MapperStatement{
 			ID:            statementId,
+			Namespace:     mapper.Namespace,
 			SQL:           strings.TrimSpace(del.SQL),
 			StatementType: DELETE,
+			FlushCache:    boolAttr(del.FlushCache, true),
+			Plugins:       del.Plugins,
+		})
+	}
+
+	// 解析 cache 与 cache-ref，被引用的命名空间可以稍后加载
+	if mapper.Cache != nil && mapper.CacheRef != nil {
+		return fmt.Errorf("namespace %s cannot declare both cache and cache-ref", mapper.Namespace)
+	}
+	if mapper.Cache != nil {
+		namespaceCache, err := c.buildCache(mapper.Namespace, mapper.Cache)
+		if err != nil {
+			return err
+		}
+		if c.Caches == nil {
+			c.Caches = make(map[string]cache.Cache)
 		}
+		c.Caches[mapper.Namespace] = namespaceCache
 	}
+	if mapper.CacheRef != nil {
+		if c.CacheRefs == nil {
+			c.CacheRefs = make(map[string]string)
+		}
+		c.CacheRefs[mapper.Namespace] = mapper.CacheRef.Namespace
+	}
+	c.resolveCaches()
 
 	return nil
 }
 
+// buildCache 按 <cache> 创建命名空间缓存，未声明 type 时使用内存缓存
+func (c *Configuration) buildCache(namespace string, xc *XMLCache) (cache.Cache, error) {
+	if xc.Type != "" {
+		factory, exists := c.CacheFactories[xc.Type]
+		if !exists {
+			return nil, fmt.Errorf("cache type %s not registered for namespace %s", xc.Type, namespace)
+		}
+		properties := make(map[string]string, len(xc.Properties))
+		for _, property := range xc.Properties {
+			properties[property.Name] = property.Value
+		}
+		return factory(namespace, properties)
+	}
+
+	options := cache.Options{Eviction: xc.Eviction, ReadOnly: xc.ReadOnly}
+	if xc.Size != "" {
+		size, err := strconv.Atoi(xc.Size)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache size %s for namespace %s", xc.Size, namespace)
+		}
+		options.Size = size
+	}
+	if xc.FlushInterval != "" {
+		millis, err := strconv.ParseInt(xc.FlushInterval, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache flushInterval %s for namespace %s", xc.FlushInterval, namespace)
+		}
+		options.FlushInterval = time.Duration(millis) * time.Millisecond
+	}
+
+	namespaceCache, err := cache.New(namespace, options)
+	if err != nil {
+		return nil, fmt.Errorf("namespace %s: %w", namespace, err)
+	}
+	return namespaceCache, nil
+}
+
+// resolveCaches 为尚未关联缓存的语句设置其命名空间的缓存
+func (c *Configuration) resolveCaches() {
+	for _, stmt := range c.MapperConfig.Mappers {
+		if stmt.Cache == nil {
+			stmt.Cache, _ = c.GetCache(stmt.Namespace)
+		}
+	}
+}
+
+// GetCache 获取命名空间的二级缓存，沿 cache-ref 查找被引用的缓存
+func (c *Configuration) GetCache(namespace string) (cache.Cache, bool) {
+	for i := 0; i <= len(c.CacheRefs); i++ {
+		if namespaceCache, exists := c.Caches[namespace]; exists {
+			return namespaceCache, true
+		}
+		ref, exists := c.CacheRefs[namespace]
+		if !exists {
+			return nil, false
+		}
+		namespace = ref
+	}
+	return nil, false
+}
+
+// RegisterCacheFactory 注册自定义缓存后端，供 <cache type="name"> 使用
+func (c *Configuration) RegisterCacheFactory(name string, factory cache.Factory) {
+	if c.CacheFactories == nil {
+		c.CacheFactories = make(map[string]cache.Factory)
+	}
+	c.CacheFactories[name] = factory
+}
+
+// boolAttr 获取可选的布尔属性，未声明时使用默认值
+func boolAttr(value *bool, defaultValue bool) bool {
+	if value == nil {
+		return defaultValue
+	}
+	return *value
+}
+
+// parseSelectKey 解析 <selectKey>，order 默认为 AFTER
+func parseSelectKey(statementId string, sk *XMLSelectKey) (*SelectKey, error) {
+	if sk.KeyProperty == "" {
+		return nil, fmt.Errorf("selectKey of statement %s requires keyProperty", statementId)
+	}
+
+	order := strings.ToUpper(strings.TrimSpace(sk.Order))
+	switch order {
+	case "":
+		order = SelectKeyAfter
+	case SelectKeyBefore, SelectKeyAfter:
+	default:
+		return nil, fmt.Errorf("invalid selectKey order %s for statement %s", sk.Order, statementId)
+	}
+
+	return &SelectKey{
+		SQL:         strings.TrimSpace(sk.SQL),
+		KeyProperty: sk.KeyProperty,
+		KeyColumn:   sk.KeyColumn,
+		ResultType:  sk.ResultType,
+		Order:       order,
+	}, nil
+}
+
 // AddPlugin 添加插件
 func (c *Configuration) AddPlugin(plugin Plugin) {
 	c.Plugins = append(c.Plugins, plugin)
 }
 
+// RegisterTypeHandler 注册类型处理器
+func (c *Configuration) RegisterTypeHandler(goType reflect.Type, handler types.TypeHandler) {
+	if c.TypeHandlerRegistry == nil {
+		c.TypeHandlerRegistry = types.NewTypeHandlerRegistry()
+	}
+	c.TypeHandlerRegistry.Register(goType, handler)
+}
+
+// RegisterIdGenerator 注册主键生成器，可覆盖内置的 snowflake、uuidv7、ulid
+func (c *Configuration) RegisterIdGenerator(name string, generator idgen.IdGenerator) {
+	if c.IdGenerators == nil {
+		c.IdGenerators = idgen.Defaults()
+	}
+	c.IdGenerators[name] = generator
+}
+
+// GetIdGenerator 获取主键生成器
+func (c *Configuration) GetIdGenerator(name string) (idgen.IdGenerator, bool) {
+	generator, exists := c.IdGenerators[name]
+	return generator, exists
+}
+
+// NewResultMapper 按配置创建结果映射器
+func (c *Configuration) NewResultMapper() mapping.ResultMapper {
+	return mapping.NewResultMapperWithOptions(mapping.Options{
+		TypeHandlers:                     c.TypeHandlerRegistry,
+		NamingStrategy:                   c.NamingStrategy,
+		Logger:                           c.Logger,
+		AutoMappingUnknownColumnBehavior: c.AutoMappingUnknownColumnBehavior,
+		RequireAllFieldsMapped:           c.RequireAllFieldsMapped,
+	})
+}
+
+// NewParameterBinder 按配置创建参数绑定器
+func (c *Configuration) NewParameterBinder() binding.ParameterBinder {
+	return binding.NewParameterBinderWithOptions(binding.Options{
+		TypeHandlers:   c.TypeHandlerRegistry,
+		NamingStrategy: c.NamingStrategy,
+		Logger:         c.Logger,
+	})
+}
+
+// GetResultMap 获取结果映射
+func (c *Configuration) GetResultMap(resultMapId string) (*mapping.ResultMap, bool) {
+	resultMap, exists := c.MapperConfig.ResultMaps[resultMapId]
+	return resultMap, exists
+}
+
+// qualifyId 为未带命名空间的 ID 补充当前命名空间
+func qualifyId(namespace, id string) string {
+	if strings.Contains(id, ".") {
+		return id
+	}
+	return namespace + "." + id
+}
+
+// addMapperStatement 注册语句并解析其占位符计划
+func (c *Configuration) addMapperStatement(stmt *MapperStatement) {
+	stmt.plan = binding.NewPlan(stmt.SQL)
+	c.MapperConfig.Mappers[stmt.ID] = stmt
+}
+
 // GetMapperStatement 获取 Mapper 语句
 func (c *Configuration) GetMapperStatement(statementId string) (*MapperStatement, bool) {
 	stmt, exists := c.MapperConfig.Mappers[statementId]
@@ -160,35 +519,100 @@ func (c *Configuration) GetMapperStatement(statementId string) (*MapperStatement
 
 // XMLMapper XML Mapper 结构
 type XMLMapper struct {
-	XMLName   xml.Name    `xml:"mapper"`
-	Namespace string      `xml:"namespace,attr"`
-	Selects   []XMLSelect `xml:"select"`
-	Inserts   []XMLInsert `xml:"insert"`
-	Updates   []XMLUpdate `xml:"update"`
-	Deletes   []XMLDelete `xml:"delete"`
+	XMLName    xml.Name       `xml:"mapper"`
+	Namespace  string         `xml:"namespace,attr"`
+	ResultMaps []XMLResultMap `xml:"resultMap"`
+	Selects    []XMLSelect    `xml:"select"`
+	Inserts    []XMLInsert    `xml:"insert"`
+	Updates    []XMLUpdate    `xml:"update"`
+	Deletes    []XMLDelete    `xml:"delete"`
+	Cache      *XMLCache      `xml:"cache"`
+	CacheRef   *XMLCacheRef   `xml:"cache-ref"`
+}
+
+// XMLCache XML 二级缓存配置
+type XMLCache struct {
+	Type          string        `xml:"type,attr"`
+	Eviction      string        `xml:"eviction,attr"`
+	FlushInterval string        `xml:"flushInterval,attr"` // 毫秒
+	Size          string        `xml:"size,attr"`
+	ReadOnly      bool          `xml:"readOnly,attr"`
+	Properties    []XMLProperty `xml:"property"`
+}
+
+// XMLCacheRef XML 缓存引用配置
+type XMLCacheRef struct {
+	Namespace string `xml:"namespace,attr"`
+}
+
+// XMLProperty XML 属性配置
+type XMLProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// XMLResultMap XML ResultMap 配置
+type XMLResultMap struct {
+	ID      string      `xml:"id,attr"`
+	Type    string      `xml:"type,attr"`
+	IDs     []XMLResult `xml:"id"`
+	Results []XMLResult `xml:"result"`
+}
+
+// XMLResult XML ResultMap 中的列映射
+type XMLResult struct {
+	Property    string `xml:"property,attr"`
+	Column      string `xml:"column,attr"`
+	JdbcType    string `xml:"jdbcType,attr"`
+	TypeHandler string `xml:"typeHandler,attr"`
 }
 
 // XMLSelect XML Select 语句
 type XMLSelect struct {
-	ID         string `xml:"id,attr"`
-	ResultType string `xml:"resultType,attr"`
-	SQL        string `xml:",chardata"`
+	ID             string `xml:"id,attr"`
+	ResultType     string `xml:"resultType,attr"`
+	ResultMap      string `xml:"resultMap,attr"`
+	FlushCache     *bool  `xml:"flushCache,attr"`
+	UseCache       *bool  `xml:"useCache,attr"`
+	CountStatement string `xml:"countStatement,attr"`
+	Plugins        string `xml:"plugins,attr"`
+	SQL            string `xml:",chardata"`
 }
 
 // XMLInsert XML Insert 语句
 type XMLInsert struct {
-	ID  string `xml:"id,attr"`
-	SQL string `xml:",chardata"`
+	ID               string        `xml:"id,attr"`
+	UseGeneratedKeys bool          `xml:"useGeneratedKeys,attr"`
+	KeyProperty      string        `xml:"keyProperty,attr"`
+	KeyColumn        string        `xml:"keyColumn,attr"`
+	KeyGenerator     string        `xml:"keyGenerator,attr"`
+	FlushCache       *bool         `xml:"flushCache,attr"`
+	Plugins          string        `xml:"plugins,attr"`
+	SelectKey        *XMLSelectKey `xml:"selectKey"`
+	SQL              string        `xml:",chardata"`
+}
+
+// XMLSelectKey XML SelectKey 配置
+type XMLSelectKey struct {
+	KeyProperty string `xml:"keyProperty,attr"`
+	KeyColumn   string `xml:"keyColumn,attr"`
+	ResultType  string `xml:"resultType,attr"`
+	Order       string `xml:"order,attr"`
+	SQL         string `xml:",chardata"`
 }
 
 // XMLUpdate XML Update 语句
 type XMLUpdate struct {
-	ID  string `xml:"id,attr"`
-	SQL string `xml:",chardata"`
+	ID         string `xml:"id,attr"`
+	FlushCache *bool  `xml:"flushCache,attr"`
+	Plugins    string `xml:"plugins,attr"`
+	SQL        string `xml:",chardata"`
 }
 
 // XMLDelete XML Delete 语句
 type XMLDelete struct {
-	ID  string `xml:"id,attr"`
-	SQL string `xml:",chardata"`
+	ID         string `xml:"id,attr"`
+	FlushCache *bool  `xml:"flushCache,attr"`
+	Plugins    string `xml:"plugins,attr"`
+	SQL        string `xml:",chardata"`
 }
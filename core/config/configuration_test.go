@@ -3,8 +3,12 @@ package config
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+
+	"gobatis/cache"
+	"gobatis/reflection"
 )
 
 // MockPlugin 模拟插件用于测试
@@ -20,6 +24,10 @@ func (p *MockPlugin) SetProperties(properties map[string]string) {
 	p.properties = properties
 }
 
+func (p *MockPlugin) GetOrder() int {
+	return 0
+}
+
 // TestNewConfiguration 测试创建新配置
 func TestNewConfiguration(t *testing.T) {
 	config := NewConfiguration()
@@ -49,6 +57,31 @@ func TestNewConfiguration(t *testing.T) {
 	}
 }
 
+// TestConfiguration_NamingStrategy 测试命名策略配置传递给参数绑定器
+func TestConfiguration_NamingStrategy(t *testing.T) {
+	config := NewConfiguration()
+	if _, ok := config.NamingStrategy.(reflection.SnakeCaseStrategy); !ok {
+		t.Fatalf("Expected snake_case naming strategy by default, got %T", config.NamingStrategy)
+	}
+
+	type user struct {
+		UserID int64
+	}
+
+	config.NamingStrategy = reflection.LowerCamelStrategy{}
+	_, args, err := config.NewParameterBinder().BindParameters("SELECT * FROM users WHERE id = #{userID}", user{UserID: 3})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(args) != 1 || args[0] != int64(3) {
+		t.Fatalf("Expected [3], got %v", args)
+	}
+
+	if config.NewResultMapper() == nil {
+		t.Fatal("ResultMapper should not be nil")
+	}
+}
+
 // TestSetDataSource_InvalidDriver 测试设置无效数据源
 func TestSetDataSource_InvalidDriver(t *testing.T) {
 	config := NewConfiguration()
@@ -246,6 +279,179 @@ func TestAddMapperXML_ValidXML(t *testing.T) {
 	}
 }
 
+// TestAddMapperXML_ResultMap 测试解析 resultMap
+func TestAddMapperXML_ResultMap(t *testing.T) {
+	config := NewConfiguration()
+
+	tempFile, err := ioutil.TempFile("", "resultmap_*.xml")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	xmlContent := `<?xml version="1.0" encoding="UTF-8"?>
+<mapper namespace="AccountMapper">
+    <resultMap id="AccountMap" type="Account">
+        <id property="ID" column="id"/>
+        <result property="Settings" column="settings" typeHandler="json"/>
+    </resultMap>
+    <select id="GetAccount" resultMap="AccountMap">
+        SELECT id, settings FROM accounts WHERE id = #{id}
+    </select>
+</mapper>`
+
+	if _, err := tempFile.WriteString(xmlContent); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	tempFile.Close()
+
+	if err := config.AddMapperXML(tempFile.Name()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resultMap, exists := config.GetResultMap("AccountMapper.AccountMap")
+	if !exists {
+		t.Fatal("AccountMap should exist")
+	}
+	if len(resultMap.Mappings) != 2 {
+		t.Fatalf("Expected 2 mappings, got %d", len(resultMap.Mappings))
+	}
+
+	mapping, ok := resultMap.GetMappingByColumn("settings")
+	if !ok || mapping.Property != "Settings" || mapping.TypeHandler != "json" {
+		t.Fatalf("Unexpected settings mapping: %+v", mapping)
+	}
+
+	stmt, exists := config.GetMapperStatement("AccountMapper.GetAccount")
+	if !exists {
+		t.Fatal("GetAccount statement should exist")
+	}
+	if stmt.ResultMap != resultMap {
+		t.Fatal("Statement should reference AccountMap")
+	}
+}
+
+// TestAddMapperXML_MissingResultMap 测试引用不存在的 resultMap
+func TestAddMapperXML_MissingResultMap(t *testing.T) {
+	config := NewConfiguration()
+
+	tempFile, err := ioutil.TempFile("", "resultmap_*.xml")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	xmlContent := `<mapper namespace="AccountMapper">
+    <select id="GetAccount" resultMap="Missing">SELECT 1</select>
+</mapper>`
+
+	if _, err := tempFile.WriteString(xmlContent); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	tempFile.Close()
+
+	if err := config.AddMapperXML(tempFile.Name()); err == nil {
+		t.Fatal("Expected error for missing result map")
+	}
+}
+
+// addMapperXMLContent 将 XML 内容写入临时文件并加载
+func addMapperXMLContent(t *testing.T, config *Configuration, xmlContent string) error {
+	tempFile, err := ioutil.TempFile("", "mapper_*.xml")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := tempFile.WriteString(xmlContent); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	tempFile.Close()
+
+	return config.AddMapperXML(tempFile.Name())
+}
+
+// TestAddMapperXML_GeneratedKeys 测试解析 insert 的主键回填配置
+func TestAddMapperXML_GeneratedKeys(t *testing.T) {
+	config := NewConfiguration()
+
+	err := addMapperXMLContent(t, config, `<mapper namespace="UserMapper">
+    <insert id="InsertUser" useGeneratedKeys="true" keyProperty="ID" keyColumn="id">
+        INSERT INTO users (name) VALUES (#{name})
+    </insert>
+</mapper>`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	stmt, exists := config.GetMapperStatement("UserMapper.InsertUser")
+	if !exists {
+		t.Fatal("InsertUser statement should exist")
+	}
+	if !stmt.UseGeneratedKeys || stmt.KeyProperty != "ID" || stmt.KeyColumn != "id" {
+		t.Fatalf("Unexpected generated key settings: %+v", stmt)
+	}
+
+	err = addMapperXMLContent(t, config, `<mapper namespace="OrderMapper">
+    <insert id="InsertOrder" keyGenerator="snowflake" keyProperty="ID">
+        INSERT INTO orders (id, name) VALUES (#{id}, #{name})
+    </insert>
+</mapper>`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	stmt, _ = config.GetMapperStatement("OrderMapper.InsertOrder")
+	if stmt.KeyGenerator != "snowflake" {
+		t.Fatalf("Expected snowflake key generator, got %q", stmt.KeyGenerator)
+	}
+	if _, exists := config.GetIdGenerator(stmt.KeyGenerator); !exists {
+		t.Fatal("Expected built-in snowflake generator")
+	}
+}
+
+// TestAddMapperXML_SelectKey 测试解析 selectKey
+func TestAddMapperXML_SelectKey(t *testing.T) {
+	config := NewConfiguration()
+
+	err := addMapperXMLContent(t, config, `<mapper namespace="UserMapper">
+    <insert id="InsertUser">
+        <selectKey keyProperty="ID" resultType="int64" order="before">
+            SELECT nextval('users_seq')
+        </selectKey>
+        INSERT INTO users (id, name) VALUES (#{id}, #{name})
+    </insert>
+    <insert id="InsertLog">
+        INSERT INTO logs (message) VALUES (#{message})
+        <selectKey keyProperty="ID">SELECT LAST_INSERT_ID()</selectKey>
+    </insert>
+</mapper>`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	stmt, _ := config.GetMapperStatement("UserMapper.InsertUser")
+	if stmt.SQL != "INSERT INTO users (id, name) VALUES (#{id}, #{name})" {
+		t.Fatalf("selectKey should not be part of the SQL, got %q", stmt.SQL)
+	}
+	selectKey := stmt.SelectKey
+	if selectKey == nil || selectKey.Order != SelectKeyBefore || selectKey.KeyProperty != "ID" ||
+		selectKey.ResultType != "int64" || selectKey.SQL != "SELECT nextval('users_seq')" {
+		t.Fatalf("Unexpected selectKey: %+v", selectKey)
+	}
+
+	stmt, _ = config.GetMapperStatement("UserMapper.InsertLog")
+	if stmt.SelectKey == nil || stmt.SelectKey.Order != SelectKeyAfter {
+		t.Fatalf("Expected AFTER order by default, got %+v", stmt.SelectKey)
+	}
+
+	err = addMapperXMLContent(t, NewConfiguration(), `<mapper namespace="UserMapper">
+    <insert id="InsertUser"><selectKey keyProperty="ID" order="LATER">SELECT 1</selectKey>INSERT INTO users VALUES (#{id})</insert>
+</mapper>`)
+	if err == nil {
+		t.Fatal("Expected error for invalid selectKey order")
+	}
+}
+
 // TestStatementType 测试语句类型常量
 func TestStatementType(t *testing.T) {
 	if SELECT != 0 {
@@ -369,3 +575,147 @@ func TestInvocation(t *testing.T) {
 		t.Fatalf("Expected second arg to be 'test', got %v", invocation.Args[1])
 	}
 }
+
+// TestAddMapperXML_CacheAttributes 测试 flushCache 与 useCache 属性及其默认值
+func TestAddMapperXML_CacheAttributes(t *testing.T) {
+	config := NewConfiguration()
+
+	err := addMapperXMLContent(t, config, `<mapper namespace="UserMapper">
+    <select id="GetUser">SELECT * FROM users WHERE id = #{id}</select>
+    <select id="GetNow" flushCache="true" useCache="false">SELECT NOW()</select>
+    <update id="Touch" flushCache="false">UPDATE users SET seen = 1</update>
+    <delete id="DeleteUser">DELETE FROM users WHERE id = #{id}</delete>
+</mapper>`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testCases := map[string][2]bool{
+		"UserMapper.GetUser":    {false, true},
+		"UserMapper.GetNow":     {true, false},
+		"UserMapper.Touch":      {false, false},
+		"UserMapper.DeleteUser": {true, false},
+	}
+	for id, expected := range testCases {
+		stmt, _ := config.GetMapperStatement(id)
+		if stmt.FlushCache != expected[0] || stmt.UseCache != expected[1] {
+			t.Errorf("%s: flushCache=%v useCache=%v, expected %v", id, stmt.FlushCache, stmt.UseCache, expected)
+		}
+	}
+}
+
+// TestAddMapperXML_Cache 测试 <cache> 与 <cache-ref> 的解析
+func TestAddMapperXML_Cache(t *testing.T) {
+	config := NewConfiguration()
+
+	// 被引用的命名空间可以稍后加载
+	err := addMapperXMLContent(t, config, `<mapper namespace="OrderMapper">
+    <cache-ref namespace="UserMapper"/>
+    <select id="GetOrder">SELECT * FROM orders WHERE id = #{id}</select>
+</mapper>`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	err = addMapperXMLContent(t, config, `<mapper namespace="UserMapper">
+    <cache eviction="FIFO" flushInterval="60000" size="2" readOnly="true"/>
+    <select id="GetUser">SELECT * FROM users WHERE id = #{id}</select>
+</mapper>`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	userCache, exists := config.Caches["UserMapper"]
+	if !exists {
+		t.Fatal("Expected UserMapper cache")
+	}
+	getUser, _ := config.GetMapperStatement("UserMapper.GetUser")
+	getOrder, _ := config.GetMapperStatement("OrderMapper.GetOrder")
+	if getUser.Cache != userCache || getOrder.Cache != userCache {
+		t.Error("Expected both namespaces to share the UserMapper cache")
+	}
+	if getUser.Namespace != "UserMapper" {
+		t.Errorf("Expected namespace UserMapper, got %s", getUser.Namespace)
+	}
+
+	// size 限制缓存项数
+	for _, key := range []string{"a", "b", "c"} {
+		userCache.Put(key, key)
+	}
+	if userCache.Size() != 2 {
+		t.Errorf("Expected size 2, got %d", userCache.Size())
+	}
+
+	// 自定义缓存后端
+	config.RegisterCacheFactory("custom", func(id string, properties map[string]string) (cache.Cache, error) {
+		if properties["host"] != "localhost" {
+			t.Errorf("Expected host property, got %v", properties)
+		}
+		return cache.NewLRUCache(id, 10), nil
+	})
+	err = addMapperXMLContent(t, config, `<mapper namespace="ItemMapper">
+    <cache type="custom"><property name="host" value="localhost"/></cache>
+</mapper>`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	invalid := []string{
+		`<mapper namespace="A"><cache eviction="RANDOM"/></mapper>`,
+		`<mapper namespace="A"><cache size="large"/></mapper>`,
+		`<mapper namespace="A"><cache type="missing"/></mapper>`,
+		`<mapper namespace="A"><cache/><cache-ref namespace="B"/></mapper>`,
+	}
+	for _, content := range invalid {
+		if err := addMapperXMLContent(t, NewConfiguration(), content); err == nil {
+			t.Errorf("Expected error for %s", content)
+		}
+	}
+}
+
+// TestAddMapperXML_CountStatement 测试 countStatement 属性补充命名空间
+func TestAddMapperXML_CountStatement(t *testing.T) {
+	config := NewConfiguration()
+
+	err := addMapperXMLContent(t, config, `<mapper namespace="UserMapper">
+    <select id="listUsers" countStatement="countUsers">SELECT * FROM users</select>
+    <select id="listAdmins" countStatement="AdminMapper.countAdmins">SELECT * FROM admins</select>
+</mapper>`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	listUsers, _ := config.GetMapperStatement("UserMapper.listUsers")
+	if listUsers.CountStatement != "UserMapper.countUsers" {
+		t.Errorf("Expected UserMapper.countUsers, got %s", listUsers.CountStatement)
+	}
+	listAdmins, _ := config.GetMapperStatement("UserMapper.listAdmins")
+	if listAdmins.CountStatement != "AdminMapper.countAdmins" {
+		t.Errorf("Expected AdminMapper.countAdmins, got %s", listAdmins.CountStatement)
+	}
+}
+
+// TestMapperStatement_PlaceholderPlan 测试加载的语句缓存占位符计划，改写过 SQL 的副本不复用
+func TestMapperStatement_PlaceholderPlan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "user.xml")
+	os.WriteFile(path, []byte(`<mapper namespace="UserMapper">
+    <select id="GetUser">SELECT id FROM users WHERE id = #{id}</select>
+</mapper>`), 0o644)
+
+	config := NewConfiguration()
+	if err := config.AddMapperXML(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	stmt, _ := config.GetMapperStatement("UserMapper.GetUser")
+	if stmt.PlaceholderPlan() != stmt.PlaceholderPlan() {
+		t.Error("Expected loaded statement to reuse its plan")
+	}
+
+	rewritten := *stmt
+	rewritten.SQL += " LIMIT 10"
+	if plan := rewritten.PlaceholderPlan(); plan.Source() != rewritten.SQL {
+		t.Errorf("Expected plan of the rewritten SQL, got %q", plan.Source())
+	}
+	if stmt.PlaceholderPlan().Source() != stmt.SQL {
+		t.Error("Expected rewritten copy not to replace the statement's plan")
+	}
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Plugin 插件接口
+type Plugin interface {
+	// Intercept 拦截方法调用
+	Intercept(invocation *Invocation) (interface{}, error)
+	// SetProperties 设置插件属性
+	SetProperties(properties map[string]string)
+	// GetOrder 获取插件执行顺序（数字越小优先级越高）
+	GetOrder() int
+}
+
+// 可拦截的目标：SqlSession 的 SelectOne、SelectList、Insert、Update、Delete，
+// 以及执行器内部的 Executor、StatementHandler、ParameterHandler、ResultSetHandler
+const (
+	TargetSqlSession       = "SqlSession"
+	TargetExecutor         = "Executor"
+	TargetStatementHandler = "StatementHandler"
+	TargetParameterHandler = "ParameterHandler"
+	TargetResultSetHandler = "ResultSetHandler"
+)
+
+// BoundSQL 绑定参数后发送到数据库的 SQL，拦截器可修改 SQL 与 Args
+type BoundSQL struct {
+	SQL           string
+	Args          []interface{}
+	StatementID   string
+	StatementType StatementType
+}
+
+// StatementExecutor 插件可用于执行附加语句的执行器
+type StatementExecutor interface {
+	Query(statement *MapperStatement, parameter interface{}) ([]interface{}, error)
+	Update(statement *MapperStatement, parameter interface{}) (int64, error)
+}
+
+// Signature 插件拦截的目标与方法，Method 为空时拦截目标的所有方法
+type Signature struct {
+	Target string
+	Method string
+}
+
+// Interceptor 声明拦截点的插件，未实现时插件只拦截 SqlSession 的方法
+type Interceptor interface {
+	Plugin
+	// Signatures 返回插件拦截的目标与方法
+	Signatures() []Signature
+}
+
+// Invocation 拦截调用信息
+type Invocation struct {
+	Target      interface{}                 // 目标对象
+	Method      reflect.Method              // 调用的方法
+	Args        []interface{}               // 方法参数
+	StatementId string                      // SQL 语句 ID
+	Properties  map[string]interface{}      // 额外属性
+	Proceed     func() (interface{}, error) // 继续执行的函数
+	Context     *InvocationContext          // 调用上下文
+	// Statement 将要执行的语句，插件可替换为修改了 SQL 的副本，Proceed 执行替换后的语句
+	Statement *MapperStatement
+	// Parameter 语句参数
+	Parameter interface{}
+	// Executor 当前会话的执行器，插件可在同一事务中执行附加查询
+	Executor StatementExecutor
+	// Configuration 框架配置，用于查找其他语句
+	Configuration *Configuration
+	// Signature 当前拦截的目标与方法
+	Signature Signature
+	// BoundSQL 执行器内部阶段绑定后的 SQL 与参数，插件修改后按修改后的内容执行
+	BoundSQL *BoundSQL
+	// Rows ResultSetHandler 阶段待映射的结果集
+	Rows *sql.Rows
+}
+
+// InvocationContext 调用上下文，用于错误处理和回滚
+type InvocationContext struct {
+	StartTime     time.Time              // 开始时间
+	PluginStates  map[string]interface{} // 插件状态
+	RollbackFuncs []func() error         // 回滚函数列表
+	mutex         sync.RWMutex           // 保护并发访问
+}
+
+// NewInvocationContext 创建新的调用上下文
+func NewInvocationContext() *InvocationContext {
+	return &InvocationContext{
+		StartTime:     time.Now(),
+		PluginStates:  make(map[string]interface{}),
+		RollbackFuncs: make([]func() error, 0),
+	}
+}
+
+// SetPluginState 设置插件状态
+func (ctx *InvocationContext) SetPluginState(pluginName string, state interface{}) {
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+	ctx.PluginStates[pluginName] = state
+}
+
+// GetPluginState 获取插件状态
+func (ctx *InvocationContext) GetPluginState(pluginName string) (interface{}, bool) {
+	ctx.mutex.RLock()
+	defer ctx.mutex.RUnlock()
+	state, exists := ctx.PluginStates[pluginName]
+	return state, exists
+}
+
+// AddRollbackFunc 添加回滚函数
+func (ctx *InvocationContext) AddRollbackFunc(rollbackFunc func() error) {
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+	ctx.RollbackFuncs = append(ctx.RollbackFuncs, rollbackFunc)
+}
+
+// ExecuteRollback 执行回滚
+func (ctx *InvocationContext) ExecuteRollback() []error {
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+
+	var errors []error
+	// 逆序执行回滚函数
+	for i := len(ctx.RollbackFuncs) - 1; i >= 0; i-- {
+		if err := ctx.RollbackFuncs[i](); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	return errors
+}
+
+// PluginFactory 创建插件，配置文件中以注册名引用
+type PluginFactory func() Plugin
+
+var pluginRegistry = struct {
+	sync.RWMutex
+	factories map[string]PluginFactory
+}{factories: make(map[string]PluginFactory)}
+
+// RegisterPlugin 以名称注册插件，配置文件中 <plugin interceptor="name"> 引用该名称；重复注册时覆盖
+func RegisterPlugin(name string, factory PluginFactory) {
+	pluginRegistry.Lock()
+	defer pluginRegistry.Unlock()
+	pluginRegistry.factories[name] = factory
+}
+
+// RegisteredPlugins 返回已注册的插件名称
+func RegisteredPlugins() []string {
+	pluginRegistry.RLock()
+	defer pluginRegistry.RUnlock()
+	names := make([]string, 0, len(pluginRegistry.factories))
+	for name := range pluginRegistry.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewPlugin 按注册名创建插件并设置属性
+func NewPlugin(name string, properties map[string]string) (Plugin, error) {
+	pluginRegistry.RLock()
+	factory, exists := pluginRegistry.factories[name]
+	pluginRegistry.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("plugin %s is not registered", name)
+	}
+
+	plugin := factory()
+	if properties == nil {
+		properties = make(map[string]string)
+	}
+	plugin.SetProperties(properties)
+	return plugin, nil
+}
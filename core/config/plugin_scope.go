@@ -0,0 +1,231 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// regexPrefix 以此开头的模式按正则匹配，否则按 path.Match 的 glob 匹配
+const regexPrefix = "regex:"
+
+// StatementScope 插件适用的语句，未设置的条件不作限制
+type StatementScope struct {
+	Statements        []string        // 语句 ID 模式，如 "UserMapper.select*" 或 "regex:^UserMapper\\.(get|find)"
+	ExcludeStatements []string        // 排除的语句 ID 模式
+	Namespaces        []string        // 命名空间模式
+	ExcludeNamespaces []string        // 排除的命名空间模式
+	StatementTypes    []StatementType // 语句类型
+}
+
+// IsEmpty 是否未设置任何条件
+func (s StatementScope) IsEmpty() bool {
+	return len(s.Statements) == 0 && len(s.ExcludeStatements) == 0 &&
+		len(s.Namespaces) == 0 && len(s.ExcludeNamespaces) == 0 && len(s.StatementTypes) == 0
+}
+
+// Validate 检查模式是否合法
+func (s StatementScope) Validate() error {
+	for _, patterns := range [][]string{s.Statements, s.ExcludeStatements, s.Namespaces, s.ExcludeNamespaces} {
+		for _, pattern := range patterns {
+			if _, err := matchPattern(pattern, ""); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Matches 语句是否在范围内，非法的模式视为不匹配
+func (s StatementScope) Matches(statement *MapperStatement) bool {
+	if len(s.StatementTypes) > 0 && !containsStatementType(s.StatementTypes, statement.StatementType) {
+		return false
+	}
+	if len(s.Statements) > 0 && !matchAny(s.Statements, statement.ID) {
+		return false
+	}
+	if matchAny(s.ExcludeStatements, statement.ID) {
+		return false
+	}
+	if len(s.Namespaces) > 0 && !matchAny(s.Namespaces, statement.Namespace) {
+		return false
+	}
+	return !matchAny(s.ExcludeNamespaces, statement.Namespace)
+}
+
+func containsStatementType(types []StatementType, statementType StatementType) bool {
+	for _, t := range types {
+		if t == statementType {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := matchPattern(pattern, value); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// compiledPatterns 已编译的正则模式
+var compiledPatterns sync.Map
+
+func matchPattern(pattern, value string) (bool, error) {
+	if !strings.HasPrefix(pattern, regexPrefix) {
+		matched, err := path.Match(pattern, value)
+		if err != nil {
+			return false, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		return matched, nil
+	}
+
+	expr := strings.TrimPrefix(pattern, regexPrefix)
+	if compiled, ok := compiledPatterns.Load(expr); ok {
+		return compiled.(*regexp.Regexp).MatchString(value), nil
+	}
+	compiled, err := regexp.Compile(expr)
+	if err != nil {
+		return false, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	compiledPatterns.Store(expr, compiled)
+	return compiled.MatchString(value), nil
+}
+
+// ParseStatementType 解析语句类型名称，不区分大小写
+func ParseStatementType(name string) (StatementType, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "select":
+		return SELECT, nil
+	case "insert":
+		return INSERT, nil
+	case "update":
+		return UPDATE, nil
+	case "delete":
+		return DELETE, nil
+	}
+	return 0, fmt.Errorf("unknown statement type %q", name)
+}
+
+// ScopedPlugin 声明适用语句的插件
+type ScopedPlugin interface {
+	Plugin
+	Scope() StatementScope
+}
+
+// NamedPlugin 声明名称的插件，名称用于语句的 plugins 属性
+type NamedPlugin interface {
+	Plugin
+	Name() string
+}
+
+// PluginName 插件名称：实现 NamedPlugin 时为 Name()，否则为类型名去掉 Plugin 后缀并将首字母小写，
+// 如 PaginationPlugin 为 pagination
+func PluginName(plugin Plugin) string {
+	if named, ok := plugin.(NamedPlugin); ok {
+		return named.Name()
+	}
+	t := reflect.TypeOf(plugin)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	name := strings.TrimSuffix(t.Name(), "Plugin")
+	if name == "" {
+		return t.Name()
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// scopedPlugin 以 NewScopedPlugin 或配置文件限定适用语句的插件
+type scopedPlugin struct {
+	Plugin
+	name  string
+	scope StatementScope
+}
+
+// NewScopedPlugin 限定插件适用的语句，名称与拦截点不变
+func NewScopedPlugin(plugin Plugin, scope StatementScope) Plugin {
+	return &scopedPlugin{Plugin: plugin, name: PluginName(plugin), scope: scope}
+}
+
+// Name 实现 NamedPlugin
+func (p *scopedPlugin) Name() string {
+	return p.name
+}
+
+// Scope 实现 ScopedPlugin
+func (p *scopedPlugin) Scope() StatementScope {
+	return p.scope
+}
+
+// Signatures 实现 Interceptor，未声明拦截点的插件只拦截 SqlSession
+func (p *scopedPlugin) Signatures() []Signature {
+	if interceptor, ok := p.Plugin.(Interceptor); ok {
+		return interceptor.Signatures()
+	}
+	return []Signature{{Target: TargetSqlSession}}
+}
+
+// Unwrap 返回被限定的插件
+func (p *scopedPlugin) Unwrap() Plugin {
+	return p.Plugin
+}
+
+// PluginSelection 语句 plugins 属性声明的插件选择，如 "!pagination, tenant"：
+// 带 ! 的名称对该语句禁用，"!*" 禁用所有未显式启用的插件，其余名称不受插件的 StatementScope 限制
+type PluginSelection struct {
+	Enabled    []string
+	Disabled   []string
+	DisableAll bool
+}
+
+// ParsePluginSelection 解析语句的 plugins 属性
+func ParsePluginSelection(value string) PluginSelection {
+	var selection PluginSelection
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		switch {
+		case item == "":
+		case item == "!*":
+			selection.DisableAll = true
+		case strings.HasPrefix(item, "!"):
+			selection.Disabled = append(selection.Disabled, strings.TrimSpace(item[1:]))
+		default:
+			selection.Enabled = append(selection.Enabled, item)
+		}
+	}
+	return selection
+}
+
+// PluginAppliesTo 插件是否作用于语句：语句的 plugins 属性优先，其次为插件的 StatementScope
+func PluginAppliesTo(plugin Plugin, statement *MapperStatement) bool {
+	if statement.Plugins != "" {
+		selection := ParsePluginSelection(statement.Plugins)
+		name := PluginName(plugin)
+		if containsName(selection.Enabled, name) {
+			return true
+		}
+		if selection.DisableAll || containsName(selection.Disabled, name) {
+			return false
+		}
+	}
+	if scoped, ok := plugin.(ScopedPlugin); ok {
+		return scoped.Scope().Matches(statement)
+	}
+	return true
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
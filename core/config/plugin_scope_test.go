@@ -0,0 +1,64 @@
+package config
+
+import "testing"
+
+// TestStatementScope_Matches 测试按语句 ID、命名空间与类型限定插件
+func TestStatementScope_Matches(t *testing.T) {
+	selectUser := &MapperStatement{ID: "UserMapper.selectUser", Namespace: "UserMapper", StatementType: SELECT}
+	updateUser := &MapperStatement{ID: "UserMapper.updateUser", Namespace: "UserMapper", StatementType: UPDATE}
+	adminSelect := &MapperStatement{ID: "admin.UserMapper.selectAll", Namespace: "admin.UserMapper", StatementType: SELECT}
+
+	tests := []struct {
+		name     string
+		scope    StatementScope
+		expected []bool // selectUser, updateUser, adminSelect
+	}{
+		{"empty", StatementScope{}, []bool{true, true, true}},
+		{"glob", StatementScope{Statements: []string{"UserMapper.select*"}}, []bool{true, false, false}},
+		{"regex", StatementScope{Statements: []string{`regex:\.select`}}, []bool{true, false, true}},
+		{"exclude statement", StatementScope{ExcludeStatements: []string{"UserMapper.update*"}}, []bool{true, false, true}},
+		{"namespace", StatementScope{Namespaces: []string{"UserMapper"}}, []bool{true, true, false}},
+		{"exclude namespace", StatementScope{ExcludeNamespaces: []string{"admin.*"}}, []bool{true, true, false}},
+		{"statement type", StatementScope{StatementTypes: []StatementType{UPDATE}}, []bool{false, true, false}},
+	}
+	for _, tt := range tests {
+		for i, statement := range []*MapperStatement{selectUser, updateUser, adminSelect} {
+			if matched := tt.scope.Matches(statement); matched != tt.expected[i] {
+				t.Errorf("%s: expected %v for %s, got %v", tt.name, tt.expected[i], statement.ID, matched)
+			}
+		}
+	}
+
+	if err := (StatementScope{Statements: []string{"regex:("}}).Validate(); err == nil {
+		t.Error("Expected error for invalid regex")
+	}
+	if err := (StatementScope{Namespaces: []string{"["}}).Validate(); err == nil {
+		t.Error("Expected error for invalid glob")
+	}
+}
+
+// TestPluginAppliesTo 测试语句 plugins 属性优先于插件的适用范围
+func TestPluginAppliesTo(t *testing.T) {
+	tenant := NewScopedPlugin(&MockPlugin{}, StatementScope{ExcludeNamespaces: []string{"admin"}})
+	if name := PluginName(tenant); name != "mock" {
+		t.Errorf("Expected name derived from type, got %s", name)
+	}
+
+	tests := []struct {
+		statement MapperStatement
+		expected  bool
+	}{
+		{MapperStatement{ID: "UserMapper.select", Namespace: "UserMapper"}, true},
+		{MapperStatement{ID: "admin.select", Namespace: "admin"}, false},
+		{MapperStatement{ID: "admin.select", Namespace: "admin", Plugins: "mock"}, true},
+		{MapperStatement{ID: "UserMapper.select", Namespace: "UserMapper", Plugins: "!mock"}, false},
+		{MapperStatement{ID: "UserMapper.select", Namespace: "UserMapper", Plugins: "!*"}, false},
+		{MapperStatement{ID: "UserMapper.select", Namespace: "UserMapper", Plugins: "!*, mock"}, true},
+		{MapperStatement{ID: "UserMapper.select", Namespace: "UserMapper", Plugins: "!pagination"}, true},
+	}
+	for _, tt := range tests {
+		if applies := PluginAppliesTo(tenant, &tt.statement); applies != tt.expected {
+			t.Errorf("Expected %v for %s with plugins=%q, got %v", tt.expected, tt.statement.ID, tt.statement.Plugins, applies)
+		}
+	}
+}
@@ -13,6 +13,9 @@ type Example struct {
 	distinct      bool
 	limitStart    *int
 	limitEnd      *int
+	seekColumns   []string
+	seekValues    []interface{}
+	seekDesc      bool
 }
 
 // Criteria 查询条件组
@@ -54,6 +57,9 @@ func isValidOrderByClause(orderBy string) bool {
 	return matched
 }
 
+// columnNamePattern 安全的列名：列名或表名.列名
+var columnNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+
 // SetOrderByClause 设置排序子句
 func (e *Example) SetOrderByClause(orderByClause string) {
 	if isValidOrderByClause(orderByClause) {
@@ -73,6 +79,22 @@ func (e *Example) SetLimit(start, end int) {
 	e.limitEnd = &end
 }
 
+// SetSeek 设置键集分页条件 (c1, c2) > (?, ?)，descending 为 true 时比较符为 <，
+// 与其他条件以 AND 连接；列名不安全或与值数量不一致时忽略
+func (e *Example) SetSeek(columns []string, values []interface{}, descending bool) {
+	if len(columns) == 0 || len(columns) != len(values) {
+		return
+	}
+	for _, column := range columns {
+		if !columnNamePattern.MatchString(column) {
+			return
+		}
+	}
+	e.seekColumns = columns
+	e.seekValues = values
+	e.seekDesc = descending
+}
+
 // GetOredCriteria 获取所有条件组
 func (e *Example) GetOredCriteria() []Criteria {
 	return e.oredCriteria
@@ -114,6 +136,9 @@ func (e *Example) Clear() {
 	e.distinct = false
 	e.limitStart = nil
 	e.limitEnd = nil
+	e.seekColumns = nil
+	e.seekValues = nil
+	e.seekDesc = false
 }
 
 // IsValid 检查 Example 是否有效
@@ -134,8 +159,15 @@ func (e *Example) BuildSQL(baseSQL string) (string, []interface{}) {
 	// 构建 WHERE 条件
 	if e.IsValid() {
 		whereClause, whereArgs := e.buildWhereClause()
+		if len(e.seekColumns) > 0 {
+			whereClause = "(" + whereClause + ") AND " + e.buildSeekClause()
+		}
 		sql += " WHERE " + whereClause
 		args = append(args, whereArgs...)
+		args = append(args, e.seekValues...)
+	} else if len(e.seekColumns) > 0 {
+		sql += " WHERE " + e.buildSeekClause()
+		args = append(args, e.seekValues...)
 	}
 
 	// 添加 ORDER BY
@@ -172,6 +204,16 @@ func (e *Example) buildWhereClause() (string, []interface{}) {
 	return strings.Join(clauses, " "), args
 }
 
+// buildSeekClause 构建键集分页条件
+func (e *Example) buildSeekClause() string {
+	operator := ">"
+	if e.seekDesc {
+		operator = "<"
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(e.seekColumns)), ", ")
+	return "(" + strings.Join(e.seekColumns, ", ") + ") " + operator + " (" + placeholders + ")"
+}
+
 // Criteria 方法实现
 
 // IsValid 检查条件组是否有效
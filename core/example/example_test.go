@@ -297,6 +297,27 @@ func TestExample_BuildSQL_NoConditions(t *testing.T) {
 	}
 }
 
+func TestExample_BuildSQL_WithSeek(t *testing.T) {
+	example := NewExample()
+	example.SetSeek([]string{"created_at", "id"}, []interface{}{"2024-01-01", 7}, false)
+	sql, args := example.BuildSQL("SELECT * FROM events")
+
+	expectedSQL := "SELECT * FROM events WHERE (created_at, id) > (?, ?)"
+	if sql != expectedSQL {
+		t.Errorf("Expected SQL: %s, got: %s", expectedSQL, sql)
+	}
+	if len(args) != 2 || args[1] != 7 {
+		t.Errorf("Expected seek args, got: %v", args)
+	}
+
+	// 不安全的列名被忽略
+	example.Clear()
+	example.SetSeek([]string{"id) OR (1=1"}, []interface{}{1}, false)
+	if sql, _ := example.BuildSQL("SELECT * FROM events"); sql != "SELECT * FROM events" {
+		t.Errorf("Expected unsafe seek to be ignored, got: %s", sql)
+	}
+}
+
 func TestCriteria_BuildClause_Multiple(t *testing.T) {
 	example := NewExample()
 	criteria := example.CreateCriteria()
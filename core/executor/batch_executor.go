@@ -0,0 +1,500 @@
+package executor
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gobatis/core/config"
+)
+
+// SuccessNoInfo 语句执行成功但无法得知其影响的行数，如改写为多行 VALUES 的批量插入
+const SuccessNoInfo int64 = -2
+
+// BatchResult 一组连续执行的相同语句的结果
+type BatchResult struct {
+	StatementID  string
+	SQL          string
+	Parameters   []interface{}
+	UpdateCounts []int64 // 与 Parameters 一一对应，无法得知时为 SuccessNoInfo
+}
+
+// BatchStatement 批量语句
+type BatchStatement struct {
+	Statement *config.MapperStatement
+	Parameter interface{}
+	sql       string
+	args      []interface{}
+	bound     bool // 入队时已绑定参数；需要回填主键的语句在执行时再绑定
+}
+
+// boundSQL 语句发送到数据库前交给 StatementHandler 阶段的 BoundSQL
+func (s *BatchStatement) boundSQL() *BoundSQL {
+	return &BoundSQL{SQL: s.sql, Args: s.args, StatementID: s.Statement.ID, StatementType: s.Statement.StatementType}
+}
+
+// BatchExecutor 批量执行器，连续的相同 SQL 共用一条预编译语句，
+// 累计的语句数或字节数达到配置值时自动刷新，所有刷新在同一事务中执行直到提交
+type BatchExecutor struct {
+	baseExecutor
+	statements []*BatchStatement
+	bytes      int
+	tx         *sql.Tx
+	results    []BatchResult
+}
+
+// NewBatchExecutor 创建批量执行器
+func NewBatchExecutor(configuration *config.Configuration) *BatchExecutor {
+	e := &BatchExecutor{
+		baseExecutor: newBaseExecutor(configuration),
+		statements:   make([]*BatchStatement, 0),
+	}
+	e.runner = e.queryRunner
+	return e
+}
+
+// queryRunner 查询在批量事务中执行，以便读取到已刷新的修改
+func (e *BatchExecutor) queryRunner() Runner {
+	if e.tx != nil {
+		return e.tx
+	}
+	return e.configuration.DataSource.DB
+}
+
+// Query 实现 Executor，先执行累计的语句再查询
+func (e *BatchExecutor) Query(statement *config.MapperStatement, parameter interface{}) ([]interface{}, error) {
+	if err := e.flush(); err != nil {
+		return nil, err
+	}
+	return e.baseExecutor.Query(statement, parameter)
+}
+
+// Update 实现 Executor，语句加入批量后返回 0，影响的行数由 FlushStatements 返回
+func (e *BatchExecutor) Update(statement *config.MapperStatement, parameter interface{}) (int64, error) {
+	return 0, e.AddBatch(statement, parameter)
+}
+
+// Upsert 实现 Executor，先执行累计的语句，upsert 本身立即执行
+func (e *BatchExecutor) Upsert(target string, rows interface{}, conflictKeys, updateColumns []string) (int64, error) {
+	if err := e.flush(); err != nil {
+		return 0, err
+	}
+	return e.baseExecutor.Upsert(target, rows, conflictKeys, updateColumns)
+}
+
+// AddBatch 添加批量语句，达到刷新阈值时立即执行已累计的语句
+func (e *BatchExecutor) AddBatch(statement *config.MapperStatement, parameter interface{}) error {
+	e.ClearLocalCache()
+	if err := GenerateKeys(e.configuration, statement, parameter); err != nil {
+		return err
+	}
+
+	batchStmt := &BatchStatement{Statement: statement, Parameter: parameter}
+	size := len(statement.SQL)
+	if !executesAlone(statement) {
+		bound, err := e.bindParameters(statement, parameter)
+		if err != nil {
+			return fmt.Errorf("failed to bind parameters: %w", err)
+		}
+		batchStmt.sql, batchStmt.args, batchStmt.bound = bound.SQL, bound.Args, true
+		size = statementSize(bound.SQL, bound.Args)
+	}
+
+	e.statements = append(e.statements, batchStmt)
+	e.bytes += size
+
+	if e.shouldFlush() {
+		return e.flush()
+	}
+	return nil
+}
+
+// FlushStatements 实现 Executor，执行剩余的批量语句但不提交，
+// 返回自上次调用以来（含自动刷新）所有语句的结果
+func (e *BatchExecutor) FlushStatements() ([]BatchResult, error) {
+	if err := e.flush(); err != nil {
+		return nil, err
+	}
+	results := e.results
+	e.results = nil
+	return results, nil
+}
+
+// Commit 实现 Executor，执行剩余的批量语句并提交批量事务
+func (e *BatchExecutor) Commit() error {
+	e.ClearLocalCache()
+	if err := e.flush(); err != nil {
+		return err
+	}
+	if e.tx == nil {
+		return nil
+	}
+
+	err := e.tx.Commit()
+	e.tx = nil
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// inTransaction 实现 transactionHolder，累计的语句在提交批量事务前不会生效
+func (e *BatchExecutor) inTransaction() bool {
+	return e.tx != nil || len(e.statements) > 0
+}
+
+// Rollback 实现 Executor，丢弃未执行的语句并回滚批量事务
+func (e *BatchExecutor) Rollback() error {
+	return e.abort()
+}
+
+// ExecuteBatch 执行剩余的批量语句并提交事务，返回自上次提交以来所有语句的结果
+func (e *BatchExecutor) ExecuteBatch() ([]BatchResult, error) {
+	results, err := e.FlushStatements()
+	if err != nil {
+		return nil, err
+	}
+	if err := e.Commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Close 实现 Executor，丢弃未执行的语句并回滚未提交的批量事务
+func (e *BatchExecutor) Close() error {
+	return e.abort()
+}
+
+// shouldFlush 累计的语句数或字节数是否达到刷新阈值
+func (e *BatchExecutor) shouldFlush() bool {
+	c := e.configuration
+	return (c.BatchFlushStatements > 0 && len(e.statements) >= c.BatchFlushStatements) ||
+		(c.BatchFlushBytes > 0 && e.bytes >= c.BatchFlushBytes)
+}
+
+// flush 在批量事务中执行已累计的语句，结果保留到 FlushStatements 时返回，出错时回滚整个批量
+func (e *BatchExecutor) flush() error {
+	if len(e.statements) == 0 {
+		return nil
+	}
+
+	// 开始事务
+	if e.tx == nil {
+		tx, err := e.configuration.DataSource.DB.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		e.tx = tx
+	}
+
+	statements := e.statements
+	for i := 0; i < len(statements); {
+		// 连续的同一语句且绑定后 SQL 相同的归为一组
+		j := i + 1
+		if statements[i].bound {
+			for j < len(statements) && statements[j].bound &&
+				statements[j].Statement == statements[i].Statement && statements[j].sql == statements[i].sql {
+				j++
+			}
+		}
+
+		result, err := e.executeGroup(statements[i:j])
+		if err != nil {
+			e.abort()
+			return err
+		}
+		e.results = append(e.results, result)
+		i = j
+	}
+
+	// 清空批量语句
+	e.reset()
+	return nil
+}
+
+// abort 回滚批量事务并丢弃所有语句、结果与一级缓存
+func (e *BatchExecutor) abort() error {
+	e.ClearLocalCache()
+	e.reset()
+	e.results = nil
+	if e.tx == nil {
+		return nil
+	}
+	err := e.tx.Rollback()
+	e.tx = nil
+	return err
+}
+
+// reset 清空累计的语句
+func (e *BatchExecutor) reset() {
+	for i := range e.statements {
+		e.statements[i] = nil
+	}
+	e.statements = e.statements[:0]
+	e.bytes = 0
+}
+
+// executeGroup 执行一组语句
+func (e *BatchExecutor) executeGroup(group []*BatchStatement) (BatchResult, error) {
+	first := group[0]
+	result := BatchResult{
+		StatementID: first.Statement.ID,
+		SQL:         first.sql,
+		Parameters:  make([]interface{}, len(group)),
+	}
+	for i, batchStmt := range group {
+		result.Parameters[i] = batchStmt.Parameter
+	}
+
+	var err error
+	switch {
+	case !first.bound:
+		var affected int64
+		result.SQL, affected, err = e.executeAlone(first)
+		result.UpdateCounts = []int64{affected}
+	case len(group) == 1:
+		var res sql.Result
+		runner := e.statementHandler(e.tx, first.Statement, first.Parameter, first.boundSQL())
+		if res, err = runner.Exec(first.sql, first.args...); err == nil {
+			var affected int64
+			affected, err = res.RowsAffected()
+			result.UpdateCounts = []int64{affected}
+		}
+	default:
+		if prefix, tuple, ok := e.multiRowInsert(group); ok {
+			result.UpdateCounts, err = e.executeMultiRow(group, prefix, tuple)
+		} else {
+			result.UpdateCounts, err = e.executePrepared(group)
+		}
+	}
+	if err != nil {
+		return BatchResult{}, fmt.Errorf("failed to execute batch statement %s: %w", first.Statement.ID, err)
+	}
+
+	return result, nil
+}
+
+// executeAlone 单独执行需要回填主键的语句，返回绑定后的 SQL 与影响的行数
+func (e *BatchExecutor) executeAlone(batchStmt *BatchStatement) (string, int64, error) {
+	statement, parameter := batchStmt.Statement, batchStmt.Parameter
+	if err := ProcessSelectKey(e.tx, e.parameterBinder, statement, parameter, config.SelectKeyBefore); err != nil {
+		return "", 0, err
+	}
+
+	bound, err := e.bindParameters(statement, parameter)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to bind parameters: %w", err)
+	}
+	processedSQL, args := bound.SQL, bound.Args
+
+	affected, err := ExecUpdate(e.statementHandler(e.tx, statement, parameter, bound), e.configuration, statement, processedSQL, args, parameter)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := ProcessSelectKey(e.tx, e.parameterBinder, statement, parameter, config.SelectKeyAfter); err != nil {
+		return "", 0, err
+	}
+	return processedSQL, affected, nil
+}
+
+// executePrepared 预编译一次后依次执行组内语句，
+// 有插件拦截 StatementHandler.Update 时插件可能改写每条 SQL，逐条经插件执行
+func (e *BatchExecutor) executePrepared(group []*BatchStatement) ([]int64, error) {
+	if e.intercepts(TargetStatementHandler, MethodUpdate, group[0].Statement) {
+		counts := make([]int64, 0, len(group))
+		for _, batchStmt := range group {
+			runner := e.statementHandler(e.tx, batchStmt.Statement, batchStmt.Parameter, batchStmt.boundSQL())
+			res, err := runner.Exec(batchStmt.sql, batchStmt.args...)
+			if err != nil {
+				return nil, err
+			}
+			affected, err := res.RowsAffected()
+			if err != nil {
+				return nil, err
+			}
+			counts = append(counts, affected)
+		}
+		return counts, nil
+	}
+
+	stmt, err := e.tx.Prepare(group[0].sql)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	counts := make([]int64, 0, len(group))
+	for _, batchStmt := range group {
+		res, err := stmt.Exec(batchStmt.args...)
+		if err != nil {
+			return nil, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, affected)
+	}
+	return counts, nil
+}
+
+// executeMultiRow 将组内的单行 INSERT 合并为多行 VALUES 执行，按参数个数上限分块
+func (e *BatchExecutor) executeMultiRow(group []*BatchStatement, prefix, tuple string) ([]int64, error) {
+	rowsPerStatement := len(group)
+	maxBindParameters := e.configuration.Dialect.MaxBindParameters()
+	if n := len(group[0].args); n > 0 && maxBindParameters/n < rowsPerStatement {
+		rowsPerStatement = maxBindParameters / n
+	}
+	if rowsPerStatement < 1 {
+		return nil, fmt.Errorf("%d parameters per row exceed the bind parameter limit of %s", len(group[0].args), e.configuration.Dialect.Name())
+	}
+
+	counts := make([]int64, 0, len(group))
+	for start := 0; start < len(group); start += rowsPerStatement {
+		chunk := group[start:min(start+rowsPerStatement, len(group))]
+
+		var query strings.Builder
+		query.WriteString(prefix)
+		args := make([]interface{}, 0, len(chunk)*len(chunk[0].args))
+		parameters := make([]interface{}, len(chunk))
+		for i, batchStmt := range chunk {
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteString(tuple)
+			args = append(args, batchStmt.args...)
+			parameters[i] = batchStmt.Parameter
+		}
+
+		// 合并后的语句作为一次 StatementHandler.Update 执行，参数为各行参数组成的切片
+		statement := chunk[0].Statement
+		bound := &BoundSQL{StatementID: statement.ID, StatementType: statement.StatementType}
+		res, err := e.statementHandler(e.tx, statement, parameters, bound).Exec(query.String(), args...)
+		if err != nil {
+			return nil, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+
+		// 多行插入只返回总行数，行数一致时每条记为 1
+		count := SuccessNoInfo
+		if affected == int64(len(chunk)) {
+			count = 1
+		}
+		for range chunk {
+			counts = append(counts, count)
+		}
+	}
+	return counts, nil
+}
+
+// multiRowInsert 判断组内语句能否改写为多行 VALUES，返回 VALUES 元组前的部分与元组本身
+func (e *BatchExecutor) multiRowInsert(group []*BatchStatement) (string, string, bool) {
+	first := group[0]
+	d := e.configuration.Dialect
+	if !e.configuration.BatchRewriteInserts || d == nil || !d.SupportsMultiRowInsert() ||
+		first.Statement.StatementType != config.INSERT {
+		return "", "", false
+	}
+
+	prefix, tuple, ok := splitInsertValues(first.sql)
+	if !ok || countPlaceholders(prefix) > 0 || countPlaceholders(tuple) != len(first.args) {
+		return "", "", false
+	}
+	return prefix, tuple, true
+}
+
+// executesAlone 需要回填主键的语句无法合并执行
+func executesAlone(statement *config.MapperStatement) bool {
+	return statement.SelectKey != nil || (statement.UseGeneratedKeys && statement.StatementType == config.INSERT)
+}
+
+// statementSize 估算语句占用的字节数
+func statementSize(query string, args []interface{}) int {
+	size := len(query)
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			size += len(v)
+		case []byte:
+			size += len(v)
+		default:
+			size += 8
+		}
+	}
+	return size
+}
+
+var valuesPattern = regexp.MustCompile(`(?i)\bVALUES\s*\(`)
+
+// splitInsertValues 拆分单行 INSERT ... VALUES (...)，
+// 元组之后还有其他子句（如 ON DUPLICATE KEY UPDATE、RETURNING）时不拆分
+func splitInsertValues(query string) (string, string, bool) {
+	trimmed := strings.TrimSpace(query)
+	if len(trimmed) < 6 || !strings.EqualFold(trimmed[:6], "INSERT") {
+		return "", "", false
+	}
+
+	loc := valuesPattern.FindStringIndex(trimmed)
+	if loc == nil {
+		return "", "", false
+	}
+	start := loc[1] - 1
+	end := closingParen(trimmed, start)
+	if end < 0 {
+		return "", "", false
+	}
+	if rest := strings.TrimSpace(trimmed[end+1:]); rest != "" && rest != ";" {
+		return "", "", false
+	}
+	return trimmed[:start], trimmed[start : end+1], true
+}
+
+// closingParen 返回与 start 处左括号匹配的右括号位置，跳过引号内的内容
+func closingParen(s string, start int) int {
+	depth := 0
+	var quote byte
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// countPlaceholders 统计引号外的 ? 占位符个数
+func countPlaceholders(s string) int {
+	count := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			count++
+		}
+	}
+	return count
+}
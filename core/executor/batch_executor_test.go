@@ -0,0 +1,506 @@
+package executor
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"gobatis/core/config"
+	"gobatis/dialect"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestNewBatchExecutor 测试创建批量执行器
+func TestNewBatchExecutor(t *testing.T) {
+	configuration := &config.Configuration{}
+	executor := NewBatchExecutor(configuration)
+
+	if executor == nil {
+		t.Fatal("BatchExecutor should not be nil")
+	}
+
+	if executor.configuration != configuration {
+		t.Fatal("Configuration should be the same instance")
+	}
+
+	if executor.parameterBinder == nil {
+		t.Fatal("ParameterBinder should not be nil")
+	}
+
+	if executor.statements == nil {
+		t.Fatal("Statements should not be nil")
+	}
+
+	if len(executor.statements) != 0 {
+		t.Fatal("Statements should be empty initially")
+	}
+}
+
+// TestBatchExecutor_AddBatch 测试添加批量语句
+func TestBatchExecutor_AddBatch(t *testing.T) {
+	configuration := &config.Configuration{}
+	executor := NewBatchExecutor(configuration)
+
+	statement := &config.MapperStatement{
+		ID:            "TestMapper.InsertUser",
+		SQL:           "INSERT INTO users (username) VALUES (#{username})",
+		StatementType: config.INSERT,
+	}
+
+	parameter := map[string]interface{}{"username": "john"}
+
+	executor.AddBatch(statement, parameter)
+
+	if len(executor.statements) != 1 {
+		t.Fatalf("Expected 1 statement, got %d", len(executor.statements))
+	}
+
+	batchStmt := executor.statements[0]
+	if batchStmt.Statement != statement {
+		t.Fatal("Statement should be the same instance")
+	}
+
+	// 不能直接比较map，只检查类型
+	if batchStmt.Parameter == nil {
+		t.Fatal("Parameter should not be nil")
+	}
+}
+
+// TestBatchExecutor_ExecuteBatch_Empty 测试执行空批量
+func TestBatchExecutor_ExecuteBatch_Empty(t *testing.T) {
+	configuration := &config.Configuration{}
+	executor := NewBatchExecutor(configuration)
+
+	results, err := executor.ExecuteBatch()
+	if err != nil {
+		t.Fatalf("ExecuteBatch failed: %v", err)
+	}
+
+	if results != nil {
+		t.Fatal("Results should be nil for empty batch")
+	}
+}
+
+// TestBatchExecutor_ExecuteBatch_Success 测试批量执行成功
+func TestBatchExecutor_ExecuteBatch_Success(t *testing.T) {
+	// 创建模拟数据库
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	// 创建配置
+	configuration := &config.Configuration{
+		DataSource: &config.DataSource{
+			DB: db,
+		},
+	}
+
+	// 创建执行器
+	executor := NewBatchExecutor(configuration)
+
+	// 创建语句
+	insertStmt := &config.MapperStatement{
+		ID:            "TestMapper.InsertUser",
+		SQL:           "INSERT INTO users (username) VALUES (#{username})",
+		StatementType: config.INSERT,
+	}
+
+	updateStmt := &config.MapperStatement{
+		ID:            "TestMapper.UpdateUser",
+		SQL:           "UPDATE users SET username = #{username} WHERE id = #{id}",
+		StatementType: config.UPDATE,
+	}
+
+	// 添加批量语句
+	executor.AddBatch(insertStmt, map[string]interface{}{"username": "john"})
+	executor.AddBatch(updateStmt, map[string]interface{}{"username": "jane", "id": 1})
+
+	// 设置模拟期望
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO users \\(username\\) VALUES \\(\\?\\)").
+		WithArgs("john").
+		WillReturnResult(sqlmock.NewResult(123, 1))
+	mock.ExpectExec("UPDATE users SET username = \\? WHERE id = \\?").
+		WithArgs("jane", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// 执行批量操作
+	results, err := executor.ExecuteBatch()
+	if err != nil {
+		t.Fatalf("ExecuteBatch failed: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	if results[0].StatementID != insertStmt.ID || results[0].UpdateCounts[0] != 1 {
+		t.Fatalf("Unexpected insert result: %+v", results[0])
+	}
+
+	if results[1].StatementID != updateStmt.ID || results[1].UpdateCounts[0] != 1 {
+		t.Fatalf("Unexpected update result: %+v", results[1])
+	}
+
+	// 验证批量语句已清空
+	if len(executor.statements) != 0 {
+		t.Fatal("Statements should be cleared after execution")
+	}
+
+	// 验证模拟期望
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Mock expectations were not met: %v", err)
+	}
+}
+
+// TestBatchStatement 测试BatchStatement结构
+func TestBatchStatement(t *testing.T) {
+	statement := &config.MapperStatement{
+		ID:            "TestMapper.InsertUser",
+		SQL:           "INSERT INTO users (username) VALUES (#{username})",
+		StatementType: config.INSERT,
+	}
+
+	parameter := map[string]interface{}{"username": "john"}
+
+	batchStmt := &BatchStatement{
+		Statement: statement,
+		Parameter: parameter,
+	}
+
+	if batchStmt.Statement != statement {
+		t.Fatal("Statement should be the same instance")
+	}
+
+	if batchStmt.Parameter == nil {
+		t.Fatal("Parameter should not be nil")
+	}
+}
+
+// newBatchTestExecutor 创建基于模拟数据库的批量执行器
+func newBatchTestExecutor(t *testing.T) (*BatchExecutor, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	configuration := &config.Configuration{
+		DataSource: &config.DataSource{DB: db},
+	}
+	return NewBatchExecutor(configuration), mock
+}
+
+// TestBatchExecutor_GroupsIdenticalSQL 测试连续相同的 SQL 共用一条预编译语句
+func TestBatchExecutor_GroupsIdenticalSQL(t *testing.T) {
+	executor, mock := newBatchTestExecutor(t)
+
+	insertStmt := &config.MapperStatement{
+		ID:            "TestMapper.InsertUser",
+		SQL:           "INSERT INTO users (username) VALUES (#{username})",
+		StatementType: config.INSERT,
+	}
+	deleteStmt := &config.MapperStatement{
+		ID:            "TestMapper.DeleteUser",
+		SQL:           "DELETE FROM users WHERE id = #{id}",
+		StatementType: config.DELETE,
+	}
+
+	for _, name := range []string{"a", "b", "c"} {
+		if err := executor.AddBatch(insertStmt, map[string]interface{}{"username": name}); err != nil {
+			t.Fatalf("AddBatch failed: %v", err)
+		}
+	}
+	if err := executor.AddBatch(deleteStmt, map[string]interface{}{"id": 9}); err != nil {
+		t.Fatalf("AddBatch failed: %v", err)
+	}
+
+	mock.ExpectBegin()
+	prepared := mock.ExpectPrepare("INSERT INTO users \\(username\\) VALUES \\(\\?\\)")
+	prepared.ExpectExec().WithArgs("a").WillReturnResult(sqlmock.NewResult(0, 1))
+	prepared.ExpectExec().WithArgs("b").WillReturnResult(sqlmock.NewResult(0, 1))
+	prepared.ExpectExec().WithArgs("c").WillReturnResult(sqlmock.NewResult(0, 1))
+	prepared.WillBeClosed()
+	mock.ExpectExec("DELETE FROM users WHERE id = \\?").WithArgs(9).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	results, err := executor.ExecuteBatch()
+	if err != nil {
+		t.Fatalf("ExecuteBatch failed: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 grouped results, got %d", len(results))
+	}
+	if len(results[0].Parameters) != 3 || len(results[0].UpdateCounts) != 3 || results[0].UpdateCounts[2] != 1 {
+		t.Fatalf("Unexpected insert group: %+v", results[0])
+	}
+	if results[0].SQL != "INSERT INTO users (username) VALUES (?)" {
+		t.Fatalf("Unexpected SQL: %s", results[0].SQL)
+	}
+	if results[1].StatementID != deleteStmt.ID || results[1].UpdateCounts[0] != 0 {
+		t.Fatalf("Unexpected delete result: %+v", results[1])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Mock expectations were not met: %v", err)
+	}
+}
+
+// TestBatchExecutor_AutoFlush 测试达到语句数阈值时自动刷新，结果在提交时一并返回
+func TestBatchExecutor_AutoFlush(t *testing.T) {
+	executor, mock := newBatchTestExecutor(t)
+	executor.configuration.BatchFlushStatements = 2
+
+	statement := &config.MapperStatement{
+		ID:            "TestMapper.UpdateUser",
+		SQL:           "UPDATE users SET username = #{username} WHERE id = #{id}",
+		StatementType: config.UPDATE,
+	}
+
+	mock.ExpectBegin()
+	prepared := mock.ExpectPrepare("UPDATE users")
+	prepared.ExpectExec().WithArgs("u1", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	prepared.ExpectExec().WithArgs("u2", 2).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	for id := 1; id <= 3; id++ {
+		parameter := map[string]interface{}{"username": fmt.Sprintf("u%d", id), "id": id}
+		if err := executor.AddBatch(statement, parameter); err != nil {
+			t.Fatalf("AddBatch failed: %v", err)
+		}
+	}
+	if len(executor.statements) != 1 {
+		t.Fatalf("Expected 1 pending statement after auto flush, got %d", len(executor.statements))
+	}
+
+	mock.ExpectExec("UPDATE users").WithArgs("u3", 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	results, err := executor.ExecuteBatch()
+	if err != nil {
+		t.Fatalf("ExecuteBatch failed: %v", err)
+	}
+	if len(results) != 2 || len(results[0].UpdateCounts) != 2 || len(results[1].UpdateCounts) != 1 {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Mock expectations were not met: %v", err)
+	}
+}
+
+// TestBatchExecutor_Rollback 测试执行失败时回滚整个批量
+func TestBatchExecutor_Rollback(t *testing.T) {
+	executor, mock := newBatchTestExecutor(t)
+
+	statement := &config.MapperStatement{
+		ID:            "TestMapper.DeleteUser",
+		SQL:           "DELETE FROM users WHERE id = #{id}",
+		StatementType: config.DELETE,
+	}
+	executor.AddBatch(statement, map[string]interface{}{"id": 1})
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM users").WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectRollback()
+
+	if _, err := executor.ExecuteBatch(); err == nil {
+		t.Fatal("Expected error from failed batch")
+	}
+	if executor.tx != nil || len(executor.statements) != 0 || executor.results != nil {
+		t.Fatal("Batch state should be cleared after rollback")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Mock expectations were not met: %v", err)
+	}
+}
+
+// TestBatchExecutor_RewriteInserts 测试将同一 INSERT 改写为多行 VALUES
+func TestBatchExecutor_RewriteInserts(t *testing.T) {
+	executor, mock := newBatchTestExecutor(t)
+	executor.configuration.Dialect = dialect.MySQLDialect{}
+	executor.configuration.BatchRewriteInserts = true
+
+	statement := &config.MapperStatement{
+		ID:            "TestMapper.InsertUser",
+		SQL:           "INSERT INTO users (id, username) VALUES (#{id}, #{username})",
+		StatementType: config.INSERT,
+	}
+	for id := 1; id <= 3; id++ {
+		executor.AddBatch(statement, TestUser{ID: id, Username: "user"})
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO users \\(id, username\\) VALUES \\(\\?, \\?\\), \\(\\?, \\?\\), \\(\\?, \\?\\)$").
+		WithArgs(1, "user", 2, "user", 3, "user").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	results, err := executor.ExecuteBatch()
+	if err != nil {
+		t.Fatalf("ExecuteBatch failed: %v", err)
+	}
+	if len(results) != 1 || len(results[0].UpdateCounts) != 3 || results[0].UpdateCounts[0] != 1 {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Mock expectations were not met: %v", err)
+	}
+}
+
+// TestBatchExecutor_RewriteInserts_TooManyParameters 测试单行参数数超出方言上限时返回错误而不是无限循环
+func TestBatchExecutor_RewriteInserts_TooManyParameters(t *testing.T) {
+	executor, mock := newBatchTestExecutor(t)
+	executor.configuration.Dialect = tinyBindLimitDialect{}
+	executor.configuration.BatchRewriteInserts = true
+
+	statement := &config.MapperStatement{
+		ID:            "TestMapper.InsertUser",
+		SQL:           "INSERT INTO users (id, username) VALUES (#{id}, #{username})",
+		StatementType: config.INSERT,
+	}
+	for id := 1; id <= 2; id++ {
+		executor.AddBatch(statement, TestUser{ID: id, Username: "user"})
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	if _, err := executor.ExecuteBatch(); err == nil {
+		t.Fatal("Expected error when a row exceeds the bind parameter limit")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Mock expectations were not met: %v", err)
+	}
+}
+
+// tinyBindLimitDialect 每条语句只允许一个参数的 MySQL 方言
+type tinyBindLimitDialect struct {
+	dialect.MySQLDialect
+}
+
+func (tinyBindLimitDialect) MaxBindParameters() int { return 1 }
+
+// batchStageInterceptor 在 ParameterHandler 阶段为 UPDATE 追加租户条件，
+// 在 StatementHandler.Update 阶段为 SQL 追加注释，并记录经过的阶段
+type batchStageInterceptor struct {
+	stages []string
+}
+
+func (i *batchStageInterceptor) Intercepts(target, method string, statement *config.MapperStatement) bool {
+	return target == TargetParameterHandler || (target == TargetStatementHandler && method == MethodUpdate)
+}
+
+func (i *batchStageInterceptor) InterceptStage(stage *Stage, proceed func() (interface{}, error)) (interface{}, error) {
+	i.stages = append(i.stages, stage.Target+"."+stage.Method+":"+stage.Statement.ID)
+	if stage.Target == TargetStatementHandler {
+		stage.BoundSQL.SQL += " /* batch */"
+		return proceed()
+	}
+
+	result, err := proceed()
+	if err != nil {
+		return nil, err
+	}
+	if stage.Statement.StatementType == config.UPDATE {
+		stage.BoundSQL.SQL += " AND tenant_id = ?"
+		stage.BoundSQL.Args = append(stage.BoundSQL.Args, "t1")
+	}
+	return result, nil
+}
+
+// TestBatchExecutor_Interceptor 测试批量执行的语句同样经过 ParameterHandler 与 StatementHandler 阶段，
+// 包括单独执行的回填主键语句与按组执行的语句
+func TestBatchExecutor_Interceptor(t *testing.T) {
+	executor, mock := newBatchTestExecutor(t)
+	interceptor := &batchStageInterceptor{}
+	executor.setInterceptor(interceptor)
+
+	insertStmt := &config.MapperStatement{
+		ID:               "TestMapper.InsertUser",
+		SQL:              "INSERT INTO users (username) VALUES (#{username})",
+		StatementType:    config.INSERT,
+		UseGeneratedKeys: true,
+		KeyProperty:      "ID",
+	}
+	updateStmt := &config.MapperStatement{
+		ID:            "TestMapper.UpdateUser",
+		SQL:           "UPDATE users SET username = #{username} WHERE id = #{id}",
+		StatementType: config.UPDATE,
+	}
+
+	user := &TestUser{Username: "john"}
+	if err := executor.AddBatch(insertStmt, user); err != nil {
+		t.Fatalf("AddBatch failed: %v", err)
+	}
+	for id := 1; id <= 2; id++ {
+		if err := executor.AddBatch(updateStmt, TestUser{ID: id, Username: "jane"}); err != nil {
+			t.Fatalf("AddBatch failed: %v", err)
+		}
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users (username) VALUES (?) /* batch */")).WithArgs("john").
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	for id := 1; id <= 2; id++ {
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET username = ? WHERE id = ? AND tenant_id = ? /* batch */")).
+			WithArgs("jane", id, "t1").WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	results, err := executor.ExecuteBatch()
+	if err != nil {
+		t.Fatalf("ExecuteBatch failed: %v", err)
+	}
+	if len(results) != 2 || len(results[1].UpdateCounts) != 2 || user.ID != 7 {
+		t.Fatalf("Unexpected results %+v for user %+v", results, user)
+	}
+
+	expected := []string{
+		"ParameterHandler.SetParameters:TestMapper.UpdateUser",
+		"ParameterHandler.SetParameters:TestMapper.UpdateUser",
+		"ParameterHandler.SetParameters:TestMapper.InsertUser",
+		"StatementHandler.Update:TestMapper.InsertUser",
+		"StatementHandler.Update:TestMapper.UpdateUser",
+		"StatementHandler.Update:TestMapper.UpdateUser",
+	}
+	if !reflect.DeepEqual(interceptor.stages, expected) {
+		t.Errorf("Unexpected stages:\n got: %v\nwant: %v", interceptor.stages, expected)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Mock expectations were not met: %v", err)
+	}
+}
+
+// TestSplitInsertValues 测试拆分单行 INSERT 的 VALUES 元组
+func TestSplitInsertValues(t *testing.T) {
+	testCases := []struct {
+		query  string
+		prefix string
+		tuple  string
+		ok     bool
+	}{
+		{"INSERT INTO t (a, b) VALUES (?, ?)", "INSERT INTO t (a, b) VALUES ", "(?, ?)", true},
+		{"insert into t(a) values(lower(?));", "insert into t(a) values", "(lower(?))", true},
+		{"INSERT INTO t (a) VALUES (')') ", "INSERT INTO t (a) VALUES ", "(')')", true},
+		{"INSERT INTO t (a) VALUES (?) ON DUPLICATE KEY UPDATE a = VALUES(a)", "", "", false},
+		{"INSERT INTO t (a) VALUES (?) RETURNING id", "", "", false},
+		{"INSERT INTO t (a) SELECT a FROM s", "", "", false},
+		{"UPDATE t SET a = ?", "", "", false},
+	}
+
+	for _, tc := range testCases {
+		prefix, tuple, ok := splitInsertValues(tc.query)
+		if ok != tc.ok || prefix != tc.prefix || tuple != tc.tuple {
+			t.Errorf("splitInsertValues(%q) = (%q, %q, %v), expected (%q, %q, %v)",
+				tc.query, prefix, tuple, ok, tc.prefix, tc.tuple, tc.ok)
+		}
+	}
+}
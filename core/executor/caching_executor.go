@@ -0,0 +1,262 @@
+package executor
+
+import (
+	"fmt"
+	"regexp"
+
+	"gobatis/binding"
+	"gobatis/cache"
+	"gobatis/core/config"
+)
+
+// transactionalCache 暂存事务内的缓存写入，提交时才发布到命名空间缓存
+type transactionalCache struct {
+	delegate      cache.Cache
+	clearOnCommit bool
+	entriesToAdd  map[string][]interface{}
+}
+
+// get 事务内已清空命名空间时视为未命中，避免读到其他会话发布的旧数据
+func (c *transactionalCache) get(key string) ([]interface{}, bool) {
+	if c.clearOnCommit {
+		return nil, false
+	}
+	value, exists := c.delegate.Get(key)
+	if !exists {
+		return nil, false
+	}
+	results, ok := value.([]interface{})
+	return results, ok
+}
+
+func (c *transactionalCache) commit() {
+	if c.clearOnCommit {
+		c.delegate.Clear()
+	}
+	for key, results := range c.entriesToAdd {
+		c.delegate.Put(key, results)
+	}
+	c.reset()
+}
+
+func (c *transactionalCache) reset() {
+	c.clearOnCommit = false
+	clear(c.entriesToAdd)
+}
+
+// queryCache 执行器内部查找二级缓存的位置，键由 ParameterHandler 阶段之后的 BoundSQL 生成，
+// 插件追加的条件与参数因此包含在键中
+type queryCache interface {
+	getCached(statement *config.MapperStatement, key string) ([]interface{}, bool)
+	putCached(statement *config.MapperStatement, key string, results []interface{})
+}
+
+// queryCacheHolder 可设置二级缓存查找点的执行器
+type queryCacheHolder interface {
+	setQueryCache(cache queryCache)
+}
+
+// transactionHolder 可报告是否持有未提交事务的执行器
+type transactionHolder interface {
+	inTransaction() bool
+}
+
+// CachingExecutor 命名空间二级缓存装饰器，查询结果在提交后才对其他会话可见；
+// 命名空间内的写语句在事务中执行时于提交时清空该命名空间的缓存，不在事务中执行时立即清空
+type CachingExecutor struct {
+	delegate        Executor
+	configuration   *config.Configuration
+	parameterBinder binding.ParameterBinder
+	caches          map[cache.Cache]*transactionalCache
+	dirty           bool // 自上次提交或回滚后执行过写语句
+	internal        bool // 被装饰的执行器在绑定参数后查找二级缓存
+}
+
+// NewCachingExecutor 为执行器增加二级缓存
+func NewCachingExecutor(configuration *config.Configuration, delegate Executor) *CachingExecutor {
+	e := &CachingExecutor{
+		delegate:        delegate,
+		configuration:   configuration,
+		parameterBinder: configuration.NewParameterBinder(),
+		caches:          make(map[cache.Cache]*transactionalCache),
+	}
+	if holder, ok := delegate.(queryCacheHolder); ok {
+		holder.setQueryCache(e)
+		e.internal = true
+	}
+	return e
+}
+
+// Delegate 返回被装饰的执行器
+func (e *CachingExecutor) Delegate() Executor {
+	return e.delegate
+}
+
+// transactional 获取命名空间缓存在当前事务中的视图
+func (e *CachingExecutor) transactional(c cache.Cache) *transactionalCache {
+	tc, exists := e.caches[c]
+	if !exists {
+		tc = &transactionalCache{delegate: c, entriesToAdd: make(map[string][]interface{})}
+		e.caches[c] = tc
+	}
+	return tc
+}
+
+// Query 实现 Executor，命中二级缓存时不再访问数据库
+func (e *CachingExecutor) Query(statement *config.MapperStatement, parameter interface{}) ([]interface{}, error) {
+	if statement.Cache == nil {
+		return e.delegate.Query(statement, parameter)
+	}
+
+	tc := e.transactional(statement.Cache)
+	if statement.FlushCache {
+		tc.clearOnCommit = true
+		clear(tc.entriesToAdd)
+	}
+	defer e.clearIfNotInTransaction()
+	if !statement.UseCache || e.internal {
+		return e.delegate.Query(statement, parameter)
+	}
+
+	// 被装饰的执行器不支持内部查找时，以未经插件处理的 SQL 为键
+	processedSQL, args, err := bindStatement(e.parameterBinder, statement, parameter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind parameters: %w", err)
+	}
+	key := CacheKey(statement.ID, processedSQL, args)
+	if cached, exists := e.getCached(statement, key); exists {
+		return cached, nil
+	}
+
+	results, err := e.delegate.Query(statement, parameter)
+	if err != nil {
+		return nil, err
+	}
+	e.putCached(statement, key, results)
+	return results, nil
+}
+
+// getCached 实现 queryCache
+func (e *CachingExecutor) getCached(statement *config.MapperStatement, key string) ([]interface{}, bool) {
+	cached, exists := e.transactional(statement.Cache).get(key)
+	if !exists {
+		return nil, false
+	}
+	return append([]interface{}(nil), cached...), true
+}
+
+// putCached 实现 queryCache，结果暂存到提交时发布
+func (e *CachingExecutor) putCached(statement *config.MapperStatement, key string, results []interface{}) {
+	e.transactional(statement.Cache).entriesToAdd[key] = append([]interface{}(nil), results...)
+}
+
+// Update 实现 Executor，flushCache 的写语句清空所在命名空间的缓存
+func (e *CachingExecutor) Update(statement *config.MapperStatement, parameter interface{}) (int64, error) {
+	e.flushCacheIfRequired(statement)
+	defer e.clearIfNotInTransaction()
+	return e.delegate.Update(statement, parameter)
+}
+
+// Upsert 实现 Executor，target 为语句 ID 时清空其命名空间的缓存，
+// 为表名时清空 SQL 引用该表的语句所在命名空间的缓存
+func (e *CachingExecutor) Upsert(target string, rows interface{}, conflictKeys, updateColumns []string) (int64, error) {
+	if statement, exists := e.configuration.GetMapperStatement(target); exists {
+		e.flushCacheIfRequired(statement)
+	} else {
+		e.flushTableCaches(target)
+	}
+	defer e.clearIfNotInTransaction()
+	return e.delegate.Upsert(target, rows, conflictKeys, updateColumns)
+}
+
+func (e *CachingExecutor) flushCacheIfRequired(statement *config.MapperStatement) {
+	e.dirty = true
+	if statement.Cache != nil && statement.FlushCache {
+		tc := e.transactional(statement.Cache)
+		tc.clearOnCommit = true
+		clear(tc.entriesToAdd)
+	}
+}
+
+// flushTableCaches 按表名查找缓存其数据的命名空间，表名匹配不区分大小写
+func (e *CachingExecutor) flushTableCaches(table string) {
+	e.dirty = true
+	pattern := regexp.MustCompile(`(?i)(^|\W)` + regexp.QuoteMeta(table) + `(\W|$)`)
+	for _, statement := range e.configuration.MapperConfig.Mappers {
+		if statement.Cache == nil || !pattern.MatchString(statement.SQL) {
+			continue
+		}
+		tc := e.transactional(statement.Cache)
+		tc.clearOnCommit = true
+		clear(tc.entriesToAdd)
+	}
+}
+
+// inTransaction 被装饰的执行器是否持有未提交的事务，否则写语句执行后即已生效
+func (e *CachingExecutor) inTransaction() bool {
+	holder, ok := e.delegate.(transactionHolder)
+	return ok && holder.inTransaction()
+}
+
+// clearIfNotInTransaction 写语句不在事务中执行时立即清空命名空间缓存，
+// 避免会话未提交即关闭或回滚时丢弃失效标记，使其他会话持续读到旧数据
+func (e *CachingExecutor) clearIfNotInTransaction() {
+	if e.inTransaction() {
+		return
+	}
+	for _, tc := range e.caches {
+		if tc.clearOnCommit {
+			tc.delegate.Clear()
+			tc.clearOnCommit = false
+		}
+	}
+}
+
+// FlushStatements 实现 Executor
+func (e *CachingExecutor) FlushStatements() ([]BatchResult, error) {
+	return e.delegate.FlushStatements()
+}
+
+// Commit 实现 Executor，事务提交成功后发布暂存的缓存项
+func (e *CachingExecutor) Commit() error {
+	if err := e.delegate.Commit(); err != nil {
+		return err
+	}
+	e.commitCaches()
+	return nil
+}
+
+// Rollback 实现 Executor，丢弃暂存的缓存项
+func (e *CachingExecutor) Rollback() error {
+	e.rollbackCaches()
+	return e.delegate.Rollback()
+}
+
+// ClearLocalCache 实现 Executor
+func (e *CachingExecutor) ClearLocalCache() {
+	e.delegate.ClearLocalCache()
+}
+
+// Close 实现 Executor，没有未提交的写语句时发布暂存的缓存项，否则丢弃
+func (e *CachingExecutor) Close() error {
+	if e.dirty {
+		e.rollbackCaches()
+	} else {
+		e.commitCaches()
+	}
+	return e.delegate.Close()
+}
+
+func (e *CachingExecutor) commitCaches() {
+	for _, tc := range e.caches {
+		tc.commit()
+	}
+	e.dirty = false
+}
+
+func (e *CachingExecutor) rollbackCaches() {
+	for _, tc := range e.caches {
+		tc.reset()
+	}
+	e.dirty = false
+}
@@ -0,0 +1,291 @@
+package executor
+
+import (
+	"reflect"
+	"testing"
+
+	"gobatis/cache"
+	"gobatis/core/config"
+	"gobatis/dialect"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestCachingExecutor_TransactionalCache 测试二级缓存在提交后发布、写语句提交后失效
+func TestCachingExecutor_TransactionalCache(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	configuration := &config.Configuration{
+		DataSource:   &config.DataSource{DB: db},
+		CacheEnabled: true,
+	}
+	userCache := cache.NewLRUCache("UserMapper", 10)
+	selectStmt := &config.MapperStatement{
+		ID:            "UserMapper.GetUser",
+		SQL:           "SELECT id, username FROM users WHERE id = #{id}",
+		ResultType:    reflect.TypeOf(TestUser{}),
+		StatementType: config.SELECT,
+		UseCache:      true,
+		Cache:         userCache,
+	}
+	updateStmt := &config.MapperStatement{
+		ID:            "UserMapper.UpdateUser",
+		SQL:           "UPDATE users SET username = #{username} WHERE id = #{id}",
+		StatementType: config.UPDATE,
+		FlushCache:    true,
+		Cache:         userCache,
+	}
+
+	expectUser := func(name string) {
+		mock.ExpectQuery("SELECT id, username FROM users").WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(1, name))
+	}
+	query := func(executor Executor) string {
+		results, err := executor.Query(selectStmt, map[string]interface{}{"id": 1})
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		return results[0].(TestUser).Username
+	}
+
+	// 未提交的查询结果对其他会话不可见
+	first := NewExecutor(configuration, config.ExecutorSimple)
+	second := NewExecutor(configuration, config.ExecutorSimple)
+	expectUser("john")
+	expectUser("john")
+	query(first)
+	query(second)
+	if userCache.Size() != 0 {
+		t.Fatal("Expected cache entries to be published only on commit")
+	}
+
+	// 回滚丢弃暂存的缓存项，提交后发布
+	second.Rollback()
+	if err := first.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	third := NewExecutor(configuration, config.ExecutorSimple)
+	if query(third) != "john" {
+		t.Fatal("Expected cached result")
+	}
+
+	// 不在事务中执行的写语句已生效，立即清空命名空间缓存，即使会话未提交即关闭
+	mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := third.Update(updateStmt, map[string]interface{}{"id": 1, "username": "johnny"}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	third.Close()
+	if userCache.Size() != 0 {
+		t.Fatal("Expected namespace cache to be cleared after an auto-committed update")
+	}
+	expectUser("johnny")
+	if query(NewExecutor(configuration, config.ExecutorSimple)) != "johnny" {
+		t.Fatal("Expected fresh result after update")
+	}
+
+	// 关闭没有写语句的会话时发布缓存项
+	fourth := NewExecutor(configuration, config.ExecutorSimple)
+	expectUser("johnny")
+	query(fourth)
+	fourth.Close()
+	if userCache.Size() != 1 {
+		t.Fatalf("Expected close to publish entries, got %d", userCache.Size())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestCachingExecutor_BatchTransaction 测试批量事务中的写语句在提交时才清空命名空间缓存，回滚时保留
+func TestCachingExecutor_BatchTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	configuration := &config.Configuration{
+		DataSource:   &config.DataSource{DB: db},
+		CacheEnabled: true,
+	}
+	userCache := cache.NewLRUCache("UserMapper", 10)
+	userCache.Put("cached", []interface{}{"john"})
+	updateStmt := &config.MapperStatement{
+		ID:            "UserMapper.UpdateUser",
+		SQL:           "UPDATE users SET username = #{username} WHERE id = #{id}",
+		StatementType: config.UPDATE,
+		FlushCache:    true,
+		Cache:         userCache,
+	}
+	parameter := map[string]interface{}{"id": 1, "username": "johnny"}
+
+	rolledBack := NewExecutor(configuration, config.ExecutorBatch)
+	rolledBack.Update(updateStmt, parameter)
+	if userCache.Size() != 1 {
+		t.Fatal("Expected uncommitted batch not to clear the cache")
+	}
+	rolledBack.Rollback()
+	if userCache.Size() != 1 {
+		t.Fatal("Expected rollback to keep the cache")
+	}
+
+	committed := NewExecutor(configuration, config.ExecutorBatch)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	committed.Update(updateStmt, parameter)
+	if err := committed.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if userCache.Size() != 0 {
+		t.Fatal("Expected commit to clear the cache")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// tenantInterceptor 在 ParameterHandler 阶段追加租户条件
+type tenantInterceptor struct {
+	tenant *string
+}
+
+func (i *tenantInterceptor) Intercepts(target, method string, statement *config.MapperStatement) bool {
+	return target == TargetParameterHandler
+}
+
+func (i *tenantInterceptor) InterceptStage(stage *Stage, proceed func() (interface{}, error)) (interface{}, error) {
+	result, err := proceed()
+	if err != nil {
+		return nil, err
+	}
+	stage.BoundSQL.SQL += " AND tenant_id = ?"
+	stage.BoundSQL.Args = append(stage.BoundSQL.Args, *i.tenant)
+	return result, nil
+}
+
+// TestCachingExecutor_KeyFromBoundSQL 测试二级缓存键包含插件追加的条件与参数，不同租户不共用缓存
+func TestCachingExecutor_KeyFromBoundSQL(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	configuration := &config.Configuration{
+		DataSource:   &config.DataSource{DB: db},
+		CacheEnabled: true,
+	}
+	selectStmt := &config.MapperStatement{
+		ID:            "UserMapper.GetUser",
+		SQL:           "SELECT id, username FROM users WHERE id = #{id}",
+		ResultType:    reflect.TypeOf(TestUser{}),
+		StatementType: config.SELECT,
+		UseCache:      true,
+		Cache:         cache.NewLRUCache("UserMapper", 10),
+	}
+
+	tenant := "a"
+	interceptor := &tenantInterceptor{tenant: &tenant}
+	query := func(expected string) {
+		executor := NewExecutorWithInterceptor(configuration, config.ExecutorSimple, interceptor)
+		results, err := executor.Query(selectStmt, map[string]interface{}{"id": 1})
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if name := results[0].(TestUser).Username; name != expected {
+			t.Errorf("Expected %s, got %s", expected, name)
+		}
+		executor.Close()
+	}
+
+	mock.ExpectQuery("SELECT id, username FROM users WHERE id = \\? AND tenant_id = \\?").WithArgs(1, "a").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(1, "alice"))
+	query("alice")
+	query("alice")
+
+	tenant = "b"
+	mock.ExpectQuery("SELECT id, username FROM users WHERE id = \\? AND tenant_id = \\?").WithArgs(1, "b").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(1, "bob"))
+	query("bob")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestCachingExecutor_UpsertTable 测试按表名 upsert 清空 SQL 引用该表的命名空间缓存
+func TestCachingExecutor_UpsertTable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	configuration := config.NewConfiguration()
+	configuration.DataSource = &config.DataSource{DB: db}
+	configuration.Dialect = dialect.PostgreSQLDialect{}
+	configuration.CacheEnabled = true
+	userCache := cache.NewLRUCache("UserMapper", 10)
+	orderCache := cache.NewLRUCache("OrderMapper", 10)
+	selectStmt := &config.MapperStatement{
+		ID:            "UserMapper.GetUser",
+		SQL:           "SELECT id, username FROM users WHERE id = #{id}",
+		ResultType:    reflect.TypeOf(TestUser{}),
+		StatementType: config.SELECT,
+		UseCache:      true,
+		Cache:         userCache,
+	}
+	configuration.MapperConfig.Mappers[selectStmt.ID] = selectStmt
+	configuration.MapperConfig.Mappers["OrderMapper.GetOrder"] = &config.MapperStatement{
+		ID:            "OrderMapper.GetOrder",
+		SQL:           "SELECT id FROM orders WHERE user_id = #{id}",
+		StatementType: config.SELECT,
+		UseCache:      true,
+		Cache:         orderCache,
+	}
+	orderCache.Put("order", []interface{}{1})
+
+	query := func(name string) {
+		mock.ExpectQuery("SELECT id, username FROM users").WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(1, name))
+		executor := NewExecutor(configuration, config.ExecutorSimple)
+		results, err := executor.Query(selectStmt, map[string]interface{}{"id": 1})
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if got := results[0].(TestUser).Username; got != name {
+			t.Fatalf("Expected %s, got %s", name, got)
+		}
+		executor.Close()
+	}
+
+	query("john")
+	if userCache.Size() != 1 {
+		t.Fatal("Expected the query result to be cached")
+	}
+
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(sqlmock.NewResult(0, 1))
+	executor := NewExecutor(configuration, config.ExecutorSimple)
+	if _, err := executor.Upsert("users", []TestUser{{ID: 1, Username: "johnny"}}, []string{"id"}, nil); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	executor.Close()
+	if userCache.Size() != 0 {
+		t.Fatal("Expected the users namespace cache to be cleared")
+	}
+	if orderCache.Size() != 1 {
+		t.Fatal("Expected caches of other tables to be kept")
+	}
+
+	query("johnny")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -1,8 +1,12 @@
 package executor
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 
 	"gobatis/binding"
 	"gobatis/core/config"
@@ -13,35 +17,185 @@ import (
 type Executor interface {
 	Query(statement *config.MapperStatement, parameter interface{}) ([]interface{}, error)
 	Update(statement *config.MapperStatement, parameter interface{}) (int64, error)
+	// Upsert 批量插入或更新，target 为 INSERT 语句 ID 或表名
+	Upsert(target string, rows interface{}, conflictKeys, updateColumns []string) (int64, error)
+	// FlushStatements 执行累计的批量语句并返回结果，非批量执行器返回 nil
+	FlushStatements() ([]BatchResult, error)
+	// Commit 提交执行器内的事务
+	Commit() error
+	// Rollback 回滚执行器内的事务
+	Rollback() error
+	// ClearLocalCache 清空一级缓存
+	ClearLocalCache()
+	// Close 释放执行器持有的资源，如缓存的预编译语句
+	Close() error
 }
 
-// SimpleExecutor 简单执行器
-type SimpleExecutor struct {
+// NewExecutor 根据执行器类型创建执行器，启用二级缓存时以 CachingExecutor 装饰
+func NewExecutor(configuration *config.Configuration, executorType config.ExecutorType) Executor {
+	var executor Executor
+	switch executorType {
+	case config.ExecutorReuse:
+		executor = NewReuseExecutor(configuration)
+	case config.ExecutorBatch:
+		executor = NewBatchExecutor(configuration)
+	default:
+		executor = NewSimpleExecutor(configuration)
+	}
+	if configuration.CacheEnabled {
+		return NewCachingExecutor(configuration, executor)
+	}
+	return executor
+}
+
+// baseExecutor 执行器的公共流程，runner 决定 SQL 如何发送到数据库
+type baseExecutor struct {
 	configuration   *config.Configuration
 	parameterBinder binding.ParameterBinder
 	resultMapper    mapping.ResultMapper
+	runner          func() Runner
+	localCache      map[string][]interface{} // 一级缓存，键为语句 ID、SQL 与参数
+	interceptor     Interceptor              // 拦截参数绑定、语句执行与结果映射，可为 nil
+	queryCache      queryCache               // 二级缓存，由 CachingExecutor 设置，可为 nil
 }
 
-// NewSimpleExecutor 创建简单执行器
-func NewSimpleExecutor(configuration *config.Configuration) Executor {
-	return &SimpleExecutor{
+func newBaseExecutor(configuration *config.Configuration) baseExecutor {
+	return baseExecutor{
 		configuration:   configuration,
-		parameterBinder: binding.NewParameterBinder(),
-		resultMapper:    mapping.NewResultMapper(),
+		parameterBinder: configuration.NewParameterBinder(),
+		resultMapper:    configuration.NewResultMapper(),
+		localCache:      make(map[string][]interface{}),
+	}
+}
+
+// bindStatement 按语句缓存的占位符计划绑定参数，绑定器不支持计划时按 SQL 文本绑定；
+// INSERT 的参数为结构体或 Map 的切片时逐行绑定，合并为一条多行插入
+func bindStatement(binder binding.ParameterBinder, statement *config.MapperStatement, parameter interface{}) (string, []interface{}, error) {
+	if rows, ok := insertRows(statement, parameter); ok {
+		return bindInsertRows(binder, statement, rows)
+	}
+	return bindSingle(binder, statement, parameter)
+}
+
+// bindSingle 以单个参数绑定语句
+func bindSingle(binder binding.ParameterBinder, statement *config.MapperStatement, parameter interface{}) (string, []interface{}, error) {
+	if _, ok := binder.(binding.PlanBinder); ok {
+		return bindPlan(binder, statement.PlaceholderPlan(), parameter)
+	}
+	return binder.BindParameters(statement.SQL, parameter)
+}
+
+// insertRows 获取 INSERT 语句切片参数中的各行，元素须为结构体、结构体指针或 Map
+func insertRows(statement *config.MapperStatement, parameter interface{}) (reflect.Value, bool) {
+	if statement.StatementType != config.INSERT || parameter == nil {
+		return reflect.Value{}, false
+	}
+	v := reflect.ValueOf(parameter)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return reflect.Value{}, false
+	}
+	element := v.Type().Elem()
+	for element.Kind() == reflect.Ptr {
+		element = element.Elem()
+	}
+	switch element.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Interface:
+		return v, true
+	}
+	return reflect.Value{}, false
+}
+
+// bindInsertRows 逐行绑定单行 INSERT ... VALUES (...)，将各行的 VALUES 元组合并为多行插入
+func bindInsertRows(binder binding.ParameterBinder, statement *config.MapperStatement, rows reflect.Value) (string, []interface{}, error) {
+	if rows.Len() == 0 {
+		return "", nil, fmt.Errorf("statement %s: no rows to insert", statement.ID)
+	}
+
+	var query strings.Builder
+	var args []interface{}
+	var firstSQL, tuple string
+	for i := 0; i < rows.Len(); i++ {
+		rowSQL, rowArgs, err := bindSingle(binder, statement, rows.Index(i).Interface())
+		if err != nil {
+			return "", nil, fmt.Errorf("row %d: %w", i, err)
+		}
+		if i == 0 {
+			var prefix string
+			var ok bool
+			prefix, tuple, ok = splitInsertValues(rowSQL)
+			if !ok || countPlaceholders(prefix) > 0 || countPlaceholders(tuple) != len(rowArgs) {
+				return "", nil, fmt.Errorf("statement %s: a slice parameter requires a single-row INSERT ... VALUES (...)", statement.ID)
+			}
+			firstSQL = rowSQL
+			query.WriteString(prefix)
+		} else {
+			if rowSQL != firstSQL {
+				return "", nil, fmt.Errorf("statement %s: row %d binds to different SQL than row 0", statement.ID, i)
+			}
+			query.WriteString(", ")
+		}
+		query.WriteString(tuple)
+		args = append(args, rowArgs...)
+	}
+	return query.String(), args, nil
+}
+
+// bindPlan 按解析好的占位符计划绑定参数
+func bindPlan(binder binding.ParameterBinder, plan *binding.Plan, parameter interface{}) (string, []interface{}, error) {
+	if planBinder, ok := binder.(binding.PlanBinder); ok {
+		return planBinder.BindPlan(plan, parameter)
 	}
+	return binder.BindParameters(plan.Source(), parameter)
 }
 
-// Query 执行查询
-func (e *SimpleExecutor) Query(statement *config.MapperStatement, parameter interface{}) ([]interface{}, error) {
+// Query 执行查询，声明 useCache 的语句在会话内缓存结果
+func (e *baseExecutor) Query(statement *config.MapperStatement, parameter interface{}) ([]interface{}, error) {
+	begin := time.Now()
+
+	if statement.FlushCache {
+		e.ClearLocalCache()
+	}
+
 	// 绑定参数
-	processedSQL, args, err := e.parameterBinder.BindParameters(statement.SQL, parameter)
+	bound, err := e.bindParameters(statement, parameter)
 	if err != nil {
+		e.trace(begin, func() (string, int64) {
+			return fmt.Sprintf("%s [PARAMS: %v]", statement.SQL, parameter), -1
+		}, err)
 		return nil, fmt.Errorf("failed to bind parameters: %w", err)
 	}
+	processedSQL, args := bound.SQL, bound.Args
+
+	// 二级缓存以 ParameterHandler 插件处理后的 SQL 与参数为键；
+	// StatementHandler 插件在执行时才可能改写 SQL，此时无法确定键，不使用二级缓存
+	useSharedCache := e.queryCache != nil && statement.Cache != nil && statement.UseCache &&
+		!e.intercepts(TargetStatementHandler, MethodQuery, statement)
+	// 命中一级缓存时返回结果切片的副本
+	useLocalCache := statement.UseCache && e.configuration.LocalCacheScope == config.LocalCacheSession
+	var key string
+	if useSharedCache || useLocalCache {
+		key = CacheKey(statement.ID, processedSQL, args)
+	}
+	if useSharedCache {
+		if cached, exists := e.queryCache.getCached(statement, key); exists {
+			return cached, nil
+		}
+	}
+	if useLocalCache {
+		if cached, exists := e.localCache[key]; exists {
+			return append([]interface{}(nil), cached...), nil
+		}
+	}
 
 	// 执行查询
-	rows, err := e.configuration.DataSource.DB.Query(processedSQL, args...)
+	rows, err := e.statementHandler(e.runner(), statement, parameter, bound).Query(processedSQL, args...)
 	if err != nil {
+		e.trace(begin, func() (string, int64) {
+			return fmt.Sprintf("%s [ARGS: %v]", processedSQL, args), -1
+		}, err)
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
 	defer rows.Close()
@@ -54,129 +208,150 @@ func (e *SimpleExecutor) Query(statement *config.MapperStatement, parameter inte
 	}
 
 	// 映射结果
-	results, err := e.resultMapper.MapResults(rows, resultType)
+	results, err := e.handleResultSets(statement, parameter, bound, rows, func(rows *sql.Rows) ([]interface{}, error) {
+		return e.resultMapper.MapResultsWithContext(rows, mapping.MappingContext{
+			StatementID: statement.ID,
+			ResultType:  resultType,
+			ResultMap:   statement.ResultMap,
+		})
+	})
 	if err != nil {
+		e.trace(begin, func() (string, int64) {
+			return fmt.Sprintf("%s [ARGS: %v]", processedSQL, args), -1
+		}, err)
 		return nil, fmt.Errorf("failed to map results: %w", err)
 	}
 
+	e.trace(begin, func() (string, int64) {
+		return fmt.Sprintf("%s [ARGS: %v]", processedSQL, args), int64(len(results))
+	}, nil)
+
+	if useSharedCache {
+		e.queryCache.putCached(statement, key, results)
+	}
+	if useLocalCache {
+		e.localCache[key] = append([]interface{}(nil), results...)
+	}
+
 	return results, nil
 }
 
-// Update 执行更新（包括 INSERT、UPDATE、DELETE）
-func (e *SimpleExecutor) Update(statement *config.MapperStatement, parameter interface{}) (int64, error) {
+// Update 执行更新（包括 INSERT、UPDATE、DELETE），并清空一级缓存
+func (e *baseExecutor) Update(statement *config.MapperStatement, parameter interface{}) (int64, error) {
+	begin := time.Now()
+	e.ClearLocalCache()
+
+	// selectKey 与主语句需在同一连接上执行
+	runner, release, err := PinConnection(e.runner(), statement)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	// 客户端生成主键与 BEFORE selectKey 需在绑定参数前完成
+	if err := GenerateKeys(e.configuration, statement, parameter); err != nil {
+		return 0, err
+	}
+	if err := ProcessSelectKey(runner, e.parameterBinder, statement, parameter, config.SelectKeyBefore); err != nil {
+		return 0, err
+	}
+
 	// 绑定参数
-	processedSQL, args, err := e.parameterBinder.BindParameters(statement.SQL, parameter)
+	bound, err := e.bindParameters(statement, parameter)
 	if err != nil {
+		e.trace(begin, func() (string, int64) {
+			return fmt.Sprintf("%s [PARAMS: %v]", statement.SQL, parameter), -1
+		}, err)
 		return 0, fmt.Errorf("failed to bind parameters: %w", err)
 	}
+	processedSQL, args := bound.SQL, bound.Args
 
-	// 执行更新
-	result, err := e.configuration.DataSource.DB.Exec(processedSQL, args...)
+	// 执行更新，声明 useGeneratedKeys 时回填主键
+	affected, err := ExecUpdate(e.statementHandler(runner, statement, parameter, bound), e.configuration, statement, processedSQL, args, parameter)
 	if err != nil {
+		e.trace(begin, func() (string, int64) {
+			return fmt.Sprintf("%s [ARGS: %v]", processedSQL, args), -1
+		}, err)
 		return 0, fmt.Errorf("failed to execute update: %w", err)
 	}
 
-	// 根据语句类型返回不同的结果
-	switch statement.StatementType {
-	case config.INSERT:
-		// 对于 INSERT，返回插入的 ID
-		if id, err := result.LastInsertId(); err == nil {
-			return id, nil
-		}
-		// 如果获取不到 ID，返回影响的行数
-		return result.RowsAffected()
-	case config.UPDATE, config.DELETE:
-		// 对于 UPDATE 和 DELETE，返回影响的行数
-		return result.RowsAffected()
-	default:
-		return result.RowsAffected()
+	if err := ProcessSelectKey(runner, e.parameterBinder, statement, parameter, config.SelectKeyAfter); err != nil {
+		return 0, err
 	}
-}
 
-// BatchExecutor 批量执行器
-type BatchExecutor struct {
-	configuration   *config.Configuration
-	parameterBinder binding.ParameterBinder
-	statements      []*BatchStatement
-}
+	e.trace(begin, func() (string, int64) {
+		return fmt.Sprintf("%s [ARGS: %v]", processedSQL, args), affected
+	}, nil)
 
-// BatchStatement 批量语句
-type BatchStatement struct {
-	Statement *config.MapperStatement
-	Parameter interface{}
+	return affected, nil
 }
 
-// NewBatchExecutor 创建批量执行器
-func NewBatchExecutor(configuration *config.Configuration) *BatchExecutor {
-	return &BatchExecutor{
-		configuration:   configuration,
-		parameterBinder: binding.NewParameterBinder(),
-		statements:      make([]*BatchStatement, 0),
-	}
+// setQueryCache 实现 queryCacheHolder
+func (e *baseExecutor) setQueryCache(cache queryCache) {
+	e.queryCache = cache
 }
 
-// AddBatch 添加批量语句
-func (e *BatchExecutor) AddBatch(statement *config.MapperStatement, parameter interface{}) {
-	e.statements = append(e.statements, &BatchStatement{
-		Statement: statement,
-		Parameter: parameter,
-	})
+// inTransaction 实现 transactionHolder，语句不在执行器的事务中执行，执行后即已生效
+func (e *baseExecutor) inTransaction() bool {
+	return false
 }
 
-// ExecuteBatch 执行批量操作
-func (e *BatchExecutor) ExecuteBatch() ([]int64, error) {
-	if len(e.statements) == 0 {
-		return nil, nil
-	}
+// FlushStatements 实现 Executor，语句已即时执行
+func (e *baseExecutor) FlushStatements() ([]BatchResult, error) {
+	return nil, nil
+}
 
-	// 开始事务
-	tx, err := e.configuration.DataSource.DB.Begin()
-	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
-	}
+// Commit 实现 Executor，语句不在执行器的事务中执行，仅清空一级缓存
+func (e *baseExecutor) Commit() error {
+	e.ClearLocalCache()
+	return nil
+}
 
-	var results []int64
-	for _, batchStmt := range e.statements {
-		// 绑定参数
-		processedSQL, args, err := e.parameterBinder.BindParameters(batchStmt.Statement.SQL, batchStmt.Parameter)
-		if err != nil {
-			tx.Rollback()
-			return nil, fmt.Errorf("failed to bind parameters: %w", err)
-		}
+// Rollback 实现 Executor，仅清空一级缓存
+func (e *baseExecutor) Rollback() error {
+	e.ClearLocalCache()
+	return nil
+}
 
-		// 执行语句
-		result, err := tx.Exec(processedSQL, args...)
-		if err != nil {
-			tx.Rollback()
-			return nil, fmt.Errorf("failed to execute batch statement: %w", err)
-		}
+// ClearLocalCache 实现 Executor
+func (e *baseExecutor) ClearLocalCache() {
+	clear(e.localCache)
+}
 
-		// 获取结果
-		switch batchStmt.Statement.StatementType {
-		case config.INSERT:
-			if id, err := result.LastInsertId(); err == nil {
-				results = append(results, id)
-			} else if affected, err := result.RowsAffected(); err == nil {
-				results = append(results, affected)
-			} else {
-				results = append(results, 0)
-			}
-		default:
-			if affected, err := result.RowsAffected(); err == nil {
-				results = append(results, affected)
-			} else {
-				results = append(results, 0)
-			}
-		}
+// CacheKey 生成查询结果的缓存键，由语句 ID、绑定后的 SQL 与参数组成
+func CacheKey(statementID, sql string, args []interface{}) string {
+	var b strings.Builder
+	b.WriteString(statementID)
+	b.WriteByte(0)
+	b.WriteString(sql)
+	for _, arg := range args {
+		// 同时写入类型，避免 1 与 "1" 冲突
+		fmt.Fprintf(&b, "\x00%T:%v", arg, arg)
 	}
+	return b.String()
+}
 
-	// 提交事务
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+// trace 记录 SQL 执行日志，未配置日志时忽略
+func (e *baseExecutor) trace(begin time.Time, fc func() (string, int64), err error) {
+	if e.configuration.Logger != nil {
+		e.configuration.Logger.Trace(context.Background(), begin, fc, err)
 	}
+}
+
+// SimpleExecutor 简单执行器，每次执行都直接发送 SQL
+type SimpleExecutor struct {
+	baseExecutor
+}
 
-	// 清空批量语句
-	e.statements = e.statements[:0]
+// NewSimpleExecutor 创建简单执行器
+func NewSimpleExecutor(configuration *config.Configuration) Executor {
+	e := &SimpleExecutor{baseExecutor: newBaseExecutor(configuration)}
+	e.runner = func() Runner { return e.configuration.DataSource.DB }
+	return e
+}
 
-	return results, nil
+// Close 实现 Executor，简单执行器只持有一级缓存
+func (e *SimpleExecutor) Close() error {
+	e.ClearLocalCache()
+	return nil
 }
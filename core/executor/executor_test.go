@@ -3,9 +3,12 @@ package executor
 import (
 	"errors"
 	"reflect"
+	"regexp"
 	"testing"
 
 	"gobatis/core/config"
+	"gobatis/dialect"
+	"gobatis/idgen"
 
 	"github.com/DATA-DOG/go-sqlmock"
 )
@@ -175,8 +178,9 @@ func TestSimpleExecutor_Update_Insert(t *testing.T) {
 		t.Fatalf("Update failed: %v", err)
 	}
 
-	if result != 123 {
-		t.Fatalf("Expected insert ID 123, got %d", result)
+	// INSERT 返回影响的行数而不是生成的主键
+	if result != 1 {
+		t.Fatalf("Expected 1 affected row, got %d", result)
 	}
 
 	// 验证模拟期望
@@ -185,172 +189,436 @@ func TestSimpleExecutor_Update_Insert(t *testing.T) {
 	}
 }
 
-// TestNewBatchExecutor 测试创建批量执行器
-func TestNewBatchExecutor(t *testing.T) {
-	configuration := &config.Configuration{}
-	executor := NewBatchExecutor(configuration)
-
-	if executor == nil {
-		t.Fatal("BatchExecutor should not be nil")
+// TestSimpleExecutor_Update_GeneratedKeys 测试通过 LastInsertId 回填主键
+func TestSimpleExecutor_Update_GeneratedKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
 	}
+	defer db.Close()
 
-	if executor.configuration != configuration {
-		t.Fatal("Configuration should be the same instance")
+	configuration := config.NewConfiguration()
+	configuration.DataSource = &config.DataSource{DB: db}
+	executor := NewSimpleExecutor(configuration)
+
+	statement := &config.MapperStatement{
+		ID:               "TestMapper.InsertUser",
+		SQL:              "INSERT INTO users (username) VALUES (#{username})",
+		StatementType:    config.INSERT,
+		UseGeneratedKeys: true,
+		KeyProperty:      "ID",
 	}
 
-	if executor.parameterBinder == nil {
-		t.Fatal("ParameterBinder should not be nil")
+	mock.ExpectExec("INSERT INTO users").
+		WithArgs("john").
+		WillReturnResult(sqlmock.NewResult(123, 1))
+
+	user := &TestUser{Username: "john"}
+	result, err := executor.Update(statement, user)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
 	}
 
-	if executor.statements == nil {
-		t.Fatal("Statements should not be nil")
+	if result != 1 {
+		t.Fatalf("Expected 1 affected row, got %d", result)
+	}
+	if user.ID != 123 {
+		t.Fatalf("Expected generated ID 123, got %d", user.ID)
 	}
 
-	if len(executor.statements) != 0 {
-		t.Fatal("Statements should be empty initially")
+	// 非指针参数无法回填
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(sqlmock.NewResult(124, 1))
+	if _, err := executor.Update(statement, TestUser{Username: "jane"}); err == nil {
+		t.Fatal("Expected error for non-pointer parameter")
 	}
 }
 
-// TestBatchExecutor_AddBatch 测试添加批量语句
-func TestBatchExecutor_AddBatch(t *testing.T) {
-	configuration := &config.Configuration{}
-	executor := NewBatchExecutor(configuration)
+// TestSimpleExecutor_Update_Returning 测试支持 RETURNING 的方言回填主键
+func TestSimpleExecutor_Update_Returning(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	configuration := config.NewConfiguration()
+	configuration.DataSource = &config.DataSource{DB: db}
+	configuration.Dialect = dialect.PostgreSQLDialect{}
+	executor := NewSimpleExecutor(configuration)
 
 	statement := &config.MapperStatement{
-		ID:            "TestMapper.InsertUser",
-		SQL:           "INSERT INTO users (username) VALUES (#{username})",
-		StatementType: config.INSERT,
+		ID:               "TestMapper.InsertUser",
+		SQL:              "INSERT INTO users (username) VALUES (#{username})",
+		StatementType:    config.INSERT,
+		UseGeneratedKeys: true,
+		KeyProperty:      "ID",
+		KeyColumn:        "id",
 	}
 
-	parameter := map[string]interface{}{"username": "john"}
-
-	executor.AddBatch(statement, parameter)
+	mock.ExpectQuery("INSERT INTO users \\(username\\) VALUES \\(\\?\\) RETURNING id").
+		WithArgs("john").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(42)))
 
-	if len(executor.statements) != 1 {
-		t.Fatalf("Expected 1 statement, got %d", len(executor.statements))
+	user := &TestUser{Username: "john"}
+	result, err := executor.Update(statement, user)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
 	}
 
-	batchStmt := executor.statements[0]
-	if batchStmt.Statement != statement {
-		t.Fatal("Statement should be the same instance")
+	if result != 1 {
+		t.Fatalf("Expected 1 affected row, got %d", result)
+	}
+	if user.ID != 42 {
+		t.Fatalf("Expected generated ID 42, got %d", user.ID)
 	}
 
-	// 不能直接比较map，只检查类型
-	if batchStmt.Parameter == nil {
-		t.Fatal("Parameter should not be nil")
+	// 列名、别名或字符串中的 returning 不是 RETURNING 子句
+	statement.SQL = "INSERT INTO users (username, returning_customer) VALUES (#{username}, 'returning')"
+	mock.ExpectQuery("INSERT INTO users \\(username, returning_customer\\) VALUES \\(\\?, 'returning'\\) RETURNING id").
+		WithArgs("jane").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(43)))
+	user = &TestUser{Username: "jane"}
+	if _, err := executor.Update(statement, user); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if user.ID != 43 {
+		t.Fatalf("Expected generated ID 43, got %d", user.ID)
 	}
 }
 
-// TestBatchExecutor_ExecuteBatch_Empty 测试执行空批量
-func TestBatchExecutor_ExecuteBatch_Empty(t *testing.T) {
-	configuration := &config.Configuration{}
-	executor := NewBatchExecutor(configuration)
+// noRowsAffectedResult 驱动无法报告影响行数的结果
+type noRowsAffectedResult struct{ id int64 }
+
+func (r noRowsAffectedResult) LastInsertId() (int64, error) { return r.id, nil }
+
+func (r noRowsAffectedResult) RowsAffected() (int64, error) {
+	return 0, errors.New("rows affected not supported")
+}
 
-	results, err := executor.ExecuteBatch()
+// TestSimpleExecutor_Update_RowsAffectedUnsupported 测试驱动无法报告影响行数时插入仍然成功
+func TestSimpleExecutor_Update_RowsAffectedUnsupported(t *testing.T) {
+	db, mock, err := sqlmock.New()
 	if err != nil {
-		t.Fatalf("ExecuteBatch failed: %v", err)
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	configuration := config.NewConfiguration()
+	configuration.DataSource = &config.DataSource{DB: db}
+	executor := NewSimpleExecutor(configuration)
+
+	statement := &config.MapperStatement{
+		ID:               "TestMapper.InsertUser",
+		SQL:              "INSERT INTO users (username) VALUES (#{username})",
+		StatementType:    config.INSERT,
+		UseGeneratedKeys: true,
+		KeyProperty:      "ID",
+	}
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(noRowsAffectedResult{id: 7})
+	user := &TestUser{Username: "john"}
+	if _, err := executor.Update(statement, user); err != nil {
+		t.Fatalf("Expected insert to succeed, got %v", err)
+	}
+	if user.ID != 7 {
+		t.Errorf("Expected generated ID 7, got %d", user.ID)
 	}
 
-	if results != nil {
-		t.Fatal("Results should be nil for empty batch")
+	statement.UseGeneratedKeys = false
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(noRowsAffectedResult{id: 8})
+	if _, err := executor.Update(statement, &TestUser{Username: "jane"}); err != nil {
+		t.Fatalf("Expected insert to succeed, got %v", err)
 	}
 }
 
-// TestBatchExecutor_ExecuteBatch_Success 测试批量执行成功
-func TestBatchExecutor_ExecuteBatch_Success(t *testing.T) {
-	// 创建模拟数据库
+// TestSimpleExecutor_Update_SelectKey 测试 BEFORE 与 AFTER 的 selectKey
+func TestSimpleExecutor_Update_SelectKey(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("Failed to create mock database: %v", err)
 	}
 	defer db.Close()
 
-	// 创建配置
-	configuration := &config.Configuration{
-		DataSource: &config.DataSource{
-			DB: db,
+	configuration := config.NewConfiguration()
+	configuration.DataSource = &config.DataSource{DB: db}
+	executor := NewSimpleExecutor(configuration)
+
+	// BEFORE：先从序列获取主键，再用于插入
+	before := &config.MapperStatement{
+		ID:            "TestMapper.InsertUser",
+		SQL:           "INSERT INTO users (id, username) VALUES (#{id}, #{username})",
+		StatementType: config.INSERT,
+		SelectKey: &config.SelectKey{
+			SQL:         "SELECT nextval('users_seq')",
+			KeyProperty: "ID",
+			ResultType:  "int",
+			Order:       config.SelectKeyBefore,
 		},
 	}
 
-	// 创建执行器
-	executor := NewBatchExecutor(configuration)
+	mock.ExpectQuery("SELECT nextval").
+		WillReturnRows(sqlmock.NewRows([]string{"nextval"}).AddRow([]byte("1001")))
+	mock.ExpectExec("INSERT INTO users").
+		WithArgs(1001, "john").
+		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	// 创建语句
-	insertStmt := &config.MapperStatement{
+	user := &TestUser{Username: "john"}
+	if _, err := executor.Update(before, user); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if user.ID != 1001 {
+		t.Fatalf("Expected ID 1001 from sequence, got %d", user.ID)
+	}
+
+	// AFTER：插入后查询主键
+	after := &config.MapperStatement{
 		ID:            "TestMapper.InsertUser",
 		SQL:           "INSERT INTO users (username) VALUES (#{username})",
 		StatementType: config.INSERT,
+		SelectKey: &config.SelectKey{
+			SQL:         "SELECT LAST_INSERT_ID() AS id",
+			KeyProperty: "ID",
+			KeyColumn:   "id",
+			Order:       config.SelectKeyAfter,
+		},
 	}
 
-	updateStmt := &config.MapperStatement{
-		ID:            "TestMapper.UpdateUser",
-		SQL:           "UPDATE users SET username = #{username} WHERE id = #{id}",
-		StatementType: config.UPDATE,
+	mock.ExpectExec("INSERT INTO users").
+		WithArgs("jane").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT LAST_INSERT_ID").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1002)))
+
+	user = &TestUser{Username: "jane"}
+	if _, err := executor.Update(after, user); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if user.ID != 1002 {
+		t.Fatalf("Expected ID 1002, got %d", user.ID)
 	}
 
-	// 添加批量语句
-	executor.AddBatch(insertStmt, map[string]interface{}{"username": "john"})
-	executor.AddBatch(updateStmt, map[string]interface{}{"username": "jane", "id": 1})
+	// 无返回行时报错
+	mock.ExpectQuery("SELECT nextval").WillReturnRows(sqlmock.NewRows([]string{"nextval"}))
+	if _, err := executor.Update(before, &TestUser{Username: "empty"}); err == nil {
+		t.Fatal("Expected error when selectKey returns no rows")
+	}
 
-	// 设置模拟期望
-	mock.ExpectBegin()
-	mock.ExpectExec("INSERT INTO users \\(username\\) VALUES \\(\\?\\)").
-		WithArgs("john").
-		WillReturnResult(sqlmock.NewResult(123, 1))
-	mock.ExpectExec("UPDATE users SET username = \\? WHERE id = \\?").
-		WithArgs("jane", 1).
-		WillReturnResult(sqlmock.NewResult(0, 1))
-	mock.ExpectCommit()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Mock expectations were not met: %v", err)
+	}
+}
+
+// TestGenerateKeys 测试客户端主键生成
+func TestGenerateKeys(t *testing.T) {
+	type order struct {
+		ID     int64    `db:"id"`
+		Code   string   `db:"code,idgen=ulid"`
+		Token  [16]byte `db:"token,idgen=uuidv7"`
+		Number string   `db:"number"`
+	}
+
+	configuration := config.NewConfiguration()
+	configuration.RegisterIdGenerator("fixed", idgen.IdGeneratorFunc(func() (interface{}, error) {
+		return int64(77), nil
+	}))
 
-	// 执行批量操作
-	results, err := executor.ExecuteBatch()
+	statement := &config.MapperStatement{
+		ID:            "OrderMapper.Insert",
+		StatementType: config.INSERT,
+		KeyGenerator:  "fixed",
+		KeyProperty:   "ID",
+	}
+
+	orders := []*order{{}, {ID: 5, Code: "KEEP"}}
+	if err := GenerateKeys(configuration, statement, orders); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if orders[0].ID != 77 || len(orders[0].Code) != 26 || orders[0].Token == ([16]byte{}) {
+		t.Fatalf("Expected generated keys, got %+v", orders[0])
+	}
+	// 非零值保持不变
+	if orders[1].ID != 5 || orders[1].Code != "KEEP" {
+		t.Fatalf("Expected existing keys to be kept, got %+v", orders[1])
+	}
+
+	params := map[string]interface{}{"number": "A1"}
+	if err := GenerateKeys(configuration, statement, params); err != nil || params["ID"] != int64(77) {
+		t.Fatalf("Expected ID on map parameter, got %v (%v)", params["ID"], err)
+	}
+
+	statement.KeyGenerator = "missing"
+	if err := GenerateKeys(configuration, statement, &order{}); err == nil {
+		t.Fatal("Expected error for unknown id generator")
+	}
+}
+
+// TestKeyTargets_MultiRow 测试多行插入时为每个元素回填主键
+func TestKeyTargets_MultiRow(t *testing.T) {
+	users := []*TestUser{{Username: "a"}, {Username: "b"}}
+
+	targets, err := keyTargets(users)
 	if err != nil {
-		t.Fatalf("ExecuteBatch failed: %v", err)
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i, target := range targets {
+		if err := setKeyProperty(target, "ID", int64(10+i)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if users[0].ID != 10 || users[1].ID != 11 {
+		t.Fatalf("Unexpected IDs: %d, %d", users[0].ID, users[1].ID)
 	}
 
-	if len(results) != 2 {
-		t.Fatalf("Expected 2 results, got %d", len(results))
+	values := []TestUser{{}, {}}
+	targets, _ = keyTargets(values)
+	if err := setKeyProperty(targets[1], "ID", []byte("7")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if values[1].ID != 7 {
+		t.Fatalf("Expected ID 7, got %d", values[1].ID)
 	}
 
-	if results[0] != 123 {
-		t.Fatalf("Expected insert ID 123, got %d", results[0])
+	params := map[string]interface{}{"username": "c"}
+	targets, _ = keyTargets(params)
+	if err := setKeyProperty(targets[0], "id", int64(9)); err != nil || params["id"] != int64(9) {
+		t.Fatalf("Expected id to be set on map, got %v (%v)", params["id"], err)
 	}
+}
 
-	if results[1] != 1 {
-		t.Fatalf("Expected affected rows 1, got %d", results[1])
+// TestSimpleExecutor_Update_MultiRowGeneratedKeys 测试切片参数合并为多行插入，并为每个元素回填主键
+func TestSimpleExecutor_Update_MultiRowGeneratedKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
 	}
+	defer db.Close()
 
-	// 验证批量语句已清空
-	if len(executor.statements) != 0 {
-		t.Fatal("Statements should be cleared after execution")
+	configuration := config.NewConfiguration()
+	configuration.DataSource = &config.DataSource{DB: db}
+	executor := NewSimpleExecutor(configuration)
+
+	statement := &config.MapperStatement{
+		ID:               "TestMapper.InsertUsers",
+		SQL:              "INSERT INTO users (username) VALUES (#{username})",
+		StatementType:    config.INSERT,
+		UseGeneratedKeys: true,
+		KeyProperty:      "ID",
+	}
+	multiRow := regexp.QuoteMeta("INSERT INTO users (username) VALUES (?), (?), (?)")
+
+	// MySQL 的 LastInsertId 为第一行的主键，后续行连续
+	configuration.Dialect = dialect.MySQLDialect{}
+	users := []*TestUser{{Username: "a"}, {Username: "b"}, {Username: "c"}}
+	mock.ExpectExec(multiRow).WithArgs("a", "b", "c").WillReturnResult(sqlmock.NewResult(10, 3))
+	affected, err := executor.Update(statement, users)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if affected != 3 || users[0].ID != 10 || users[1].ID != 11 || users[2].ID != 12 {
+		t.Fatalf("Unexpected keys %d, %d, %d (affected %d)", users[0].ID, users[1].ID, users[2].ID, affected)
+	}
+
+	// RETURNING 按行返回主键，切片元素为值时同样回填
+	configuration.Dialect = dialect.PostgreSQLDialect{}
+	values := []TestUser{{Username: "a"}, {Username: "b"}, {Username: "c"}}
+	mock.ExpectQuery(multiRow+" RETURNING id").WithArgs("a", "b", "c").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(21)).AddRow(int64(22)).AddRow(int64(23)))
+	if _, err := executor.Update(statement, values); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if values[0].ID != 21 || values[1].ID != 22 || values[2].ID != 23 {
+		t.Fatalf("Unexpected keys %+v", values)
+	}
+
+	// 其他方言不保证主键连续，执行前拒绝
+	configuration.Dialect = dialect.GenericDialect{}
+	if _, err := executor.Update(statement, []*TestUser{{Username: "a"}, {Username: "b"}}); err == nil {
+		t.Fatal("Expected error for multi-row generated keys on the generic dialect")
 	}
 
-	// 验证模拟期望
 	if err := mock.ExpectationsWereMet(); err != nil {
-		t.Errorf("Mock expectations were not met: %v", err)
+		t.Errorf("Unfulfilled expectations: %v", err)
 	}
 }
 
-// TestBatchStatement 测试BatchStatement结构
-func TestBatchStatement(t *testing.T) {
-	statement := &config.MapperStatement{
-		ID:            "TestMapper.InsertUser",
-		SQL:           "INSERT INTO users (username) VALUES (#{username})",
-		StatementType: config.INSERT,
+// TestSimpleExecutor_LocalCache 测试一级缓存的命中与清空
+func TestSimpleExecutor_LocalCache(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	configuration := &config.Configuration{
+		DataSource: &config.DataSource{DB: db},
 	}
+	executor := NewSimpleExecutor(configuration)
 
-	parameter := map[string]interface{}{"username": "john"}
+	selectStmt := &config.MapperStatement{
+		ID:            "TestMapper.GetUser",
+		SQL:           "SELECT id, username FROM users WHERE id = #{id}",
+		ResultType:    reflect.TypeOf(TestUser{}),
+		StatementType: config.SELECT,
+		UseCache:      true,
+	}
+	updateStmt := &config.MapperStatement{
+		ID:            "TestMapper.UpdateUser",
+		SQL:           "UPDATE users SET username = #{username} WHERE id = #{id}",
+		StatementType: config.UPDATE,
+	}
 
-	batchStmt := &BatchStatement{
-		Statement: statement,
-		Parameter: parameter,
+	expectUser := func(id int, name string) {
+		mock.ExpectQuery("SELECT id, username FROM users").WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(id, name))
+	}
+	query := func(id int) string {
+		results, err := executor.Query(selectStmt, map[string]interface{}{"id": id})
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		return results[0].(TestUser).Username
 	}
 
-	if batchStmt.Statement != statement {
-		t.Fatal("Statement should be the same instance")
+	// 相同参数只查询一次，不同参数分别查询
+	expectUser(1, "john")
+	expectUser(2, "jane")
+	if query(1) != "john" || query(1) != "john" || query(2) != "jane" {
+		t.Fatal("Unexpected cached results")
 	}
 
-	if batchStmt.Parameter == nil {
-		t.Fatal("Parameter should not be nil")
+	// 更新后缓存失效
+	mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := executor.Update(updateStmt, map[string]interface{}{"id": 1, "username": "johnny"}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	expectUser(1, "johnny")
+	if query(1) != "johnny" {
+		t.Fatal("Expected fresh result after update")
+	}
+
+	// ClearLocalCache 与 Commit 清空缓存
+	executor.ClearLocalCache()
+	expectUser(1, "johnny")
+	query(1)
+	executor.Commit()
+	expectUser(1, "johnny")
+	query(1)
+
+	// flushCache 的语句每次执行前清空缓存
+	selectStmt.FlushCache = true
+	expectUser(1, "johnny")
+	query(1)
+	selectStmt.FlushCache = false
+
+	// STATEMENT 作用范围不跨语句缓存
+	configuration.LocalCacheScope = config.LocalCacheStatement
+	executor.ClearLocalCache()
+	expectUser(1, "johnny")
+	expectUser(1, "johnny")
+	query(1)
+	query(1)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Mock expectations were not met: %v", err)
 	}
 }
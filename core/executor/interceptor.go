@@ -0,0 +1,298 @@
+package executor
+
+import (
+	"database/sql"
+	"fmt"
+
+	"gobatis/core/config"
+)
+
+// 可拦截的目标，对应 MyBatis 的 Executor、StatementHandler、ParameterHandler 与 ResultSetHandler
+const (
+	TargetExecutor         = config.TargetExecutor
+	TargetStatementHandler = config.TargetStatementHandler
+	TargetParameterHandler = config.TargetParameterHandler
+	TargetResultSetHandler = config.TargetResultSetHandler
+)
+
+// 可拦截的方法：
+//   - Executor.Query 返回 []interface{}，Executor.Update 返回 int64
+//   - ParameterHandler.SetParameters 绑定参数，返回 *BoundSQL
+//   - StatementHandler.Query 返回 *sql.Rows，StatementHandler.Update 返回 sql.Result
+//   - ResultSetHandler.HandleResultSets 映射结果，返回 []interface{}
+const (
+	MethodQuery            = "Query"
+	MethodUpdate           = "Update"
+	MethodSetParameters    = "SetParameters"
+	MethodHandleResultSets = "HandleResultSets"
+)
+
+// BoundSQL 绑定参数后发送到数据库的 SQL，拦截器可修改 SQL 与 Args
+type BoundSQL = config.BoundSQL
+
+// Stage 执行器中一次可拦截的调用，proceed 读取 Stage 中的语句、参数与 BoundSQL
+type Stage struct {
+	Target    string
+	Method    string
+	Statement *config.MapperStatement
+	Parameter interface{}
+	// BoundSQL ParameterHandler 阶段由 proceed 填充，之后的阶段按其执行
+	BoundSQL *BoundSQL
+	// Rows ResultSetHandler 阶段待映射的结果集
+	Rows *sql.Rows
+	// Executor Executor 阶段被拦截的执行器
+	Executor Executor
+}
+
+// Interceptor 拦截执行器的各阶段，由插件管理器实现
+type Interceptor interface {
+	// Intercepts 是否有插件拦截 statement 在 target 的 method，否则执行器直接执行
+	Intercepts(target, method string, statement *config.MapperStatement) bool
+	// InterceptStage 经插件执行阶段，proceed 执行原逻辑
+	InterceptStage(stage *Stage, proceed func() (interface{}, error)) (interface{}, error)
+}
+
+// interceptable 可设置拦截器的执行器
+type interceptable interface {
+	setInterceptor(interceptor Interceptor)
+}
+
+// NewExecutorWithInterceptor 创建执行器，interceptor 拦截执行器及其内部各阶段
+func NewExecutorWithInterceptor(configuration *config.Configuration, executorType config.ExecutorType, interceptor Interceptor) Executor {
+	executor := NewExecutor(configuration, executorType)
+	if interceptor == nil {
+		return executor
+	}
+
+	inner := executor
+	if caching, ok := inner.(*CachingExecutor); ok {
+		inner = caching.Delegate()
+	}
+	if target, ok := inner.(interceptable); ok {
+		target.setInterceptor(interceptor)
+	}
+	return NewInterceptingExecutor(executor, interceptor)
+}
+
+// setInterceptor 实现 interceptable
+func (e *baseExecutor) setInterceptor(interceptor Interceptor) {
+	e.interceptor = interceptor
+}
+
+// intercepts 是否需要经拦截器执行
+func (e *baseExecutor) intercepts(target, method string, statement *config.MapperStatement) bool {
+	return e.interceptor != nil && e.interceptor.Intercepts(target, method, statement)
+}
+
+// bindParameters 绑定参数，即 ParameterHandler.SetParameters 阶段
+func (e *baseExecutor) bindParameters(statement *config.MapperStatement, parameter interface{}) (*BoundSQL, error) {
+	bind := func(stage *Stage) (*BoundSQL, error) {
+		processedSQL, args, err := bindStatement(e.parameterBinder, stage.Statement, stage.Parameter)
+		if err != nil {
+			return nil, err
+		}
+		stage.BoundSQL.SQL, stage.BoundSQL.Args = processedSQL, args
+		return stage.BoundSQL, nil
+	}
+
+	stage := &Stage{
+		Target:    TargetParameterHandler,
+		Method:    MethodSetParameters,
+		Statement: statement,
+		Parameter: parameter,
+		BoundSQL:  &BoundSQL{StatementID: statement.ID, StatementType: statement.StatementType},
+	}
+	if !e.intercepts(stage.Target, stage.Method, statement) {
+		return bind(stage)
+	}
+
+	result, err := e.interceptor.InterceptStage(stage, func() (interface{}, error) {
+		return bind(stage)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if bound, ok := result.(*BoundSQL); ok && bound != nil {
+		return bound, nil
+	}
+	return stage.BoundSQL, nil
+}
+
+// handleResultSets 映射结果集，即 ResultSetHandler.HandleResultSets 阶段
+func (e *baseExecutor) handleResultSets(statement *config.MapperStatement, parameter interface{}, bound *BoundSQL, rows *sql.Rows, handle func(rows *sql.Rows) ([]interface{}, error)) ([]interface{}, error) {
+	if !e.intercepts(TargetResultSetHandler, MethodHandleResultSets, statement) {
+		return handle(rows)
+	}
+
+	stage := &Stage{
+		Target:    TargetResultSetHandler,
+		Method:    MethodHandleResultSets,
+		Statement: statement,
+		Parameter: parameter,
+		BoundSQL:  bound,
+		Rows:      rows,
+	}
+	result, err := e.interceptor.InterceptStage(stage, func() (interface{}, error) {
+		return handle(stage.Rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	results, ok := result.([]interface{})
+	if !ok && result != nil {
+		return nil, fmt.Errorf("ResultSetHandler interceptor returned %T, expected []interface{}", result)
+	}
+	return results, nil
+}
+
+// statementHandler 拦截 StatementHandler 阶段时包装 Runner，
+// 发送到数据库前将 SQL 与参数写入 bound 供插件修改
+func (e *baseExecutor) statementHandler(runner Runner, statement *config.MapperStatement, parameter interface{}, bound *BoundSQL) Runner {
+	if !e.intercepts(TargetStatementHandler, MethodQuery, statement) && !e.intercepts(TargetStatementHandler, MethodUpdate, statement) {
+		return runner
+	}
+	return &interceptedRunner{runner: runner, executor: e, statement: statement, parameter: parameter, bound: bound}
+}
+
+// interceptedRunner 经 StatementHandler 拦截执行的 Runner
+type interceptedRunner struct {
+	runner    Runner
+	executor  *baseExecutor
+	statement *config.MapperStatement
+	parameter interface{}
+	bound     *BoundSQL
+}
+
+// Exec 实现 Runner，即 StatementHandler.Update 阶段
+func (r *interceptedRunner) Exec(query string, args ...interface{}) (sql.Result, error) {
+	result, err := r.intercept(MethodUpdate, query, args, func(bound *BoundSQL) (interface{}, error) {
+		return r.runner.Exec(bound.SQL, bound.Args...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	execResult, ok := result.(sql.Result)
+	if !ok {
+		return nil, fmt.Errorf("StatementHandler interceptor returned %T, expected sql.Result", result)
+	}
+	return execResult, nil
+}
+
+// Query 实现 Runner，即 StatementHandler.Query 阶段
+func (r *interceptedRunner) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	result, err := r.intercept(MethodQuery, query, args, func(bound *BoundSQL) (interface{}, error) {
+		return r.runner.Query(bound.SQL, bound.Args...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	rows, ok := result.(*sql.Rows)
+	if !ok {
+		return nil, fmt.Errorf("StatementHandler interceptor returned %T, expected *sql.Rows", result)
+	}
+	return rows, nil
+}
+
+func (r *interceptedRunner) intercept(method, query string, args []interface{}, execute func(bound *BoundSQL) (interface{}, error)) (interface{}, error) {
+	r.bound.SQL, r.bound.Args = query, args
+	if !r.executor.intercepts(TargetStatementHandler, method, r.statement) {
+		return execute(r.bound)
+	}
+
+	stage := &Stage{
+		Target:    TargetStatementHandler,
+		Method:    method,
+		Statement: r.statement,
+		Parameter: r.parameter,
+		BoundSQL:  r.bound,
+	}
+	return r.executor.interceptor.InterceptStage(stage, func() (interface{}, error) {
+		return execute(stage.BoundSQL)
+	})
+}
+
+// InterceptingExecutor 经拦截器执行 Query 与 Update 的执行器装饰器，位于二级缓存之外
+type InterceptingExecutor struct {
+	delegate    Executor
+	interceptor Interceptor
+}
+
+// NewInterceptingExecutor 为执行器增加 Executor 阶段的拦截
+func NewInterceptingExecutor(delegate Executor, interceptor Interceptor) *InterceptingExecutor {
+	return &InterceptingExecutor{delegate: delegate, interceptor: interceptor}
+}
+
+// Delegate 返回被装饰的执行器
+func (e *InterceptingExecutor) Delegate() Executor {
+	return e.delegate
+}
+
+// Query 实现 Executor，即 Executor.Query 阶段
+func (e *InterceptingExecutor) Query(statement *config.MapperStatement, parameter interface{}) ([]interface{}, error) {
+	if !e.interceptor.Intercepts(TargetExecutor, MethodQuery, statement) {
+		return e.delegate.Query(statement, parameter)
+	}
+
+	stage := &Stage{Target: TargetExecutor, Method: MethodQuery, Statement: statement, Parameter: parameter, Executor: e.delegate}
+	result, err := e.interceptor.InterceptStage(stage, func() (interface{}, error) {
+		return e.delegate.Query(stage.Statement, stage.Parameter)
+	})
+	if err != nil {
+		return nil, err
+	}
+	results, ok := result.([]interface{})
+	if !ok && result != nil {
+		return nil, fmt.Errorf("Executor interceptor returned %T, expected []interface{}", result)
+	}
+	return results, nil
+}
+
+// Update 实现 Executor，即 Executor.Update 阶段
+func (e *InterceptingExecutor) Update(statement *config.MapperStatement, parameter interface{}) (int64, error) {
+	if !e.interceptor.Intercepts(TargetExecutor, MethodUpdate, statement) {
+		return e.delegate.Update(statement, parameter)
+	}
+
+	stage := &Stage{Target: TargetExecutor, Method: MethodUpdate, Statement: statement, Parameter: parameter, Executor: e.delegate}
+	result, err := e.interceptor.InterceptStage(stage, func() (interface{}, error) {
+		return e.delegate.Update(stage.Statement, stage.Parameter)
+	})
+	if err != nil {
+		return 0, err
+	}
+	affected, ok := result.(int64)
+	if !ok {
+		return 0, fmt.Errorf("Executor interceptor returned %T, expected int64", result)
+	}
+	return affected, nil
+}
+
+// Upsert 实现 Executor
+func (e *InterceptingExecutor) Upsert(target string, rows interface{}, conflictKeys, updateColumns []string) (int64, error) {
+	return e.delegate.Upsert(target, rows, conflictKeys, updateColumns)
+}
+
+// FlushStatements 实现 Executor
+func (e *InterceptingExecutor) FlushStatements() ([]BatchResult, error) {
+	return e.delegate.FlushStatements()
+}
+
+// Commit 实现 Executor
+func (e *InterceptingExecutor) Commit() error {
+	return e.delegate.Commit()
+}
+
+// Rollback 实现 Executor
+func (e *InterceptingExecutor) Rollback() error {
+	return e.delegate.Rollback()
+}
+
+// ClearLocalCache 实现 Executor
+func (e *InterceptingExecutor) ClearLocalCache() {
+	e.delegate.ClearLocalCache()
+}
+
+// Close 实现 Executor
+func (e *InterceptingExecutor) Close() error {
+	return e.delegate.Close()
+}
@@ -0,0 +1,599 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gobatis/binding"
+	"gobatis/core/config"
+	"gobatis/dialect"
+	"gobatis/reflection"
+)
+
+// Runner 可执行 SQL 的数据库连接或事务，*sql.DB 与 *sql.Tx 均满足
+type Runner interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// connRunner 固定在单个连接上的 Runner
+type connRunner struct {
+	conn *sql.Conn
+}
+
+// Exec 实现 Runner
+func (r connRunner) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return r.conn.ExecContext(context.Background(), query, args...)
+}
+
+// Query 实现 Runner
+func (r connRunner) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return r.conn.QueryContext(context.Background(), query, args...)
+}
+
+// PinConnection 语句声明了 selectKey 且不在事务中时，从连接池取出单个连接，
+// 保证主键查询与主语句在同一连接上执行；调用方需调用返回的 release 归还连接
+func PinConnection(runner Runner, statement *config.MapperStatement) (Runner, func(), error) {
+	if statement.SelectKey == nil {
+		return runner, func() {}, nil
+	}
+	if r, ok := runner.(*statementRunner); ok {
+		return r.pin()
+	}
+	db, ok := runner.(*sql.DB)
+	if !ok {
+		return runner, func() {}, nil
+	}
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	return connRunner{conn: conn}, func() { conn.Close() }, nil
+}
+
+// ProcessSelectKey 执行指定时机（BEFORE/AFTER）的 <selectKey> 查询并将结果写入参数，
+// 参数为切片时对每个元素分别执行
+func ProcessSelectKey(runner Runner, binder binding.ParameterBinder, statement *config.MapperStatement, parameter interface{}, order string) error {
+	selectKey := statement.SelectKey
+	if selectKey == nil || selectKey.Order != order {
+		return nil
+	}
+
+	targets, err := keyTargets(parameter)
+	if err != nil {
+		return fmt.Errorf("statement %s: %w", statement.ID, err)
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("statement %s: selectKey requires a parameter to set %s", statement.ID, selectKey.KeyProperty)
+	}
+
+	keyProperties := splitKeys(selectKey.KeyProperty)
+	for _, target := range targets {
+		keyParameter := parameter
+		if len(targets) > 1 {
+			keyParameter = targetParameter(target)
+		}
+
+		values, err := querySelectKey(runner, binder, selectKey, keyParameter, len(keyProperties))
+		if err != nil {
+			return fmt.Errorf("statement %s: %w", statement.ID, err)
+		}
+		for i, property := range keyProperties {
+			value, err := convertKeyResult(values[i], selectKey.ResultType)
+			if err != nil {
+				return fmt.Errorf("statement %s: %w", statement.ID, err)
+			}
+			if err := setKeyProperty(target, property, value); err != nil {
+				return fmt.Errorf("statement %s: %w", statement.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// querySelectKey 执行主键查询，要求返回且仅返回一行
+func querySelectKey(runner Runner, binder binding.ParameterBinder, selectKey *config.SelectKey, parameter interface{}, keyCount int) ([]interface{}, error) {
+	processedSQL, args, err := binder.BindParameters(selectKey.SQL, parameter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind selectKey parameters: %w", err)
+	}
+
+	rows, err := runner.Query(processedSQL, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute selectKey: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("selectKey returned no rows")
+	}
+
+	values := make([]interface{}, len(columns))
+	scanTargets := make([]interface{}, len(columns))
+	for i := range values {
+		scanTargets[i] = &values[i]
+	}
+	if err := rows.Scan(scanTargets...); err != nil {
+		return nil, fmt.Errorf("failed to scan selectKey result: %w", err)
+	}
+	if rows.Next() {
+		return nil, fmt.Errorf("selectKey returned more than one row")
+	}
+
+	// 未声明 keyColumn 时按位置对应主键属性
+	keyColumns := splitKeys(selectKey.KeyColumn)
+	if len(keyColumns) == 0 {
+		if len(values) < keyCount {
+			return nil, fmt.Errorf("selectKey returned %d columns for %d key properties", len(values), keyCount)
+		}
+		return values[:keyCount], nil
+	}
+	if len(keyColumns) != keyCount {
+		return nil, fmt.Errorf("selectKey keyColumn %s does not match keyProperty", selectKey.KeyColumn)
+	}
+
+	result := make([]interface{}, keyCount)
+	for i, keyColumn := range keyColumns {
+		found := false
+		for j, column := range columns {
+			if strings.EqualFold(column, keyColumn) {
+				result[i], found = values[j], true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("selectKey column %s not found in %v", keyColumn, columns)
+		}
+	}
+	return result, nil
+}
+
+// targetParameter 将回填目标转换为可绑定的参数
+func targetParameter(target reflect.Value) interface{} {
+	if target.Kind() == reflect.Struct && target.CanAddr() {
+		return target.Addr().Interface()
+	}
+	return target.Interface()
+}
+
+// convertKeyResult 按 resultType 转换主键查询结果，未知类型保持原值
+func convertKeyResult(value interface{}, resultType string) (interface{}, error) {
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	switch strings.ToLower(resultType) {
+	case "int", "int64", "long", "integer":
+		switch v := value.(type) {
+		case int64:
+			return v, nil
+		case string:
+			return strconv.ParseInt(v, 10, 64)
+		default:
+			rv := reflect.ValueOf(value)
+			if !isNumericKind(rv.Kind()) {
+				return nil, fmt.Errorf("cannot convert %T to %s", value, resultType)
+			}
+			return rv.Convert(reflect.TypeOf(int64(0))).Interface(), nil
+		}
+	case "string":
+		return fmt.Sprint(value), nil
+	default:
+		return value, nil
+	}
+}
+
+// GenerateKeys 在绑定参数前为 INSERT 填充客户端生成的主键：
+// 语句声明了 keyGenerator 时填充 keyProperty，另外填充带 db:"...,idgen=name" 标签的字段，仅处理零值字段
+func GenerateKeys(configuration *config.Configuration, statement *config.MapperStatement, parameter interface{}) error {
+	if statement.StatementType != config.INSERT || parameter == nil {
+		return nil
+	}
+
+	taggedFields := idgenFields(parameter)
+	if statement.KeyGenerator == "" && len(taggedFields) == 0 {
+		return nil
+	}
+
+	targets, err := keyTargets(parameter)
+	if err != nil {
+		return fmt.Errorf("statement %s: %w", statement.ID, err)
+	}
+
+	for _, target := range targets {
+		if statement.KeyGenerator != "" {
+			for _, property := range splitKeys(statement.KeyProperty) {
+				if err := generateKey(configuration, statement.KeyGenerator, target, property); err != nil {
+					return fmt.Errorf("statement %s: %w", statement.ID, err)
+				}
+			}
+		}
+
+		if target.Kind() != reflect.Struct {
+			continue
+		}
+		for _, field := range taggedFields {
+			fieldValue, ok := reflection.FieldByIndex(target, field.Index, true)
+			if !ok {
+				return fmt.Errorf("statement %s: %s of %s is not settable", statement.ID, field.Name, target.Type())
+			}
+			if !fieldValue.IsZero() {
+				continue
+			}
+			id, err := nextID(configuration, field.Tag.Option("idgen"))
+			if err != nil {
+				return fmt.Errorf("statement %s: %w", statement.ID, err)
+			}
+			if err := setKeyValue(fieldValue, id); err != nil {
+				return fmt.Errorf("statement %s: failed to set %s of %s: %w", statement.ID, field.Name, target.Type(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// idgenFields 获取参数结构体中声明了 idgen 选项的字段
+func idgenFields(parameter interface{}) []reflection.FieldInfo {
+	t := reflect.TypeOf(parameter)
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []reflection.FieldInfo
+	for _, field := range reflection.StructFields(t) {
+		if field.Tag.Option("idgen") != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// generateKey 为结构体字段或 Map 中值为零的主键属性生成主键
+func generateKey(configuration *config.Configuration, generatorName string, target reflect.Value, property string) error {
+	if target.Kind() == reflect.Map {
+		if current := target.MapIndex(reflect.ValueOf(property)); current.IsValid() && !isZeroValue(current) {
+			return nil
+		}
+	} else if target.Kind() == reflect.Struct {
+		if field, exists := target.Type().FieldByName(property); exists {
+			if current, ok := reflection.FieldByIndex(target, field.Index, false); ok && !current.IsZero() {
+				return nil
+			}
+		}
+	}
+
+	id, err := nextID(configuration, generatorName)
+	if err != nil {
+		return err
+	}
+	return setKeyProperty(target, property, id)
+}
+
+// isZeroValue 判断值是否为零值，接口按其动态值判断
+func isZeroValue(v reflect.Value) bool {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	return v.IsZero()
+}
+
+// nextID 使用指定名称的生成器生成主键
+func nextID(configuration *config.Configuration, generatorName string) (interface{}, error) {
+	generator, exists := configuration.GetIdGenerator(generatorName)
+	if !exists {
+		return nil, fmt.Errorf("id generator not found: %s", generatorName)
+	}
+	return generator.NextID()
+}
+
+// ExecUpdate 执行 INSERT、UPDATE、DELETE 并返回实际影响的行数，
+// 声明了 useGeneratedKeys 的 INSERT 会将生成的主键回填到参数
+func ExecUpdate(runner Runner, configuration *config.Configuration, statement *config.MapperStatement, query string, args []interface{}, parameter interface{}) (int64, error) {
+	if statement.StatementType != config.INSERT || !statement.UseGeneratedKeys || statement.KeyProperty == "" {
+		result, err := runner.Exec(query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return rowsAffected(statement, result)
+	}
+
+	keyProperties := splitKeys(statement.KeyProperty)
+	d := configuration.Dialect
+	if d == nil {
+		d = dialect.GenericDialect{}
+	}
+
+	// 支持 RETURNING 的数据库直接返回生成的列，多行插入时每行一条记录
+	if d.SupportsReturning() {
+		return execReturning(runner, configuration, statement, query, args, parameter, keyProperties)
+	}
+	if !d.SupportsLastInsertId() {
+		return 0, fmt.Errorf("statement %s: dialect %s does not support generated keys", statement.ID, d.Name())
+	}
+
+	if len(keyProperties) != 1 {
+		return 0, fmt.Errorf("statement %s: LastInsertId supports a single keyProperty, got %s", statement.ID, statement.KeyProperty)
+	}
+	targets, err := keyTargets(parameter)
+	if err != nil {
+		return 0, fmt.Errorf("statement %s: %w", statement.ID, err)
+	}
+	// 多行插入只有 LastInsertId 为第一行主键且后续主键连续时才能回填，
+	// 仅 MySQL 在 innodb_autoinc_lock_mode 小于 2 时保证这一点，其他方言执行前即拒绝
+	if len(targets) > 1 && d.Name() != (dialect.MySQLDialect{}).Name() {
+		return 0, fmt.Errorf("statement %s: dialect %s cannot return generated keys of a multi-row insert", statement.ID, d.Name())
+	}
+
+	result, err := runner.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := rowsAffected(statement, result)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("statement %s: failed to get generated key: %w", statement.ID, err)
+	}
+	// 多行插入时 LastInsertId 为第一行的主键，后续行按自增步长 1 依次递增
+	for i, target := range targets {
+		if err := setKeyProperty(target, keyProperties[0], id+int64(i)); err != nil {
+			return 0, fmt.Errorf("statement %s: %w", statement.ID, err)
+		}
+	}
+
+	return affected, nil
+}
+
+// rowsAffected 获取影响的行数，INSERT 已执行成功，驱动无法报告行数时返回 0 而不视为失败
+func rowsAffected(statement *config.MapperStatement, result sql.Result) (int64, error) {
+	affected, err := result.RowsAffected()
+	if err != nil && statement.StatementType == config.INSERT {
+		return 0, nil
+	}
+	return affected, err
+}
+
+// execReturning 追加 RETURNING 子句执行插入并回填主键，返回插入的行数
+func execReturning(runner Runner, configuration *config.Configuration, statement *config.MapperStatement, query string, args []interface{}, parameter interface{}, keyProperties []string) (int64, error) {
+	keyColumns := splitKeys(statement.KeyColumn)
+	if len(keyColumns) == 0 {
+		namingStrategy := configuration.NamingStrategy
+		if namingStrategy == nil {
+			namingStrategy = reflection.DefaultNamingStrategy
+		}
+		for _, property := range keyProperties {
+			keyColumns = append(keyColumns, namingStrategy.ColumnName(property))
+		}
+	}
+	if len(keyColumns) != len(keyProperties) {
+		return 0, fmt.Errorf("statement %s: keyColumn %s does not match keyProperty %s", statement.ID, statement.KeyColumn, statement.KeyProperty)
+	}
+
+	targets, err := keyTargets(parameter)
+	if err != nil {
+		return 0, fmt.Errorf("statement %s: %w", statement.ID, err)
+	}
+
+	if !dialect.HasTopLevelKeyword(query, "RETURNING") {
+		query = query + " RETURNING " + strings.Join(keyColumns, ", ")
+	}
+	rows, err := runner.Query(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var affected int64
+	values := make([]interface{}, len(keyColumns))
+	scanTargets := make([]interface{}, len(keyColumns))
+	for i := range values {
+		scanTargets[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(scanTargets...); err != nil {
+			return 0, fmt.Errorf("statement %s: failed to scan generated keys: %w", statement.ID, err)
+		}
+		if int(affected) < len(targets) {
+			for i, property := range keyProperties {
+				if err := setKeyProperty(targets[affected], property, values[i]); err != nil {
+					return 0, fmt.Errorf("statement %s: %w", statement.ID, err)
+				}
+			}
+		}
+		affected++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	return affected, nil
+}
+
+// splitKeys 拆分逗号分隔的主键属性或列名
+func splitKeys(keys string) []string {
+	var result []string
+	for _, key := range strings.Split(keys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+// keyTargets 获取需要回填主键的对象：结构体指针、Map，或切片中的每个元素
+func keyTargets(parameter interface{}) ([]reflect.Value, error) {
+	if parameter == nil {
+		return nil, nil
+	}
+
+	v := reflect.ValueOf(parameter)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		targets := make([]reflect.Value, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			element := v.Index(i)
+			if element.Kind() == reflect.Interface {
+				element = element.Elem()
+			}
+			if element.Kind() == reflect.Ptr {
+				element = element.Elem()
+			}
+			if element.Kind() == reflect.Struct && !element.CanSet() {
+				return nil, fmt.Errorf("cannot set generated key on element %d of %T, pass a pointer or a slice", i, parameter)
+			}
+			targets = append(targets, element)
+		}
+		return targets, nil
+	case reflect.Map:
+		return []reflect.Value{v}, nil
+	case reflect.Struct:
+		if !v.CanSet() {
+			return nil, fmt.Errorf("cannot set generated key on non-pointer parameter %T", parameter)
+		}
+		return []reflect.Value{v}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// setKeyProperty 将主键值写入结构体字段或 Map
+func setKeyProperty(target reflect.Value, property string, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	if target.Kind() == reflect.Map {
+		if target.Type().Key().Kind() != reflect.String || target.IsNil() {
+			return fmt.Errorf("cannot set generated key on %s", target.Type())
+		}
+		v := reflect.ValueOf(value)
+		if !v.Type().AssignableTo(target.Type().Elem()) {
+			return fmt.Errorf("cannot assign %s to %s", v.Type(), target.Type().Elem())
+		}
+		target.SetMapIndex(reflect.ValueOf(property), v)
+		return nil
+	}
+
+	if target.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot set generated key on %s", target.Type())
+	}
+	field, exists := target.Type().FieldByName(property)
+	if !exists || field.PkgPath != "" {
+		return fmt.Errorf("key property %s not found on %s", property, target.Type())
+	}
+	fieldValue, ok := reflection.FieldByIndex(target, field.Index, true)
+	if !ok {
+		return fmt.Errorf("key property %s of %s is not settable", property, target.Type())
+	}
+	if err := setKeyValue(fieldValue, value); err != nil {
+		return fmt.Errorf("failed to set key property %s of %s: %w", property, target.Type(), err)
+	}
+	return nil
+}
+
+// setKeyValue 按字段类型转换并设置主键值
+func setKeyValue(field reflect.Value, value interface{}) error {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return setKeyValue(field.Elem(), value)
+	}
+
+	if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(value)
+	}
+
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if str, ok := value.(string); ok {
+			i, err := strconv.ParseInt(str, 10, 64)
+			if err != nil {
+				return err
+			}
+			field.SetInt(i)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if str, ok := value.(string); ok {
+			u, err := strconv.ParseUint(str, 10, 64)
+			if err != nil {
+				return err
+			}
+			field.SetUint(u)
+			return nil
+		}
+	case reflect.String:
+		if stringer, ok := value.(fmt.Stringer); ok {
+			field.SetString(stringer.String())
+		} else {
+			field.SetString(fmt.Sprint(value))
+		}
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	if isNumericKind(v.Kind()) && isNumericKind(field.Kind()) {
+		field.Set(v.Convert(field.Type()))
+		return nil
+	}
+	if v.Type().AssignableTo(field.Type()) {
+		field.Set(v)
+		return nil
+	}
+	// 底层类型相同的值，如 idgen.UUID 写入 [16]byte 字段
+	if v.Kind() == field.Kind() && v.Type().ConvertibleTo(field.Type()) {
+		field.Set(v.Convert(field.Type()))
+		return nil
+	}
+	return fmt.Errorf("cannot assign %s to %s", v.Type(), field.Type())
+}
+
+// isNumericKind 判断是否为数字类型
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,172 @@
+package executor
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"gobatis/core/config"
+)
+
+// preparer 可创建预编译语句的连接或事务，*sql.DB、*sql.Tx 均满足
+type preparer interface {
+	Prepare(query string) (*sql.Stmt, error)
+}
+
+// Prepare 实现 preparer，语句绑定在固定的连接上
+func (r connRunner) Prepare(query string) (*sql.Stmt, error) {
+	return r.conn.PrepareContext(context.Background(), query)
+}
+
+// cachedStatement LRU 链表中的缓存项
+type cachedStatement struct {
+	sql  string
+	stmt *sql.Stmt
+}
+
+// statementCache 按 SQL 文本缓存预编译语句，超出容量时关闭最久未使用的语句
+type statementCache struct {
+	mu       sync.Mutex
+	preparer preparer
+	capacity int
+	lru      *list.List
+	items    map[string]*list.Element
+}
+
+// newStatementCache 创建预编译语句缓存，capacity 不大于 0 时使用默认容量
+func newStatementCache(p preparer, capacity int) *statementCache {
+	if capacity <= 0 {
+		capacity = config.DefaultStatementCacheSize
+	}
+	return &statementCache{
+		preparer: p,
+		capacity: capacity,
+		lru:      list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// get 获取 SQL 对应的预编译语句，未缓存时预编译并加入缓存
+func (c *statementCache) get(query string) (*sql.Stmt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, exists := c.items[query]; exists {
+		c.lru.MoveToFront(elem)
+		return elem.Value.(*cachedStatement).stmt, nil
+	}
+
+	stmt, err := c.preparer.Prepare(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	c.items[query] = c.lru.PushFront(&cachedStatement{sql: query, stmt: stmt})
+
+	// 淘汰最久未使用的语句，仍在读取的结果集会在关闭后再释放语句
+	for c.lru.Len() > c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		cached := oldest.Value.(*cachedStatement)
+		delete(c.items, cached.sql)
+		cached.stmt.Close()
+	}
+
+	return stmt, nil
+}
+
+// len 当前缓存的语句数量
+func (c *statementCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// close 关闭并清空所有缓存的语句，返回第一个关闭错误
+func (c *statementCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var firstErr error
+	for elem := c.lru.Front(); elem != nil; elem = elem.Next() {
+		if err := elem.Value.(*cachedStatement).stmt.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	c.lru.Init()
+	c.items = make(map[string]*list.Element)
+	return firstErr
+}
+
+// statementRunner 通过缓存的预编译语句执行 SQL 的 Runner
+type statementRunner struct {
+	cache *statementCache
+}
+
+// Exec 实现 Runner
+func (r *statementRunner) Exec(query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := r.cache.get(query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Exec(args...)
+}
+
+// Query 实现 Runner
+func (r *statementRunner) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, err := r.cache.get(query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Query(args...)
+}
+
+// pin 将语句固定到单个连接上执行，该连接使用独立的语句缓存，释放连接时一并关闭
+func (r *statementRunner) pin() (Runner, func(), error) {
+	db, ok := r.cache.preparer.(*sql.DB)
+	if !ok {
+		return r, func() {}, nil
+	}
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	pinned := &statementRunner{cache: newStatementCache(connRunner{conn: conn}, r.cache.capacity)}
+	return pinned, func() {
+		pinned.cache.close()
+		conn.Close()
+	}, nil
+}
+
+// ReuseExecutor 复用预编译语句的执行器，会话内相同 SQL 只预编译一次，
+// 缓存容量由 Configuration.StatementCacheSize 控制，关闭执行器时释放所有语句
+type ReuseExecutor struct {
+	baseExecutor
+	statements *statementCache
+}
+
+// NewReuseExecutor 创建复用执行器
+func NewReuseExecutor(configuration *config.Configuration) Executor {
+	e := &ReuseExecutor{baseExecutor: newBaseExecutor(configuration)}
+	e.runner = e.statementRunner
+	return e
+}
+
+// statementRunner 获取基于语句缓存的 Runner，首次使用时创建缓存
+func (e *ReuseExecutor) statementRunner() Runner {
+	if e.statements == nil {
+		e.statements = newStatementCache(e.configuration.DataSource.DB, e.configuration.StatementCacheSize)
+	}
+	return &statementRunner{cache: e.statements}
+}
+
+// Close 实现 Executor，关闭所有缓存的预编译语句
+func (e *ReuseExecutor) Close() error {
+	e.ClearLocalCache()
+	if e.statements == nil {
+		return nil
+	}
+	return e.statements.close()
+}
@@ -0,0 +1,130 @@
+package executor
+
+import (
+	"reflect"
+	"testing"
+
+	"gobatis/core/config"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestNewExecutor 测试按类型创建执行器
+func TestNewExecutor(t *testing.T) {
+	configuration := &config.Configuration{}
+
+	if _, ok := NewExecutor(configuration, config.ExecutorSimple).(*SimpleExecutor); !ok {
+		t.Error("ExecutorSimple should create SimpleExecutor")
+	}
+	if _, ok := NewExecutor(configuration, config.ExecutorReuse).(*ReuseExecutor); !ok {
+		t.Error("ExecutorReuse should create ReuseExecutor")
+	}
+}
+
+// TestReuseExecutor_ReusesStatements 测试相同 SQL 只预编译一次，关闭执行器时关闭语句
+func TestReuseExecutor_ReusesStatements(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	configuration := &config.Configuration{
+		DataSource: &config.DataSource{DB: db},
+	}
+	executor := NewReuseExecutor(configuration)
+
+	selectStmt := &config.MapperStatement{
+		ID:            "TestMapper.GetUser",
+		SQL:           "SELECT id, username FROM users WHERE id = #{id}",
+		ResultType:    reflect.TypeOf(TestUser{}),
+		StatementType: config.SELECT,
+	}
+	updateStmt := &config.MapperStatement{
+		ID:            "TestMapper.UpdateUser",
+		SQL:           "UPDATE users SET username = #{username} WHERE id = #{id}",
+		StatementType: config.UPDATE,
+	}
+
+	query := mock.ExpectPrepare("SELECT id, username FROM users WHERE id = \\?")
+	query.ExpectQuery().WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(1, "john"))
+	query.ExpectQuery().WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(2, "jane"))
+	update := mock.ExpectPrepare("UPDATE users SET username = \\? WHERE id = \\?")
+	update.ExpectExec().WithArgs("john", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	update.ExpectExec().WithArgs("jane", 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	query.WillBeClosed()
+	update.WillBeClosed()
+
+	for _, id := range []int{1, 2} {
+		results, err := executor.Query(selectStmt, map[string]interface{}{"id": id})
+		if err != nil {
+			t.Fatalf("Query should not return error: %v", err)
+		}
+		if len(results) != 1 || results[0].(TestUser).ID != id {
+			t.Fatalf("Unexpected results: %+v", results)
+		}
+	}
+
+	for _, user := range []TestUser{{ID: 1, Username: "john"}, {ID: 2, Username: "jane"}} {
+		affected, err := executor.Update(updateStmt, user)
+		if err != nil {
+			t.Fatalf("Update should not return error: %v", err)
+		}
+		if affected != 1 {
+			t.Fatalf("Expected 1 affected row, got %d", affected)
+		}
+	}
+
+	if n := executor.(*ReuseExecutor).statements.len(); n != 2 {
+		t.Fatalf("Expected 2 cached statements, got %d", n)
+	}
+
+	if err := executor.Close(); err != nil {
+		t.Fatalf("Close should not return error: %v", err)
+	}
+	if n := executor.(*ReuseExecutor).statements.len(); n != 0 {
+		t.Fatalf("Expected empty cache after close, got %d", n)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestStatementCache_Eviction 测试超出容量时关闭最久未使用的语句
+func TestStatementCache_Eviction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	cache := newStatementCache(db, 2)
+
+	mock.ExpectPrepare("SELECT 1")
+	mock.ExpectPrepare("SELECT 2").WillBeClosed()
+	mock.ExpectPrepare("SELECT 3")
+
+	for _, query := range []string{"SELECT 1", "SELECT 2", "SELECT 1", "SELECT 3"} {
+		if _, err := cache.get(query); err != nil {
+			t.Fatalf("get %q should not return error: %v", query, err)
+		}
+	}
+
+	// SELECT 1 最近被访问，淘汰的是 SELECT 2
+	if _, exists := cache.items["SELECT 2"]; exists {
+		t.Error("SELECT 2 should be evicted")
+	}
+	if _, exists := cache.items["SELECT 1"]; !exists {
+		t.Error("SELECT 1 should stay cached")
+	}
+	if cache.len() != 2 {
+		t.Errorf("Expected 2 cached statements, got %d", cache.len())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package executor
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gobatis/binding"
+	"gobatis/core/config"
+	"gobatis/dialect"
+	"gobatis/reflection"
+)
+
+// insertTargetPattern 匹配 INSERT INTO table (columns) VALUES 前缀
+var insertTargetPattern = regexp.MustCompile(`(?is)^\s*INSERT\s+INTO\s+([^\s(]+)\s*\(([^)]*)\)\s*VALUES\s*$`)
+
+// Upsert 批量插入，与 conflictKeys 冲突的行更新 updateColumns，返回累计影响的行数。
+// target 为 INSERT 语句 ID 时沿用其表、列和参数表达式，否则视为表名并按第一行推断列；
+// updateColumns 为 nil 时更新除冲突键外的所有列，为空切片时冲突行保持不变；
+// 行数超出方言的参数上限时分多条语句执行，未处于事务中时这些语句在同一事务中执行，任一条失败时全部回滚
+func (e *baseExecutor) Upsert(target string, rows interface{}, conflictKeys, updateColumns []string) (int64, error) {
+	begin := time.Now()
+	e.ClearLocalCache()
+
+	d := e.configuration.Dialect
+	if d == nil {
+		d = dialect.GenericDialect{}
+	}
+	if len(conflictKeys) == 0 {
+		return 0, fmt.Errorf("upsert %s: conflict keys are required", target)
+	}
+
+	elements := reflect.ValueOf(rows)
+	for elements.Kind() == reflect.Ptr {
+		elements = elements.Elem()
+	}
+	if elements.Kind() != reflect.Slice && elements.Kind() != reflect.Array {
+		return 0, fmt.Errorf("upsert %s: rows must be a slice, got %T", target, rows)
+	}
+	if elements.Len() == 0 {
+		return 0, nil
+	}
+
+	table, columns, tuple, err := e.upsertTarget(target, elements.Index(0).Interface())
+	if err != nil {
+		return 0, fmt.Errorf("upsert %s: %w", target, err)
+	}
+	if updateColumns == nil {
+		updateColumns = excludeColumns(columns, conflictKeys)
+	}
+
+	// 每行按元组模板绑定参数，复用语句的参数表达式与类型处理器
+	plan := binding.NewPlan(tuple)
+	args := make([]interface{}, 0, elements.Len()*len(columns))
+	for i := 0; i < elements.Len(); i++ {
+		_, rowArgs, err := bindPlan(e.parameterBinder, plan, rowParameter(elements.Index(i)))
+		if err != nil {
+			return 0, fmt.Errorf("upsert %s: failed to bind row %d: %w", target, i, err)
+		}
+		if len(rowArgs) != len(columns) {
+			return 0, fmt.Errorf("upsert %s: expected %d parameters per row, got %d", target, len(columns), len(rowArgs))
+		}
+		args = append(args, rowArgs...)
+	}
+
+	rowsPerStatement := d.MaxBindParameters() / len(columns)
+	if rowsPerStatement < 1 {
+		return 0, fmt.Errorf("upsert %s: %d columns exceed the bind parameter limit of %s", target, len(columns), d.Name())
+	}
+
+	runner := e.runner()
+	var tx *sql.Tx
+	if _, inTransaction := runner.(*sql.Tx); !inTransaction && elements.Len() > rowsPerStatement {
+		if tx, err = e.configuration.DataSource.DB.Begin(); err != nil {
+			return 0, fmt.Errorf("upsert %s: failed to begin transaction: %w", target, err)
+		}
+		defer tx.Rollback()
+		runner = tx
+	}
+
+	var affected int64
+	for start := 0; start < elements.Len(); start += rowsPerStatement {
+		end := min(start+rowsPerStatement, elements.Len())
+		query, err := d.UpsertSQL(table, columns, end-start, conflictKeys, updateColumns)
+		if err != nil {
+			return 0, fmt.Errorf("upsert %s: %w", target, err)
+		}
+
+		chunkArgs := args[start*len(columns) : end*len(columns)]
+		result, err := runner.Exec(query, chunkArgs...)
+		if err != nil {
+			e.trace(begin, func() (string, int64) {
+				return fmt.Sprintf("%s [ARGS: %v]", query, chunkArgs), -1
+			}, err)
+			return 0, fmt.Errorf("upsert %s: failed to execute rows %d-%d: %w", target, start, end-1, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("upsert %s: failed to get affected rows: %w", target, err)
+		}
+		affected += n
+	}
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return 0, fmt.Errorf("upsert %s: failed to commit transaction: %w", target, err)
+		}
+	}
+
+	e.trace(begin, func() (string, int64) {
+		return fmt.Sprintf("UPSERT %s [ROWS: %d]", table, elements.Len()), affected
+	}, nil)
+
+	return affected, nil
+}
+
+// rowParameter 获取行的绑定参数，键为字符串的 Map 转换为 map[string]interface{}
+func rowParameter(row reflect.Value) interface{} {
+	for row.Kind() == reflect.Interface {
+		row = row.Elem()
+	}
+	if row.Kind() != reflect.Map || row.Type().Key().Kind() != reflect.String {
+		return row.Interface()
+	}
+	if params, ok := row.Interface().(map[string]interface{}); ok {
+		return params
+	}
+	params := make(map[string]interface{}, row.Len())
+	iter := row.MapRange()
+	for iter.Next() {
+		params[iter.Key().String()] = iter.Value().Interface()
+	}
+	return params
+}
+
+// upsertTarget 解析表名、列和单行参数模板
+func (e *baseExecutor) upsertTarget(target string, row interface{}) (string, []string, string, error) {
+	if statement, exists := e.configuration.GetMapperStatement(target); exists {
+		if statement.StatementType != config.INSERT {
+			return "", nil, "", fmt.Errorf("statement is not an insert statement")
+		}
+		prefix, tuple, ok := splitInsertValues(statement.SQL)
+		if !ok {
+			return "", nil, "", fmt.Errorf("statement must be a single-row INSERT INTO table (columns) VALUES (...)")
+		}
+		match := insertTargetPattern.FindStringSubmatch(prefix)
+		if match == nil {
+			return "", nil, "", fmt.Errorf("statement must list its columns")
+		}
+		columns := strings.Split(match[2], ",")
+		for i := range columns {
+			columns[i] = strings.TrimSpace(columns[i])
+		}
+		return match[1], columns, tuple, nil
+	}
+
+	columns, err := e.rowColumns(row)
+	if err != nil {
+		return "", nil, "", err
+	}
+	expressions := make([]string, len(columns))
+	for i, column := range columns {
+		expressions[i] = "#{" + column + "}"
+	}
+	return target, columns, "(" + strings.Join(expressions, ", ") + ")", nil
+}
+
+// rowColumns 按行推断列：结构体按 db 标签或命名策略，map 按键排序
+func (e *baseExecutor) rowColumns(row interface{}) ([]string, error) {
+	v := reflect.ValueOf(row)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, fmt.Errorf("row must not be nil")
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		naming := e.configuration.NamingStrategy
+		if naming == nil {
+			naming = reflection.DefaultNamingStrategy
+		}
+		var columns []string
+		for _, field := range reflection.StructFields(v.Type()) {
+			column := field.Tag.Name
+			if column == "" {
+				column = naming.ColumnName(field.Name)
+			}
+			columns = append(columns, field.Prefix+column)
+		}
+		return columns, nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("map rows must have string keys")
+		}
+		columns := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			columns = append(columns, key.String())
+		}
+		sort.Strings(columns)
+		return columns, nil
+	default:
+		return nil, fmt.Errorf("row must be a struct or map, got %s", v.Type())
+	}
+}
+
+// excludeColumns 返回不在 excluded 中的列
+func excludeColumns(columns, excluded []string) []string {
+	result := make([]string, 0, len(columns))
+	for _, column := range columns {
+		skip := false
+		for _, key := range excluded {
+			if strings.EqualFold(column, key) {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			result = append(result, column)
+		}
+	}
+	return result
+}
@@ -0,0 +1,161 @@
+package executor
+
+import (
+	"errors"
+	"testing"
+
+	"gobatis/core/config"
+	"gobatis/dialect"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// smallBatchDialect 参数上限很小的 PostgreSQL 方言，用于测试分块
+type smallBatchDialect struct {
+	dialect.PostgreSQLDialect
+}
+
+func (smallBatchDialect) MaxBindParameters() int { return 4 }
+
+// newUpsertTestExecutor 创建基于模拟数据库的简单执行器
+func newUpsertTestExecutor(t *testing.T, d dialect.Dialect) (Executor, *config.Configuration, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	configuration := config.NewConfiguration()
+	configuration.DataSource = &config.DataSource{DB: db}
+	configuration.Dialect = d
+	return NewSimpleExecutor(configuration), configuration, mock
+}
+
+// TestUpsert_Table 测试按表名与结构体字段生成 upsert 并按参数上限分块，各块在同一事务中执行
+func TestUpsert_Table(t *testing.T) {
+	executor, _, mock := newUpsertTestExecutor(t, smallBatchDialect{})
+
+	users := []TestUser{{ID: 1, Username: "a"}, {ID: 2, Username: "b"}, {ID: 3, Username: "c"}}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^INSERT INTO users \(id, username\) VALUES \(\?, \?\), \(\?, \?\) `+
+		`ON CONFLICT \(id\) DO UPDATE SET username = EXCLUDED.username$`).
+		WithArgs(1, "a", 2, "b").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`^INSERT INTO users \(id, username\) VALUES \(\?, \?\) ON CONFLICT`).
+		WithArgs(3, "c").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	affected, err := executor.Upsert("users", users, []string{"id"}, nil)
+	if err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	if affected != 3 {
+		t.Errorf("Expected 3 affected rows, got %d", affected)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Mock expectations were not met: %v", err)
+	}
+}
+
+// TestUpsert_PartialFailure 测试分块执行失败时回滚已执行的块
+func TestUpsert_PartialFailure(t *testing.T) {
+	executor, _, mock := newUpsertTestExecutor(t, smallBatchDialect{})
+
+	users := []TestUser{{ID: 1, Username: "a"}, {ID: 2, Username: "b"}, {ID: 3, Username: "c"}}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^INSERT INTO users`).
+		WithArgs(1, "a", 2, "b").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`^INSERT INTO users`).
+		WithArgs(3, "c").
+		WillReturnError(errors.New("deadlock detected"))
+	mock.ExpectRollback()
+
+	affected, err := executor.Upsert("users", users, []string{"id"}, nil)
+	if err == nil {
+		t.Fatal("Expected error when a chunk fails")
+	}
+	if affected != 0 {
+		t.Errorf("Expected 0 affected rows after rollback, got %d", affected)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Mock expectations were not met: %v", err)
+	}
+}
+
+// TestUpsert_StringMapRows 测试值类型不是 interface{} 的 Map 行
+func TestUpsert_StringMapRows(t *testing.T) {
+	executor, _, mock := newUpsertTestExecutor(t, dialect.PostgreSQLDialect{})
+
+	rows := []map[string]string{{"code": "a", "name": "A"}}
+
+	mock.ExpectExec(`^INSERT INTO tags \(code, name\) VALUES \(\?, \?\) ON CONFLICT \(code\) DO UPDATE SET name = EXCLUDED.name$`).
+		WithArgs("a", "A").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if _, err := executor.Upsert("tags", rows, []string{"code"}, nil); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Mock expectations were not met: %v", err)
+	}
+}
+
+// TestUpsert_Statement 测试沿用 INSERT 语句的表、列和参数表达式
+func TestUpsert_Statement(t *testing.T) {
+	executor, configuration, mock := newUpsertTestExecutor(t, dialect.MySQLDialect{})
+	configuration.MapperConfig.Mappers["UserMapper.Insert"] = &config.MapperStatement{
+		ID:            "UserMapper.Insert",
+		SQL:           "INSERT INTO users (id, username) VALUES (#{id}, #{username})",
+		StatementType: config.INSERT,
+	}
+
+	rows := []map[string]interface{}{
+		{"id": 1, "username": "a"},
+		{"id": 2, "username": "b"},
+	}
+
+	// MySQL 中更新的行计为 2
+	mock.ExpectExec(`^INSERT INTO users \(id, username\) VALUES \(\?, \?\), \(\?, \?\) ON DUPLICATE KEY UPDATE username = VALUES\(username\)$`).
+		WithArgs(1, "a", 2, "b").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	affected, err := executor.Upsert("UserMapper.Insert", rows, []string{"id"}, []string{"username"})
+	if err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	if affected != 3 {
+		t.Errorf("Expected 3 affected rows, got %d", affected)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Mock expectations were not met: %v", err)
+	}
+}
+
+// TestUpsert_Errors 测试 upsert 的参数校验
+func TestUpsert_Errors(t *testing.T) {
+	executor, configuration, _ := newUpsertTestExecutor(t, dialect.GenericDialect{})
+	users := []TestUser{{ID: 1, Username: "a"}}
+
+	if _, err := executor.Upsert("users", users, []string{"id"}, nil); !errors.Is(err, dialect.ErrUpsertNotSupported) {
+		t.Errorf("Expected ErrUpsertNotSupported, got %v", err)
+	}
+
+	configuration.Dialect = dialect.PostgreSQLDialect{}
+	if _, err := executor.Upsert("users", users, nil, nil); err == nil {
+		t.Error("Expected error without conflict keys")
+	}
+	if _, err := executor.Upsert("users", TestUser{}, []string{"id"}, nil); err == nil {
+		t.Error("Expected error for non-slice rows")
+	}
+	if affected, err := executor.Upsert("users", []TestUser{}, []string{"id"}, nil); err != nil || affected != 0 {
+		t.Errorf("Expected no-op for empty rows, got %d, %v", affected, err)
+	}
+}
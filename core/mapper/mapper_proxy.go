@@ -5,6 +5,8 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+
+	"gobatis/plugins"
 )
 
 // SqlSession SQL 会话接口（避免循环导入）
@@ -255,7 +257,16 @@ func (mp *MapperProxy) invoke(methodName string, methodType reflect.Type, args [
 	var err error
 
 	// 根据方法名判断操作类型
-	if mp.isSelectMethod(methodName, methodType) {
+	if mp.isSelectPageMethod(methodType) {
+		// 分页插件返回 *PageResult[interface{}]，转换为方法声明的分页类型
+		var page interface{}
+		if page, err = mp.session.SelectOne(statementId, parameter); err == nil {
+			var converted reflect.Value
+			if converted, err = plugins.ConvertPage(page, methodType.Out(0)); err == nil {
+				result = converted.Interface()
+			}
+		}
+	} else if mp.isSelectMethod(methodName, methodType) {
 		if mp.isSelectListMethod(methodType) {
 			result, err = mp.session.SelectList(statementId, parameter)
 		} else {
@@ -366,6 +377,11 @@ func (mp *MapperProxy) isSelectListMethod(methodType reflect.Type) bool {
 	return false
 }
 
+// isSelectPageMethod 判断是否为分页查询方法，即返回 PageResult[T] 或 Page[T]
+func (mp *MapperProxy) isSelectPageMethod(methodType reflect.Type) bool {
+	return methodType.NumOut() > 0 && plugins.IsPageType(methodType.Out(0))
+}
+
 // isInsertMethod 判断是否为插入方法
 func (mp *MapperProxy) isInsertMethod(methodName string) bool {
 	methodNameLower := strings.ToLower(methodName)
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+
+	"gobatis/plugins"
 )
 
 // MockSqlSession 模拟SQL会话
@@ -303,6 +305,53 @@ func TestMapperProxy_SelectList(t *testing.T) {
 	}
 }
 
+// pageUser 分页测试的行类型
+type pageUser struct {
+	Name string
+}
+
+// PageMapper 返回分页结果的Mapper接口
+type PageMapper interface {
+	ListUsers(status string, page *plugins.PageRequest) (*plugins.PageResult[pageUser], error)
+	SearchUsers(page *plugins.PageRequest) (plugins.Page[*pageUser], error)
+}
+
+// TestMapperProxy_SelectPage 测试返回分页结果的方法
+func TestMapperProxy_SelectPage(t *testing.T) {
+	pageReq := &plugins.PageRequest{Page: 1, Size: 2}
+	session := &MockSqlSession{
+		selectOneResult: plugins.NewPageResult([]interface{}{&pageUser{Name: "a"}, &pageUser{Name: "b"}}, 3, pageReq),
+	}
+	mp := &MapperProxy{session: session, mapperType: reflect.TypeOf((*PageMapper)(nil)).Elem()}
+
+	method, _ := mp.mapperType.MethodByName("ListUsers")
+	results := mp.invoke("ListUsers", method.Type, []reflect.Value{reflect.ValueOf("active"), reflect.ValueOf(pageReq)})
+	if !results[1].IsNil() {
+		t.Fatalf("Unexpected error: %v", results[1].Interface())
+	}
+	page := results[0].Interface().(*plugins.PageResult[pageUser])
+	if len(page.Data) != 2 || page.Data[1].Name != "b" || page.Total != 3 || !page.HasNext {
+		t.Errorf("Unexpected page: %+v", page)
+	}
+	params := session.lastParameter.(map[string]interface{})
+	if params["param1"] != "active" || params["param2"] != pageReq {
+		t.Errorf("Unexpected parameter: %v", session.lastParameter)
+	}
+
+	method, _ = mp.mapperType.MethodByName("SearchUsers")
+	results = mp.invoke("SearchUsers", method.Type, []reflect.Value{reflect.ValueOf(pageReq)})
+	if value := results[0].Interface().(plugins.Page[*pageUser]); len(value.Data) != 2 || value.Data[0].Name != "a" {
+		t.Errorf("Unexpected page: %+v", value)
+	}
+
+	// 未安装分页插件时返回的不是分页结果
+	session.selectOneResult = &pageUser{Name: "a"}
+	results = mp.invoke("SearchUsers", method.Type, []reflect.Value{reflect.ValueOf(pageReq)})
+	if results[1].IsNil() {
+		t.Error("Expected error when the result is not a page")
+	}
+}
+
 // TestMapperProxy_Insert 测试插入操作
 func TestMapperProxy_Insert(t *testing.T) {
 	session := &MockSqlSession{
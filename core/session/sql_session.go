@@ -8,6 +8,7 @@ import (
 	"gobatis/core/config"
 	"gobatis/core/executor"
 	"gobatis/core/mapper"
+	"gobatis/plugins"
 )
 
 // SqlSession SQL 会话接口
@@ -17,7 +18,13 @@ type SqlSession interface {
 	Insert(statementId string, parameter interface{}) (int64, error)
 	Update(statementId string, parameter interface{}) (int64, error)
 	Delete(statementId string, parameter interface{}) (int64, error)
+	// Upsert 批量插入，与 conflictKeys 冲突的行改为更新 updateColumns，target 为 INSERT 语句 ID 或表名
+	Upsert(target string, rows interface{}, conflictKeys, updateColumns []string) (int64, error)
 	GetMapper(mapperType interface{}) interface{}
+	// FlushStatements 执行批量会话中累计的语句，非批量会话返回 nil
+	FlushStatements() ([]executor.BatchResult, error)
+	// ClearCache 清空会话的一级缓存
+	ClearCache()
 	Commit() error
 	Rollback() error
 	Close() error
@@ -32,9 +39,17 @@ type DefaultSqlSession struct {
 	closed        bool
 }
 
-// NewSqlSession 创建新的 SQL 会话
+// NewSqlSession 创建新的 SQL 会话，使用配置的默认执行器类型，
+// Configuration.Plugins 中的插件拦截执行器的各阶段
 func NewSqlSession(configuration *config.Configuration, autoCommit bool) SqlSession {
-	exec := executor.NewSimpleExecutor(configuration)
+	// 执行器只创建一次，Reuse 与 Batch 执行器持有的资源随会话关闭
+	var exec executor.Executor
+	if len(configuration.Plugins) > 0 {
+		manager := plugins.NewPluginManagerWithConfiguration(configuration)
+		exec = executor.NewExecutorWithInterceptor(configuration, configuration.DefaultExecutorType, manager)
+	} else {
+		exec = executor.NewExecutor(configuration, configuration.DefaultExecutorType)
+	}
 	return &DefaultSqlSession{
 		configuration: configuration,
 		executor:      exec,
@@ -142,6 +157,15 @@ func (s *DefaultSqlSession) Delete(statementId string, parameter interface{}) (i
 	return s.executor.Update(stmt, parameter)
 }
 
+// Upsert 批量插入或更新，生成的语句取决于配置的方言，返回累计影响的行数
+func (s *DefaultSqlSession) Upsert(target string, rows interface{}, conflictKeys, updateColumns []string) (int64, error) {
+	if s.closed {
+		return 0, fmt.Errorf("session is closed")
+	}
+
+	return s.executor.Upsert(target, rows, conflictKeys, updateColumns)
+}
+
 // GetMapper 获取 Mapper 代理
 func (s *DefaultSqlSession) GetMapper(mapperType interface{}) interface{} {
 	if s.closed {
@@ -162,12 +186,39 @@ func (s *DefaultSqlSession) GetMapper(mapperType interface{}) interface{} {
 	return mapper.NewMapperProxy(s, t)
 }
 
-// Commit 提交事务
+// FlushStatements 执行批量会话中累计的语句，自动提交的会话随后提交批量事务
+func (s *DefaultSqlSession) FlushStatements() ([]executor.BatchResult, error) {
+	if s.closed {
+		return nil, fmt.Errorf("session is closed")
+	}
+
+	results, err := s.executor.FlushStatements()
+	if err != nil {
+		return nil, err
+	}
+	if s.autoCommit {
+		if err := s.executor.Commit(); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// ClearCache 清空会话的一级缓存
+func (s *DefaultSqlSession) ClearCache() {
+	s.executor.ClearLocalCache()
+}
+
+// Commit 提交事务，批量会话先执行累计的语句
 func (s *DefaultSqlSession) Commit() error {
 	if s.closed {
 		return fmt.Errorf("session is closed")
 	}
 
+	if err := s.executor.Commit(); err != nil {
+		return err
+	}
+
 	if s.tx != nil {
 		err := s.tx.Commit()
 		s.tx = nil
@@ -177,12 +228,16 @@ func (s *DefaultSqlSession) Commit() error {
 	return nil
 }
 
-// Rollback 回滚事务
+// Rollback 回滚事务，批量会话丢弃累计的语句
 func (s *DefaultSqlSession) Rollback() error {
 	if s.closed {
 		return fmt.Errorf("session is closed")
 	}
 
+	if err := s.executor.Rollback(); err != nil {
+		return err
+	}
+
 	if s.tx != nil {
 		err := s.tx.Rollback()
 		s.tx = nil
@@ -192,7 +247,7 @@ func (s *DefaultSqlSession) Rollback() error {
 	return nil
 }
 
-// Close 关闭会话
+// Close 关闭会话，批量会话中未提交的语句会被丢弃
 func (s *DefaultSqlSession) Close() error {
 	if s.closed {
 		return nil
@@ -204,5 +259,5 @@ func (s *DefaultSqlSession) Close() error {
 	}
 
 	s.closed = true
-	return nil
+	return s.executor.Close()
 }
@@ -6,6 +6,9 @@ import (
 	"testing"
 
 	"gobatis/core/config"
+	"gobatis/core/executor"
+
+	"github.com/DATA-DOG/go-sqlmock"
 )
 
 // MockExecutor 模拟执行器
@@ -14,6 +17,10 @@ type MockExecutor struct {
 	queryError   error
 	updateResult int64
 	updateError  error
+	flushed      bool
+	committed    bool
+	rolledBack   bool
+	closed       bool
 }
 
 func (m *MockExecutor) Query(stmt *config.MapperStatement, parameter interface{}) ([]interface{}, error) {
@@ -30,6 +37,35 @@ func (m *MockExecutor) Update(stmt *config.MapperStatement, parameter interface{
 	return m.updateResult, nil
 }
 
+func (m *MockExecutor) Upsert(target string, rows interface{}, conflictKeys, updateColumns []string) (int64, error) {
+	if m.updateError != nil {
+		return 0, m.updateError
+	}
+	return m.updateResult, nil
+}
+
+func (m *MockExecutor) FlushStatements() ([]executor.BatchResult, error) {
+	m.flushed = true
+	return nil, nil
+}
+
+func (m *MockExecutor) Commit() error {
+	m.committed = true
+	return nil
+}
+
+func (m *MockExecutor) Rollback() error {
+	m.rolledBack = true
+	return nil
+}
+
+func (m *MockExecutor) ClearLocalCache() {}
+
+func (m *MockExecutor) Close() error {
+	m.closed = true
+	return nil
+}
+
 func TestNewSqlSession(t *testing.T) {
 	cfg := config.NewConfiguration()
 	session := NewSqlSession(cfg, true)
@@ -54,6 +90,21 @@ func TestNewSqlSession(t *testing.T) {
 	if defaultSession.closed != false {
 		t.Error("Expected closed to be false")
 	}
+
+	caching, ok := defaultSession.executor.(*executor.CachingExecutor)
+	if !ok {
+		t.Fatalf("Expected CachingExecutor when cache is enabled, got %T", defaultSession.executor)
+	}
+	if _, ok := caching.Delegate().(*executor.SimpleExecutor); !ok {
+		t.Errorf("Expected SimpleExecutor by default, got %T", caching.Delegate())
+	}
+
+	cfg.DefaultExecutorType = config.ExecutorReuse
+	cfg.CacheEnabled = false
+	defaultSession = NewSqlSession(cfg, true).(*DefaultSqlSession)
+	if _, ok := defaultSession.executor.(*executor.ReuseExecutor); !ok {
+		t.Errorf("Expected ReuseExecutor from DefaultExecutorType, got %T", defaultSession.executor)
+	}
 }
 
 func TestDefaultSqlSession_SelectOne(t *testing.T) {
@@ -333,9 +384,10 @@ func TestDefaultSqlSession_GetMapper(t *testing.T) {
 
 func TestDefaultSqlSession_Commit(t *testing.T) {
 	cfg := config.NewConfiguration()
+	mockExecutor := &MockExecutor{}
 	session := &DefaultSqlSession{
 		configuration: cfg,
-		executor:      &MockExecutor{},
+		executor:      mockExecutor,
 		autoCommit:    true,
 		closed:        false,
 	}
@@ -355,13 +407,18 @@ func TestDefaultSqlSession_Commit(t *testing.T) {
 	if err != nil {
 		t.Errorf("Expected no error, got: %v", err)
 	}
+
+	if !mockExecutor.committed {
+		t.Error("Expected executor to be committed")
+	}
 }
 
 func TestDefaultSqlSession_Rollback(t *testing.T) {
 	cfg := config.NewConfiguration()
+	mockExecutor := &MockExecutor{}
 	session := &DefaultSqlSession{
 		configuration: cfg,
-		executor:      &MockExecutor{},
+		executor:      mockExecutor,
 		autoCommit:    true,
 		closed:        false,
 	}
@@ -381,13 +438,75 @@ func TestDefaultSqlSession_Rollback(t *testing.T) {
 	if err != nil {
 		t.Errorf("Expected no error, got: %v", err)
 	}
+
+	if !mockExecutor.rolledBack {
+		t.Error("Expected executor to be rolled back")
+	}
+}
+
+func TestDefaultSqlSession_BatchFlushStatements(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	cfg := config.NewConfiguration()
+	cfg.DataSource = &config.DataSource{DB: db}
+	cfg.DefaultExecutorType = config.ExecutorBatch
+	cfg.MapperConfig.Mappers["UserMapper.Insert"] = &config.MapperStatement{
+		ID:            "UserMapper.Insert",
+		SQL:           "INSERT INTO users (name) VALUES (#{name})",
+		StatementType: config.INSERT,
+	}
+
+	session := NewSqlSession(cfg, false)
+	defer session.Close()
+
+	for _, name := range []string{"a", "b"} {
+		affected, err := session.Insert("UserMapper.Insert", map[string]interface{}{"name": name})
+		if err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+		if affected != 0 {
+			t.Errorf("Expected batched insert to return 0, got %d", affected)
+		}
+	}
+
+	mock.ExpectBegin()
+	prepared := mock.ExpectPrepare("INSERT INTO users")
+	prepared.ExpectExec().WithArgs("a").WillReturnResult(sqlmock.NewResult(0, 1))
+	prepared.ExpectExec().WithArgs("b").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	results, err := session.FlushStatements()
+	if err != nil {
+		t.Fatalf("FlushStatements failed: %v", err)
+	}
+	if len(results) != 1 || results[0].StatementID != "UserMapper.Insert" || len(results[0].UpdateCounts) != 2 {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+
+	// 非自动提交的会话在 Commit 时提交批量事务
+	mock.ExpectExec("INSERT INTO users").WithArgs("c").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if _, err := session.Insert("UserMapper.Insert", map[string]interface{}{"name": "c"}); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if err := session.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Mock expectations were not met: %v", err)
+	}
 }
 
 func TestDefaultSqlSession_Close(t *testing.T) {
 	cfg := config.NewConfiguration()
+	mockExecutor := &MockExecutor{}
 	session := &DefaultSqlSession{
 		configuration: cfg,
-		executor:      &MockExecutor{},
+		executor:      mockExecutor,
 		autoCommit:    true,
 		closed:        false,
 	}
@@ -402,6 +521,10 @@ func TestDefaultSqlSession_Close(t *testing.T) {
 		t.Error("Expected session to be closed")
 	}
 
+	if !mockExecutor.closed {
+		t.Error("Expected executor to be closed with the session")
+	}
+
 	// Test closing already closed session
 	err = session.Close()
 	if err != nil {
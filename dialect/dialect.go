@@ -0,0 +1,374 @@
+package dialect
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrUpsertNotSupported 方言不支持 insert-or-update 语句
+var ErrUpsertNotSupported = errors.New("upsert is not supported by this dialect")
+
+// Dialect 数据库方言，描述不同数据库的 SQL 差异
+type Dialect interface {
+	// Name 方言名称
+	Name() string
+	// SupportsReturning 是否支持 INSERT ... RETURNING 返回生成的列
+	SupportsReturning() bool
+	// SupportsLastInsertId 驱动是否支持 sql.Result.LastInsertId
+	SupportsLastInsertId() bool
+	// SupportsMultiRowInsert 是否支持 INSERT ... VALUES (...), (...) 一次插入多行
+	SupportsMultiRowInsert() bool
+	// MaxBindParameters 单条语句允许的最大参数个数
+	MaxBindParameters() int
+	// UpsertSQL 生成插入 rows 行、与 conflictKeys 冲突时更新 updateColumns 的语句，
+	// 占位符为 ?，参数按行依次对应 columns
+	UpsertSQL(table string, columns []string, rows int, conflictKeys, updateColumns []string) (string, error)
+	// SupportsRowValues 是否支持 (a, b) > (?, ?) 形式的行值比较
+	SupportsRowValues() bool
+	// PagingSQL 为查询追加分页子句，limit 与 offset 为行数与偏移量的占位符，offset 为空时不跳过行
+	PagingSQL(sql, limit, offset string) string
+}
+
+// GenericDialect 通用方言，依赖驱动的 LastInsertId 获取主键
+type GenericDialect struct{}
+
+// Name 实现 Dialect
+func (GenericDialect) Name() string { return "generic" }
+
+// SupportsReturning 实现 Dialect
+func (GenericDialect) SupportsReturning() bool { return false }
+
+// SupportsLastInsertId 实现 Dialect
+func (GenericDialect) SupportsLastInsertId() bool { return true }
+
+// SupportsMultiRowInsert 实现 Dialect
+func (GenericDialect) SupportsMultiRowInsert() bool { return false }
+
+// MaxBindParameters 实现 Dialect，按 SQLite 3.32 之前的限制保守取值
+func (GenericDialect) MaxBindParameters() int { return 999 }
+
+// SupportsRowValues 实现 Dialect，通用方言按不支持处理
+func (GenericDialect) SupportsRowValues() bool { return false }
+
+// PagingSQL 实现 Dialect，使用 LIMIT ... OFFSET
+func (GenericDialect) PagingSQL(sql, limit, offset string) string {
+	return limitOffsetSQL(sql, limit, offset)
+}
+
+// UpsertSQL 实现 Dialect，通用方言不支持
+func (GenericDialect) UpsertSQL(table string, columns []string, rows int, conflictKeys, updateColumns []string) (string, error) {
+	return "", ErrUpsertNotSupported
+}
+
+// MySQLDialect MySQL 方言
+type MySQLDialect struct{}
+
+// Name 实现 Dialect
+func (MySQLDialect) Name() string { return "mysql" }
+
+// SupportsReturning 实现 Dialect
+func (MySQLDialect) SupportsReturning() bool { return false }
+
+// SupportsLastInsertId 实现 Dialect
+func (MySQLDialect) SupportsLastInsertId() bool { return true }
+
+// SupportsMultiRowInsert 实现 Dialect
+func (MySQLDialect) SupportsMultiRowInsert() bool { return true }
+
+// MaxBindParameters 实现 Dialect
+func (MySQLDialect) MaxBindParameters() int { return 65535 }
+
+// SupportsRowValues 实现 Dialect
+func (MySQLDialect) SupportsRowValues() bool { return true }
+
+// PagingSQL 实现 Dialect，使用 LIMIT ... OFFSET
+func (MySQLDialect) PagingSQL(sql, limit, offset string) string {
+	return limitOffsetSQL(sql, limit, offset)
+}
+
+// UpsertSQL 实现 Dialect，使用 ON DUPLICATE KEY UPDATE，冲突由表上的唯一键判定
+func (MySQLDialect) UpsertSQL(table string, columns []string, rows int, conflictKeys, updateColumns []string) (string, error) {
+	var b strings.Builder
+	writeInsertValues(&b, table, columns, rows)
+	b.WriteString(" ON DUPLICATE KEY UPDATE ")
+	if len(updateColumns) == 0 {
+		// 没有需要更新的列时原值赋值，冲突行保持不变
+		fmt.Fprintf(&b, "%s = %s", columns[0], columns[0])
+		return b.String(), nil
+	}
+	for i, column := range updateColumns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%s = VALUES(%s)", column, column)
+	}
+	return b.String(), nil
+}
+
+// PostgreSQLDialect PostgreSQL 方言，驱动不支持 LastInsertId，使用 RETURNING
+type PostgreSQLDialect struct{}
+
+// Name 实现 Dialect
+func (PostgreSQLDialect) Name() string { return "postgresql" }
+
+// SupportsReturning 实现 Dialect
+func (PostgreSQLDialect) SupportsReturning() bool { return true }
+
+// SupportsLastInsertId 实现 Dialect
+func (PostgreSQLDialect) SupportsLastInsertId() bool { return false }
+
+// SupportsMultiRowInsert 实现 Dialect
+func (PostgreSQLDialect) SupportsMultiRowInsert() bool { return true }
+
+// MaxBindParameters 实现 Dialect
+func (PostgreSQLDialect) MaxBindParameters() int { return 65535 }
+
+// SupportsRowValues 实现 Dialect
+func (PostgreSQLDialect) SupportsRowValues() bool { return true }
+
+// PagingSQL 实现 Dialect，使用 LIMIT ... OFFSET
+func (PostgreSQLDialect) PagingSQL(sql, limit, offset string) string {
+	return limitOffsetSQL(sql, limit, offset)
+}
+
+// UpsertSQL 实现 Dialect，使用 ON CONFLICT ... DO UPDATE
+func (PostgreSQLDialect) UpsertSQL(table string, columns []string, rows int, conflictKeys, updateColumns []string) (string, error) {
+	return onConflictSQL(table, columns, rows, conflictKeys, updateColumns), nil
+}
+
+// SQLiteDialect SQLite 方言，3.35 起支持 RETURNING，可一次返回多行插入的全部主键
+type SQLiteDialect struct{}
+
+// Name 实现 Dialect
+func (SQLiteDialect) Name() string { return "sqlite" }
+
+// SupportsReturning 实现 Dialect
+func (SQLiteDialect) SupportsReturning() bool { return true }
+
+// SupportsLastInsertId 实现 Dialect
+func (SQLiteDialect) SupportsLastInsertId() bool { return true }
+
+// SupportsMultiRowInsert 实现 Dialect
+func (SQLiteDialect) SupportsMultiRowInsert() bool { return false }
+
+// MaxBindParameters 实现 Dialect，SQLite 3.32 起为 32766
+func (SQLiteDialect) MaxBindParameters() int { return 32766 }
+
+// SupportsRowValues 实现 Dialect，SQLite 3.15 起支持
+func (SQLiteDialect) SupportsRowValues() bool { return true }
+
+// PagingSQL 实现 Dialect，使用 LIMIT ... OFFSET
+func (SQLiteDialect) PagingSQL(sql, limit, offset string) string {
+	return limitOffsetSQL(sql, limit, offset)
+}
+
+// UpsertSQL 实现 Dialect，SQLite 3.24 起支持 ON CONFLICT ... DO UPDATE
+func (SQLiteDialect) UpsertSQL(table string, columns []string, rows int, conflictKeys, updateColumns []string) (string, error) {
+	return onConflictSQL(table, columns, rows, conflictKeys, updateColumns), nil
+}
+
+// SQLServerDialect SQL Server 方言
+type SQLServerDialect struct{}
+
+// Name 实现 Dialect
+func (SQLServerDialect) Name() string { return "sqlserver" }
+
+// SupportsReturning 实现 Dialect
+func (SQLServerDialect) SupportsReturning() bool { return false }
+
+// SupportsLastInsertId 实现 Dialect
+func (SQLServerDialect) SupportsLastInsertId() bool { return false }
+
+// SupportsMultiRowInsert 实现 Dialect
+func (SQLServerDialect) SupportsMultiRowInsert() bool { return false }
+
+// MaxBindParameters 实现 Dialect，SQL Server 单个请求最多 2100 个参数
+func (SQLServerDialect) MaxBindParameters() int { return 2100 }
+
+// SupportsRowValues 实现 Dialect
+func (SQLServerDialect) SupportsRowValues() bool { return false }
+
+// PagingSQL 实现 Dialect，使用 SQL Server 2012 起支持的 OFFSET ... FETCH，该子句要求 ORDER BY，
+// 查询未排序时以 ORDER BY (SELECT NULL) 补足
+func (SQLServerDialect) PagingSQL(sql, limit, offset string) string {
+	if !HasTopLevelKeyword(sql, "ORDER", "BY") {
+		sql += " ORDER BY (SELECT NULL)"
+	}
+	return offsetFetchSQL(sql, limit, offset)
+}
+
+// UpsertSQL 实现 Dialect，使用 MERGE ... USING (VALUES ...)
+func (SQLServerDialect) UpsertSQL(table string, columns []string, rows int, conflictKeys, updateColumns []string) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "MERGE INTO %s AS target USING (VALUES ", table)
+	writeRows(&b, len(columns), rows)
+	fmt.Fprintf(&b, ") AS source (%s)", strings.Join(columns, ", "))
+	writeMergeClauses(&b, columns, conflictKeys, updateColumns)
+	b.WriteString(";")
+	return b.String(), nil
+}
+
+// OracleDialect Oracle 方言
+type OracleDialect struct{}
+
+// Name 实现 Dialect
+func (OracleDialect) Name() string { return "oracle" }
+
+// SupportsReturning 实现 Dialect
+func (OracleDialect) SupportsReturning() bool { return false }
+
+// SupportsLastInsertId 实现 Dialect
+func (OracleDialect) SupportsLastInsertId() bool { return false }
+
+// SupportsMultiRowInsert 实现 Dialect
+func (OracleDialect) SupportsMultiRowInsert() bool { return false }
+
+// MaxBindParameters 实现 Dialect
+func (OracleDialect) MaxBindParameters() int { return 65535 }
+
+// SupportsRowValues 实现 Dialect，Oracle 不支持行值的大小比较
+func (OracleDialect) SupportsRowValues() bool { return false }
+
+// PagingSQL 实现 Dialect，使用 Oracle 12c 起支持的 OFFSET ... FETCH
+func (OracleDialect) PagingSQL(sql, limit, offset string) string {
+	return offsetFetchSQL(sql, limit, offset)
+}
+
+// UpsertSQL 实现 Dialect，使用 MERGE ... USING (SELECT ... FROM dual UNION ALL ...)
+func (OracleDialect) UpsertSQL(table string, columns []string, rows int, conflictKeys, updateColumns []string) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "MERGE INTO %s target USING (", table)
+	for row := 0; row < rows; row++ {
+		if row > 0 {
+			b.WriteString(" UNION ALL ")
+		}
+		b.WriteString("SELECT ")
+		for i, column := range columns {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "? %s", column)
+		}
+		b.WriteString(" FROM dual")
+	}
+	b.WriteString(") source")
+	writeMergeClauses(&b, columns, conflictKeys, updateColumns)
+	return b.String(), nil
+}
+
+// limitOffsetSQL 追加 LIMIT ... OFFSET 子句
+func limitOffsetSQL(sql, limit, offset string) string {
+	if offset == "" {
+		return sql + " LIMIT " + limit
+	}
+	return sql + " LIMIT " + limit + " OFFSET " + offset
+}
+
+// offsetFetchSQL 追加标准 SQL 的 OFFSET ... ROWS FETCH NEXT ... ROWS ONLY 子句
+func offsetFetchSQL(sql, limit, offset string) string {
+	if offset == "" {
+		offset = "0"
+	}
+	return sql + " OFFSET " + offset + " ROWS FETCH NEXT " + limit + " ROWS ONLY"
+}
+
+// writeRows 写入 rows 个 (?, ?, ...) 元组
+func writeRows(b *strings.Builder, columns, rows int) {
+	tuple := "(" + strings.TrimSuffix(strings.Repeat("?, ", columns), ", ") + ")"
+	for row := 0; row < rows; row++ {
+		if row > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(tuple)
+	}
+}
+
+// writeInsertValues 写入多行 INSERT INTO ... VALUES
+func writeInsertValues(b *strings.Builder, table string, columns []string, rows int) {
+	fmt.Fprintf(b, "INSERT INTO %s (%s) VALUES ", table, strings.Join(columns, ", "))
+	writeRows(b, len(columns), rows)
+}
+
+// onConflictSQL 生成 PostgreSQL 与 SQLite 的 ON CONFLICT 语句
+func onConflictSQL(table string, columns []string, rows int, conflictKeys, updateColumns []string) string {
+	var b strings.Builder
+	writeInsertValues(&b, table, columns, rows)
+	fmt.Fprintf(&b, " ON CONFLICT (%s) ", strings.Join(conflictKeys, ", "))
+	if len(updateColumns) == 0 {
+		b.WriteString("DO NOTHING")
+		return b.String()
+	}
+	b.WriteString("DO UPDATE SET ")
+	for i, column := range updateColumns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%s = EXCLUDED.%s", column, column)
+	}
+	return b.String()
+}
+
+// writeMergeClauses 写入 MERGE 的 ON 条件与 WHEN MATCHED / WHEN NOT MATCHED 子句
+func writeMergeClauses(b *strings.Builder, columns, conflictKeys, updateColumns []string) {
+	b.WriteString(" ON (")
+	for i, key := range conflictKeys {
+		if i > 0 {
+			b.WriteString(" AND ")
+		}
+		fmt.Fprintf(b, "target.%s = source.%s", key, key)
+	}
+	b.WriteString(")")
+
+	if len(updateColumns) > 0 {
+		b.WriteString(" WHEN MATCHED THEN UPDATE SET ")
+		for i, column := range updateColumns {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(b, "target.%s = source.%s", column, column)
+		}
+	}
+
+	fmt.Fprintf(b, " WHEN NOT MATCHED THEN INSERT (%s) VALUES (", strings.Join(columns, ", "))
+	for i, column := range columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(b, "source.%s", column)
+	}
+	b.WriteString(")")
+}
+
+var (
+	mu       sync.RWMutex
+	dialects = map[string]Dialect{
+		"mysql":     MySQLDialect{},
+		"postgres":  PostgreSQLDialect{},
+		"pgx":       PostgreSQLDialect{},
+		"sqlite3":   SQLiteDialect{},
+		"sqlite":    SQLiteDialect{},
+		"sqlserver": SQLServerDialect{},
+		"mssql":     SQLServerDialect{},
+		"oracle":    OracleDialect{},
+		"godror":    OracleDialect{},
+	}
+)
+
+// Register 为驱动名注册方言
+func Register(driverName string, d Dialect) {
+	mu.Lock()
+	defer mu.Unlock()
+	dialects[strings.ToLower(driverName)] = d
+}
+
+// ForDriver 根据驱动名获取方言，未注册的驱动使用通用方言
+func ForDriver(driverName string) Dialect {
+	mu.RLock()
+	defer mu.RUnlock()
+	if d, exists := dialects[strings.ToLower(driverName)]; exists {
+		return d
+	}
+	return GenericDialect{}
+}
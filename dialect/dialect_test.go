@@ -0,0 +1,127 @@
+package dialect
+
+import "testing"
+
+// TestForDriver 测试根据驱动名获取方言
+func TestForDriver(t *testing.T) {
+	testCases := map[string]string{
+		"mysql":     "mysql",
+		"postgres":  "postgresql",
+		"pgx":       "postgresql",
+		"sqlite3":   "sqlite",
+		"sqlserver": "sqlserver",
+		"godror":    "oracle",
+		"unknown":   "generic",
+	}
+
+	for driverName, expected := range testCases {
+		if name := ForDriver(driverName).Name(); name != expected {
+			t.Errorf("ForDriver(%s) = %s, expected %s", driverName, name, expected)
+		}
+	}
+
+	if !ForDriver("postgres").SupportsReturning() || ForDriver("postgres").SupportsLastInsertId() {
+		t.Error("PostgreSQL should use RETURNING instead of LastInsertId")
+	}
+
+	if !ForDriver("mysql").SupportsMultiRowInsert() || ForDriver("unknown").SupportsMultiRowInsert() {
+		t.Error("Only MySQL and PostgreSQL should rewrite batch inserts into multi-row VALUES")
+	}
+}
+
+// TestRegister 测试注册自定义驱动的方言
+func TestRegister(t *testing.T) {
+	Register("cockroach", PostgreSQLDialect{})
+
+	if name := ForDriver("cockroach").Name(); name != "postgresql" {
+		t.Errorf("Expected postgresql dialect, got %s", name)
+	}
+}
+
+// TestUpsertSQL 测试各方言生成的 upsert 语句
+func TestUpsertSQL(t *testing.T) {
+	columns := []string{"id", "name", "age"}
+	keys := []string{"id"}
+	updates := []string{"name", "age"}
+
+	testCases := []struct {
+		dialect  Dialect
+		expected string
+	}{
+		{MySQLDialect{}, "INSERT INTO users (id, name, age) VALUES (?, ?, ?), (?, ?, ?) ON DUPLICATE KEY UPDATE name = VALUES(name), age = VALUES(age)"},
+		{PostgreSQLDialect{}, "INSERT INTO users (id, name, age) VALUES (?, ?, ?), (?, ?, ?) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, age = EXCLUDED.age"},
+		{SQLiteDialect{}, "INSERT INTO users (id, name, age) VALUES (?, ?, ?), (?, ?, ?) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, age = EXCLUDED.age"},
+		{SQLServerDialect{}, "MERGE INTO users AS target USING (VALUES (?, ?, ?), (?, ?, ?)) AS source (id, name, age) ON (target.id = source.id) " +
+			"WHEN MATCHED THEN UPDATE SET target.name = source.name, target.age = source.age " +
+			"WHEN NOT MATCHED THEN INSERT (id, name, age) VALUES (source.id, source.name, source.age);"},
+		{OracleDialect{}, "MERGE INTO users target USING (SELECT ? id, ? name, ? age FROM dual UNION ALL SELECT ? id, ? name, ? age FROM dual) source ON (target.id = source.id) " +
+			"WHEN MATCHED THEN UPDATE SET target.name = source.name, target.age = source.age " +
+			"WHEN NOT MATCHED THEN INSERT (id, name, age) VALUES (source.id, source.name, source.age)"},
+	}
+
+	for _, tc := range testCases {
+		sql, err := tc.dialect.UpsertSQL("users", columns, 2, keys, updates)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.dialect.Name(), err)
+			continue
+		}
+		if sql != tc.expected {
+			t.Errorf("%s:\n got: %s\nwant: %s", tc.dialect.Name(), sql, tc.expected)
+		}
+	}
+
+	// 没有需要更新的列时冲突行保持不变
+	if sql, _ := (PostgreSQLDialect{}).UpsertSQL("users", keys, 1, keys, []string{}); sql != "INSERT INTO users (id) VALUES (?) ON CONFLICT (id) DO NOTHING" {
+		t.Errorf("Unexpected DO NOTHING upsert: %s", sql)
+	}
+	if sql, _ := (MySQLDialect{}).UpsertSQL("users", keys, 1, keys, nil); sql != "INSERT INTO users (id) VALUES (?) ON DUPLICATE KEY UPDATE id = id" {
+		t.Errorf("Unexpected no-op MySQL upsert: %s", sql)
+	}
+
+	if _, err := (GenericDialect{}).UpsertSQL("users", columns, 1, keys, updates); err != ErrUpsertNotSupported {
+		t.Errorf("Expected ErrUpsertNotSupported, got %v", err)
+	}
+}
+
+// TestHasTopLevelKeyword 测试只识别顶层关键字，忽略标识符、字符串、注释与子查询中的同名单词
+func TestHasTopLevelKeyword(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected bool
+	}{
+		{"INSERT INTO t (a) VALUES (?) RETURNING id", true},
+		{"INSERT INTO t (returning_customer) VALUES (?)", false},
+		{"INSERT INTO t (a) VALUES ('returning')", false},
+		{"INSERT INTO t (a) SELECT a FROM (SELECT 1 AS a RETURNING x) s", false},
+		{"INSERT INTO t (a) VALUES (?) -- returning", false},
+	}
+	for _, tt := range tests {
+		if got := HasTopLevelKeyword(tt.sql, "RETURNING"); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.sql, tt.expected, got)
+		}
+	}
+}
+
+// TestPagingSQL 测试各方言生成的分页子句
+func TestPagingSQL(t *testing.T) {
+	testCases := []struct {
+		dialect  Dialect
+		sql      string
+		offset   string
+		expected string
+	}{
+		{MySQLDialect{}, "SELECT * FROM users ORDER BY id", "#{offset}", "SELECT * FROM users ORDER BY id LIMIT #{limit} OFFSET #{offset}"},
+		{PostgreSQLDialect{}, "SELECT * FROM users", "", "SELECT * FROM users LIMIT #{limit}"},
+		{GenericDialect{}, "SELECT * FROM users", "#{offset}", "SELECT * FROM users LIMIT #{limit} OFFSET #{offset}"},
+		{SQLServerDialect{}, "SELECT * FROM users ORDER BY id", "#{offset}", "SELECT * FROM users ORDER BY id OFFSET #{offset} ROWS FETCH NEXT #{limit} ROWS ONLY"},
+		// 子查询中的 ORDER BY 不计，补足顶层排序
+		{SQLServerDialect{}, "SELECT * FROM (SELECT TOP 5 * FROM users ORDER BY id) t", "", "SELECT * FROM (SELECT TOP 5 * FROM users ORDER BY id) t ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT #{limit} ROWS ONLY"},
+		{OracleDialect{}, "SELECT * FROM users", "#{offset}", "SELECT * FROM users OFFSET #{offset} ROWS FETCH NEXT #{limit} ROWS ONLY"},
+	}
+
+	for _, tc := range testCases {
+		if sql := tc.dialect.PagingSQL(tc.sql, "#{limit}", tc.offset); sql != tc.expected {
+			t.Errorf("%s:\n got: %s\nwant: %s", tc.dialect.Name(), sql, tc.expected)
+		}
+	}
+}
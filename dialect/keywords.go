@@ -0,0 +1,96 @@
+package dialect
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Keyword 顶层（不在括号、引号内）出现的单词及其位置
+type Keyword struct {
+	Word  string // 大写单词
+	Start int
+	End   int
+}
+
+// TopLevelKeywords 扫描 SQL 中顶层的单词，跳过字符串、引号标识符、注释与括号内的内容
+func TopLevelKeywords(sql string) []Keyword {
+	var keywords []Keyword
+	depth := 0
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(sql, i, c)
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			if end := strings.IndexByte(sql[i:], '\n'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(sql)
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			if end := strings.Index(sql[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(sql)
+			}
+		case c == '(':
+			depth++
+			i++
+		case c == ')':
+			depth--
+			i++
+		case isWordChar(c):
+			start := i
+			for i < len(sql) && isWordChar(sql[i]) {
+				i++
+			}
+			if depth == 0 {
+				keywords = append(keywords, Keyword{Word: strings.ToUpper(sql[start:i]), Start: start, End: i})
+			}
+		default:
+			i++
+		}
+	}
+	return keywords
+}
+
+// skipQuoted 返回引号内容结束后的位置，连续两个引号视为转义
+func skipQuoted(sql string, start int, quote byte) int {
+	for i := start + 1; i < len(sql); i++ {
+		if sql[i] == quote {
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(sql)
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c == '#' || c == '$' || c == '{' || c == '}' || c == '.' ||
+		unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+// FindKeywords 查找连续的顶层关键字，如 ORDER BY，返回首个关键字的下标，未找到时返回 -1
+func FindKeywords(keywords []Keyword, words ...string) int {
+	for i := 0; i+len(words) <= len(keywords); i++ {
+		matched := true
+		for j, word := range words {
+			if keywords[i+j].Word != word {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return i
+		}
+	}
+	return -1
+}
+
+// HasTopLevelKeyword SQL 顶层是否出现关键字，列名、别名与字符串中的同名单词不计
+func HasTopLevelKeyword(sql string, words ...string) bool {
+	return FindKeywords(TopLevelKeywords(sql), words...) >= 0
+}
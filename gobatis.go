@@ -1,16 +1,13 @@
 package gobatis
 
 import (
-	"context"
 	"database/sql"
 	"fmt"
-	"gobatis/binding"
 	"gobatis/core/config"
+	"gobatis/core/executor"
 	"gobatis/core/mapper"
-	"gobatis/mapping"
 	"gobatis/plugins"
 	"reflect"
-	"time"
 )
 
 // SqlSession SQL 会话接口
@@ -20,7 +17,13 @@ type SqlSession interface {
 	Insert(statementId string, parameter interface{}) (int64, error)
 	Update(statementId string, parameter interface{}) (int64, error)
 	Delete(statementId string, parameter interface{}) (int64, error)
+	// Upsert 批量插入，与 conflictKeys 冲突的行改为更新 updateColumns，target 为 INSERT 语句 ID 或表名
+	Upsert(target string, rows interface{}, conflictKeys, updateColumns []string) (int64, error)
 	GetMapper(mapperType interface{}) interface{}
+	// FlushStatements 执行批量会话中累计的语句，非批量会话返回 nil
+	FlushStatements() ([]BatchResult, error)
+	// ClearCache 清空会话的一级缓存
+	ClearCache()
 	Commit() error
 	Rollback() error
 	Close() error
@@ -28,19 +31,51 @@ type SqlSession interface {
 
 // SqlSessionFactory SQL 会话工厂接口
 type SqlSessionFactory interface {
-	OpenSession() SqlSession
+	OpenSession(options ...SessionOption) SqlSession
 	OpenSessionWithAutoCommit(autoCommit bool) SqlSession
 }
 
+// 执行器类型
+const (
+	Simple = config.ExecutorSimple
+	Reuse  = config.ExecutorReuse
+	Batch  = config.ExecutorBatch
+)
+
+// BatchResult 批量语句的执行结果
+type BatchResult = executor.BatchResult
+
+// sessionOptions 打开会话的选项
+type sessionOptions struct {
+	executorType config.ExecutorType
+	autoCommit   bool
+}
+
+// SessionOption 打开会话的选项
+type SessionOption func(*sessionOptions)
+
+// WithExecutor 指定会话使用的执行器类型，默认使用 Configuration.DefaultExecutorType
+func WithExecutor(executorType config.ExecutorType) SessionOption {
+	return func(o *sessionOptions) {
+		o.executorType = executorType
+	}
+}
+
+// WithAutoCommit 指定会话是否自动提交，默认自动提交
+func WithAutoCommit(autoCommit bool) SessionOption {
+	return func(o *sessionOptions) {
+		o.autoCommit = autoCommit
+	}
+}
+
 // DefaultSqlSession 默认 SQL 会话实现
 type DefaultSqlSession struct {
-	configuration   *config.Configuration
-	parameterBinder binding.ParameterBinder
-	resultMapper    mapping.ResultMapper
-	pluginManager   *plugins.PluginManager
-	tx              *sql.Tx
-	autoCommit      bool
-	closed          bool
+	configuration *config.Configuration
+	executor      executor.Executor
+	pluginManager *plugins.PluginManager
+	tx            *sql.Tx
+	autoCommit    bool
+	closed        bool
 }
 
 // DefaultSqlSessionFactory 默认 SQL 会话工厂
@@ -54,15 +89,15 @@ func NewConfiguration() *config.Configuration {
 	return config.NewConfiguration()
 }
 
-// NewSqlSessionFactory 创建 SQL 会话工厂
+// NewSqlSessionFactory 创建 SQL 会话工厂，插件链由 Configuration.Plugins 构建
 func NewSqlSessionFactory(configuration *config.Configuration) SqlSessionFactory {
 	return &DefaultSqlSessionFactory{
 		configuration: configuration,
-		pluginManager: plugins.NewPluginManager(),
+		pluginManager: plugins.NewPluginManagerWithConfiguration(configuration),
 	}
 }
 
-// NewSqlSessionFactoryWithPlugins 创建带插件管理器的 SQL 会话工厂
+// NewSqlSessionFactoryWithPlugins 创建带插件管理器的 SQL 会话工厂，不使用 Configuration.Plugins
 func NewSqlSessionFactoryWithPlugins(configuration *config.Configuration, pluginManager *plugins.PluginManager) SqlSessionFactory {
 	return &DefaultSqlSessionFactory{
 		configuration: configuration,
@@ -70,21 +105,36 @@ func NewSqlSessionFactoryWithPlugins(configuration *config.Configuration, plugin
 	}
 }
 
-// OpenSession 打开会话（自动提交）
-func (f *DefaultSqlSessionFactory) OpenSession() SqlSession {
-	return f.OpenSessionWithAutoCommit(true)
+// OpenSession 打开会话，默认自动提交并使用配置的默认执行器
+func (f *DefaultSqlSessionFactory) OpenSession(options ...SessionOption) SqlSession {
+	opts := sessionOptions{
+		executorType: f.configuration.DefaultExecutorType,
+		autoCommit:   true,
+	}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	// 插件管理器同时拦截执行器的各阶段
+	var exec executor.Executor
+	if f.pluginManager != nil {
+		exec = executor.NewExecutorWithInterceptor(f.configuration, opts.executorType, f.pluginManager)
+	} else {
+		exec = executor.NewExecutor(f.configuration, opts.executorType)
+	}
+
+	return &DefaultSqlSession{
+		configuration: f.configuration,
+		executor:      exec,
+		pluginManager: f.pluginManager,
+		autoCommit:    opts.autoCommit,
+		closed:        false,
+	}
 }
 
 // OpenSessionWithAutoCommit 打开会话（指定是否自动提交）
 func (f *DefaultSqlSessionFactory) OpenSessionWithAutoCommit(autoCommit bool) SqlSession {
-	return &DefaultSqlSession{
-		configuration:   f.configuration,
-		parameterBinder: binding.NewParameterBinder(),
-		resultMapper:    mapping.NewResultMapper(),
-		pluginManager:   f.pluginManager,
-		autoCommit:      autoCommit,
-		closed:          false,
-	}
+	return f.OpenSession(WithAutoCommit(autoCommit))
 }
 
 // SelectOne 查询单个结果
@@ -102,33 +152,18 @@ func (s *DefaultSqlSession) SelectOne(statementId string, parameter interface{})
 		return nil, fmt.Errorf("statement %s is not a select statement", statementId)
 	}
 
-	// 创建拦截调用
-	invocation := &plugins.Invocation{
-		Target:      s,
-		Method:      reflect.Method{Name: "SelectOne"},
-		Args:        []interface{}{statementId, parameter},
-		StatementId: statementId,
-		Properties:  map[string]interface{}{"sql": stmt.SQL},
-		Proceed: func() (interface{}, error) {
-			results, err := s.query(stmt, parameter)
-			if err != nil {
-				return nil, err
-			}
-			if len(results) == 0 {
-				return nil, nil
-			}
-			return results[0], nil
-		},
-	}
-
-	// 如果有插件管理器，使用插件拦截
-	if s.pluginManager != nil && s.pluginManager.Size() > 0 {
-		method := reflect.Method{Name: "SelectOne"}
-		return s.pluginManager.InterceptMethod(s, method, []interface{}{statementId, parameter}, statementId, invocation.Proceed)
-	}
-
-	// 否则直接执行
-	return invocation.Proceed()
+	result, err := s.intercept("SelectOne", stmt, parameter, s.query)
+	if err != nil {
+		return nil, err
+	}
+	if results, ok := result.([]interface{}); ok {
+		if len(results) == 0 {
+			return nil, nil
+		}
+		return results[0], nil
+	}
+	// 插件可返回其他结果，如分页插件返回 *plugins.PageResult[interface{}] 或 *plugins.CursorPageResult
+	return result, nil
 }
 
 // SelectList 查询多个结果
@@ -146,38 +181,49 @@ func (s *DefaultSqlSession) SelectList(statementId string, parameter interface{}
 		return nil, fmt.Errorf("statement %s is not a select statement", statementId)
 	}
 
-	// 创建拦截调用
-	invocation := &plugins.Invocation{
-		Target:      s,
-		Method:      reflect.Method{Name: "SelectList"},
-		Args:        []interface{}{statementId, parameter},
-		StatementId: statementId,
-		Properties:  map[string]interface{}{"sql": stmt.SQL},
-		Proceed: func() (interface{}, error) {
-			return s.query(stmt, parameter)
-		},
-	}
-
-	// 如果有插件管理器，使用插件拦截
-	if s.pluginManager != nil && s.pluginManager.Size() > 0 {
-		method := reflect.Method{Name: "SelectList"}
-		result, err := s.pluginManager.InterceptMethod(s, method, []interface{}{statementId, parameter}, statementId, invocation.Proceed)
-		if err != nil {
-			return nil, err
-		}
-		// 确保返回类型是 []interface{}
-		if results, ok := result.([]interface{}); ok {
-			return results, nil
+	result, err := s.intercept("SelectList", stmt, parameter, s.query)
+	if err != nil {
+		return nil, err
+	}
+	switch results := result.(type) {
+	case []interface{}:
+		return results, nil
+	case *plugins.PageResult[interface{}]:
+		// 分页查询返回当前页的数据
+		return results.Data, nil
+	case *plugins.CursorPageResult:
+		if data, ok := results.Data.([]interface{}); ok {
+			return data, nil
 		}
-		return nil, fmt.Errorf("unexpected result type from plugin: %T", result)
 	}
+	return nil, fmt.Errorf("unexpected result type from plugin: %T", result)
+}
 
-	// 否则直接执行
-	result, err := invocation.Proceed()
-	if err != nil {
-		return nil, err
+// intercept 经插件链执行 SqlSession 的方法，插件可替换 invocation.Statement 改变实际执行的 SQL，
+// 或通过 invocation.Executor 在同一事务中执行附加查询
+func (s *DefaultSqlSession) intercept(methodName string, stmt *config.MapperStatement, parameter interface{}, proceed func(*config.MapperStatement, interface{}) (interface{}, error)) (interface{}, error) {
+	// 没有插件拦截时直接执行
+	if s.pluginManager == nil || !s.pluginManager.Intercepts(plugins.TargetSqlSession, methodName, stmt) {
+		return proceed(stmt, parameter)
 	}
-	return result.([]interface{}), nil
+
+	// 使用语句副本，插件的修改不影响全局配置
+	statement := *stmt
+	invocation := &plugins.Invocation{
+		Target:        s,
+		Method:        reflect.Method{Name: methodName},
+		Args:          []interface{}{stmt.ID, parameter},
+		StatementId:   stmt.ID,
+		Properties:    map[string]interface{}{"sql": stmt.SQL},
+		Statement:     &statement,
+		Parameter:     parameter,
+		Executor:      s.executor,
+		Configuration: s.configuration,
+		Signature:     plugins.Signature{Target: plugins.TargetSqlSession, Method: methodName},
+	}
+	return s.pluginManager.Execute(invocation, func(current *plugins.Invocation) (interface{}, error) {
+		return proceed(current.Statement, current.Parameter)
+	})
 }
 
 // Insert 插入数据
@@ -195,7 +241,7 @@ func (s *DefaultSqlSession) Insert(statementId string, parameter interface{}) (i
 		return 0, fmt.Errorf("statement %s is not an insert statement", statementId)
 	}
 
-	return s.update(stmt, parameter)
+	return s.intercepted("Insert", stmt, parameter)
 }
 
 // Update 更新数据
@@ -213,7 +259,7 @@ func (s *DefaultSqlSession) Update(statementId string, parameter interface{}) (i
 		return 0, fmt.Errorf("statement %s is not an update statement", statementId)
 	}
 
-	return s.update(stmt, parameter)
+	return s.intercepted("Update", stmt, parameter)
 }
 
 // Delete 删除数据
@@ -231,7 +277,16 @@ func (s *DefaultSqlSession) Delete(statementId string, parameter interface{}) (i
 		return 0, fmt.Errorf("statement %s is not a delete statement", statementId)
 	}
 
-	return s.update(stmt, parameter)
+	return s.intercepted("Delete", stmt, parameter)
+}
+
+// Upsert 批量插入或更新，生成的语句取决于配置的方言，返回累计影响的行数
+func (s *DefaultSqlSession) Upsert(target string, rows interface{}, conflictKeys, updateColumns []string) (int64, error) {
+	if s.closed {
+		return 0, fmt.Errorf("session is closed")
+	}
+
+	return s.executor.Upsert(target, rows, conflictKeys, updateColumns)
 }
 
 // GetMapper 获取 Mapper 代理
@@ -254,12 +309,39 @@ func (s *DefaultSqlSession) GetMapper(mapperType interface{}) interface{} {
 	return mapper.NewMapperProxy(s, t)
 }
 
-// Commit 提交事务
+// FlushStatements 执行批量会话中累计的语句，自动提交的会话随后提交批量事务
+func (s *DefaultSqlSession) FlushStatements() ([]BatchResult, error) {
+	if s.closed {
+		return nil, fmt.Errorf("session is closed")
+	}
+
+	results, err := s.executor.FlushStatements()
+	if err != nil {
+		return nil, err
+	}
+	if s.autoCommit {
+		if err := s.executor.Commit(); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// ClearCache 清空会话的一级缓存
+func (s *DefaultSqlSession) ClearCache() {
+	s.executor.ClearLocalCache()
+}
+
+// Commit 提交事务，批量会话先执行累计的语句
 func (s *DefaultSqlSession) Commit() error {
 	if s.closed {
 		return fmt.Errorf("session is closed")
 	}
 
+	if err := s.executor.Commit(); err != nil {
+		return err
+	}
+
 	if s.tx != nil {
 		err := s.tx.Commit()
 		s.tx = nil
@@ -269,12 +351,16 @@ func (s *DefaultSqlSession) Commit() error {
 	return nil
 }
 
-// Rollback 回滚事务
+// Rollback 回滚事务，批量会话丢弃累计的语句
 func (s *DefaultSqlSession) Rollback() error {
 	if s.closed {
 		return fmt.Errorf("session is closed")
 	}
 
+	if err := s.executor.Rollback(); err != nil {
+		return err
+	}
+
 	if s.tx != nil {
 		err := s.tx.Rollback()
 		s.tx = nil
@@ -284,7 +370,7 @@ func (s *DefaultSqlSession) Rollback() error {
 	return nil
 }
 
-// Close 关闭会话
+// Close 关闭会话，批量会话中未提交的语句会被丢弃
 func (s *DefaultSqlSession) Close() error {
 	if s.closed {
 		return nil
@@ -296,112 +382,28 @@ func (s *DefaultSqlSession) Close() error {
 	}
 
 	s.closed = true
-	return nil
+	return s.executor.Close()
 }
 
 // query 执行查询
-func (s *DefaultSqlSession) query(statement *config.MapperStatement, parameter interface{}) ([]interface{}, error) {
-	// 开始计时
-	begin := time.Now()
-	ctx := context.Background()
-
-	// 绑定参数
-	processedSQL, args, err := s.parameterBinder.BindParameters(statement.SQL, parameter)
-	if err != nil {
-		// 记录参数绑定错误
-		s.configuration.Logger.Trace(ctx, begin, func() (string, int64) {
-			return fmt.Sprintf("%s [PARAMS: %v]", statement.SQL, parameter), -1
-		}, err)
-		return nil, fmt.Errorf("failed to bind parameters: %w", err)
-	}
-
-	// 执行查询
-	rows, err := s.configuration.DataSource.DB.Query(processedSQL, args...)
-	if err != nil {
-		// 记录查询执行错误
-		s.configuration.Logger.Trace(ctx, begin, func() (string, int64) {
-			return fmt.Sprintf("%s [ARGS: %v]", processedSQL, args), -1
-		}, err)
-		return nil, fmt.Errorf("failed to execute query: %w", err)
-	}
-	defer rows.Close()
-
-	// 确定结果类型
-	resultType := statement.ResultType
-	if resultType == nil {
-		// 如果没有指定结果类型，使用 map[string]interface{}
-		resultType = reflect.TypeOf(map[string]interface{}{})
-	}
-
-	// 映射结果
-	results, err := s.resultMapper.MapResults(rows, resultType)
-	if err != nil {
-		// 记录结果映射错误
-		s.configuration.Logger.Trace(ctx, begin, func() (string, int64) {
-			return fmt.Sprintf("%s [ARGS: %v]", processedSQL, args), -1
-		}, err)
-		return nil, fmt.Errorf("failed to map results: %w", err)
-	}
-
-	// 记录成功的查询
-	s.configuration.Logger.Trace(ctx, begin, func() (string, int64) {
-		return fmt.Sprintf("%s [ARGS: %v]", processedSQL, args), int64(len(results))
-	}, nil)
-
-	return results, nil
+func (s *DefaultSqlSession) query(statement *config.MapperStatement, parameter interface{}) (interface{}, error) {
+	return s.executor.Query(statement, parameter)
 }
 
 // update 执行更新（包括 INSERT、UPDATE、DELETE）
-func (s *DefaultSqlSession) update(statement *config.MapperStatement, parameter interface{}) (int64, error) {
-	// 开始计时
-	begin := time.Now()
-	ctx := context.Background()
+func (s *DefaultSqlSession) update(statement *config.MapperStatement, parameter interface{}) (interface{}, error) {
+	return s.executor.Update(statement, parameter)
+}
 
-	// 绑定参数
-	processedSQL, args, err := s.parameterBinder.BindParameters(statement.SQL, parameter)
+// intercepted 经插件链执行更新，插件需返回影响的行数
+func (s *DefaultSqlSession) intercepted(methodName string, stmt *config.MapperStatement, parameter interface{}) (int64, error) {
+	result, err := s.intercept(methodName, stmt, parameter, s.update)
 	if err != nil {
-		// 记录参数绑定错误
-		s.configuration.Logger.Trace(ctx, begin, func() (string, int64) {
-			return fmt.Sprintf("%s [PARAMS: %v]", statement.SQL, parameter), -1
-		}, err)
-		return 0, fmt.Errorf("failed to bind parameters: %w", err)
+		return 0, err
 	}
-
-	// 执行更新
-	result, err := s.configuration.DataSource.DB.Exec(processedSQL, args...)
-	if err != nil {
-		// 记录执行错误
-		s.configuration.Logger.Trace(ctx, begin, func() (string, int64) {
-			return fmt.Sprintf("%s [ARGS: %v]", processedSQL, args), -1
-		}, err)
-		return 0, fmt.Errorf("failed to execute update: %w", err)
-	}
-
-	// 根据语句类型返回不同的结果
-	var affectedRows int64
-	switch statement.StatementType {
-	case config.INSERT:
-		// 对于 INSERT，返回插入的 ID
-		if id, err := result.LastInsertId(); err == nil {
-			affectedRows = id
-		} else if affected, err := result.RowsAffected(); err == nil {
-			affectedRows = affected
-		}
-	case config.UPDATE, config.DELETE:
-		// 对于 UPDATE 和 DELETE，返回影响的行数
-		if affected, err := result.RowsAffected(); err == nil {
-			affectedRows = affected
-		}
-	default:
-		if affected, err := result.RowsAffected(); err == nil {
-			affectedRows = affected
-		}
+	affected, ok := result.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected result type from plugin: %T", result)
 	}
-
-	// 记录成功的更新
-	s.configuration.Logger.Trace(ctx, begin, func() (string, int64) {
-		return fmt.Sprintf("%s [ARGS: %v]", processedSQL, args), affectedRows
-	}, nil)
-
-	return affectedRows, nil
+	return affected, nil
 }
@@ -0,0 +1,212 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// IdGenerator 客户端主键生成器
+type IdGenerator interface {
+	NextID() (interface{}, error)
+}
+
+// IdGeneratorFunc 函数形式的主键生成器
+type IdGeneratorFunc func() (interface{}, error)
+
+// NextID 实现 IdGenerator
+func (f IdGeneratorFunc) NextID() (interface{}, error) {
+	return f()
+}
+
+// 内置生成器名称
+const (
+	Snowflake = "snowflake"
+	UUIDv7    = "uuidv7"
+	ULID      = "ulid"
+)
+
+// Defaults 创建内置生成器，snowflake 的 workerID 为 0
+func Defaults() map[string]IdGenerator {
+	snowflake, _ := NewSnowflakeGenerator(0)
+	return map[string]IdGenerator{
+		Snowflake: snowflake,
+		UUIDv7:    NewUUIDv7Generator(),
+		ULID:      NewULIDGenerator(),
+	}
+}
+
+// snowflake 位分配：41 位毫秒时间戳、10 位 workerID、12 位序列号
+const (
+	workerIDBits = 10
+	sequenceBits = 12
+	maxWorkerID  = -1 ^ (-1 << workerIDBits)
+	maxSequence  = -1 ^ (-1 << sequenceBits)
+)
+
+// SnowflakeEpoch snowflake 时间戳起点（2020-01-01 UTC）
+var SnowflakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// SnowflakeGenerator snowflake 主键生成器，生成趋势递增的 int64
+type SnowflakeGenerator struct {
+	mu       sync.Mutex
+	workerID int64
+	lastMs   int64
+	sequence int64
+	now      func() time.Time
+}
+
+// NewSnowflakeGenerator 创建 snowflake 生成器，workerID 取值 0-1023，同一集群内需唯一
+func NewSnowflakeGenerator(workerID int64) (*SnowflakeGenerator, error) {
+	if workerID < 0 || workerID > maxWorkerID {
+		return nil, fmt.Errorf("snowflake worker id must be between 0 and %d, got %d", maxWorkerID, workerID)
+	}
+	return &SnowflakeGenerator{workerID: workerID, now: time.Now}, nil
+}
+
+// NextID 实现 IdGenerator，返回 int64
+func (g *SnowflakeGenerator) NextID() (interface{}, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := g.now().Sub(SnowflakeEpoch).Milliseconds()
+	if ms < g.lastMs {
+		return nil, fmt.Errorf("snowflake clock moved backwards by %dms", g.lastMs-ms)
+	}
+
+	if ms == g.lastMs {
+		g.sequence = (g.sequence + 1) & maxSequence
+		// 当前毫秒序列号用尽，等待下一毫秒
+		for g.sequence == 0 && ms <= g.lastMs {
+			time.Sleep(100 * time.Microsecond)
+			ms = g.now().Sub(SnowflakeEpoch).Milliseconds()
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.lastMs = ms
+
+	return ms<<(workerIDBits+sequenceBits) | g.workerID<<sequenceBits | g.sequence, nil
+}
+
+// UUID 16 字节 UUID，String 返回标准的 8-4-4-4-12 格式
+type UUID [16]byte
+
+// String 实现 fmt.Stringer
+func (u UUID) String() string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// UUIDv7Generator 按 RFC 9562 生成时间有序的 UUIDv7
+type UUIDv7Generator struct {
+	now func() time.Time
+}
+
+// NewUUIDv7Generator 创建 UUIDv7 生成器
+func NewUUIDv7Generator() *UUIDv7Generator {
+	return &UUIDv7Generator{now: time.Now}
+}
+
+// NextID 实现 IdGenerator，返回 UUID，可写入 string 或 [16]byte 字段
+func (g *UUIDv7Generator) NextID() (interface{}, error) {
+	var u UUID
+	if _, err := rand.Read(u[6:]); err != nil {
+		return nil, fmt.Errorf("failed to generate uuidv7: %w", err)
+	}
+
+	ms := uint64(g.now().UnixMilli())
+	for i := 0; i < 6; i++ {
+		u[i] = byte(ms >> (40 - 8*i))
+	}
+	u[6] = u[6]&0x0f | 0x70 // 版本 7
+	u[8] = u[8]&0x3f | 0x80 // RFC 4122 变体
+
+	return u, nil
+}
+
+// crockford ULID 使用的 Crockford Base32 字母表
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ErrULIDOverflow 同一毫秒内生成的 ULID 超出随机部分的范围
+var ErrULIDOverflow = errors.New("ulid random component overflow")
+
+// ULIDGenerator 生成单调递增的 ULID，同一毫秒内随机部分递增
+type ULIDGenerator struct {
+	mu         sync.Mutex
+	lastMs     uint64
+	lastRandom [10]byte
+	now        func() time.Time
+}
+
+// NewULIDGenerator 创建 ULID 生成器
+func NewULIDGenerator() *ULIDGenerator {
+	return &ULIDGenerator{now: time.Now}
+}
+
+// NextID 实现 IdGenerator，返回 26 位字符串
+func (g *ULIDGenerator) NextID() (interface{}, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(g.now().UnixMilli())
+	if ms <= g.lastMs {
+		// 同一毫秒（或时钟回拨）时沿用上次时间戳并递增随机部分，保持单调
+		ms = g.lastMs
+		if !increment(g.lastRandom[:]) {
+			return nil, ErrULIDOverflow
+		}
+	} else if _, err := rand.Read(g.lastRandom[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate ulid: %w", err)
+	}
+	g.lastMs = ms
+
+	var id [16]byte
+	for i := 0; i < 6; i++ {
+		id[i] = byte(ms >> (40 - 8*i))
+	}
+	copy(id[6:], g.lastRandom[:])
+
+	return encodeULID(id), nil
+}
+
+// increment 将大端字节序整数加一，溢出时返回 false
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID 将 128 位数据编码为 26 位 Crockford Base32
+func encodeULID(id [16]byte) string {
+	var buf [26]byte
+	// 128 位数据左侧补 2 位 0，按 5 位一组从高位编码
+	var acc uint32
+	bits, pos := uint(2), 0
+	for _, b := range id {
+		acc = acc<<8 | uint32(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			buf[pos] = crockford[(acc>>bits)&0x1f]
+			pos++
+		}
+	}
+	return string(buf[:])
+}
@@ -0,0 +1,112 @@
+package idgen
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSnowflakeGenerator 测试 snowflake 主键递增且包含 workerID
+func TestSnowflakeGenerator(t *testing.T) {
+	if _, err := NewSnowflakeGenerator(1024); err == nil {
+		t.Fatal("Expected error for worker id out of range")
+	}
+
+	generator, err := NewSnowflakeGenerator(5)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var last int64
+	for i := 0; i < 10000; i++ {
+		id, err := generator.NextID()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		value := id.(int64)
+		if value <= last {
+			t.Fatalf("Expected increasing ids, got %d after %d", value, last)
+		}
+		if workerID := (value >> sequenceBits) & maxWorkerID; workerID != 5 {
+			t.Fatalf("Expected worker id 5, got %d", workerID)
+		}
+		last = value
+	}
+
+	// 时钟回拨时报错
+	generator.now = func() time.Time { return time.Now().Add(-time.Hour) }
+	if _, err := generator.NextID(); err == nil {
+		t.Fatal("Expected error when clock moves backwards")
+	}
+}
+
+// TestUUIDv7Generator 测试 UUIDv7 的版本、变体与格式
+func TestUUIDv7Generator(t *testing.T) {
+	generator := NewUUIDv7Generator()
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	first, _ := generator.NextID()
+	second, _ := generator.NextID()
+	for _, id := range []interface{}{first, second} {
+		if !pattern.MatchString(id.(UUID).String()) {
+			t.Fatalf("Invalid uuidv7: %s", id.(UUID))
+		}
+	}
+	if first == second {
+		t.Fatal("Expected distinct uuids")
+	}
+
+	// 时间戳位于前 48 位
+	generator.now = func() time.Time { return time.UnixMilli(0x0123456789ab) }
+	id, _ := generator.NextID()
+	if prefix := id.(UUID).String()[:13]; prefix != "01234567-89ab" {
+		t.Fatalf("Unexpected timestamp prefix: %s", prefix)
+	}
+}
+
+// TestULIDGenerator 测试 ULID 的编码与单调性
+func TestULIDGenerator(t *testing.T) {
+	if encoded := encodeULID([16]byte{}); encoded != strings.Repeat("0", 26) {
+		t.Fatalf("Unexpected zero ulid: %s", encoded)
+	}
+	var max [16]byte
+	for i := range max {
+		max[i] = 0xff
+	}
+	if encoded := encodeULID(max); encoded != "7"+strings.Repeat("Z", 25) {
+		t.Fatalf("Unexpected max ulid: %s", encoded)
+	}
+
+	generator := NewULIDGenerator()
+	fixed := time.Now()
+	generator.now = func() time.Time { return fixed }
+
+	var last string
+	for i := 0; i < 1000; i++ {
+		id, err := generator.NextID()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		value := id.(string)
+		if len(value) != 26 {
+			t.Fatalf("Expected 26 characters, got %s", value)
+		}
+		if value <= last {
+			t.Fatalf("Expected monotonic ulids within the same millisecond, got %s after %s", value, last)
+		}
+		last = value
+	}
+}
+
+// TestIdGeneratorFunc 测试函数形式的生成器
+func TestIdGeneratorFunc(t *testing.T) {
+	generator := IdGeneratorFunc(func() (interface{}, error) { return "fixed", nil })
+	if id, _ := generator.NextID(); id != "fixed" {
+		t.Fatalf("Unexpected id: %v", id)
+	}
+
+	if _, exists := Defaults()[Snowflake]; !exists {
+		t.Fatal("Expected built-in snowflake generator")
+	}
+}
@@ -0,0 +1,29 @@
+package mapping
+
+// ResultMap 结果映射配置，对应 XML 中的 <resultMap>
+type ResultMap struct {
+	ID       string
+	Type     string
+	Mappings []ResultMapping
+}
+
+// ResultMapping 单个列到字段的映射
+type ResultMapping struct {
+	Property    string // 结构体字段名
+	Column      string // 列名
+	JdbcType    string // JDBC 类型
+	TypeHandler string // 具名类型处理器
+}
+
+// GetMappingByColumn 根据列名获取映射
+func (r *ResultMap) GetMappingByColumn(column string) (*ResultMapping, bool) {
+	if r == nil {
+		return nil, false
+	}
+	for i := range r.Mappings {
+		if r.Mappings[i].Column == column {
+			return &r.Mappings[i], true
+		}
+	}
+	return nil, false
+}
@@ -1,25 +1,93 @@
 package mapping
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
+
+	"gobatis/logger"
+	"gobatis/reflection"
+	"gobatis/types"
 )
 
 // ResultMapper 结果映射器接口
 type ResultMapper interface {
 	MapResult(rows *sql.Rows, resultType reflect.Type) (interface{}, error)
 	MapResults(rows *sql.Rows, resultType reflect.Type) ([]interface{}, error)
+	MapResultsWithContext(rows *sql.Rows, mc MappingContext) ([]interface{}, error)
+}
+
+// MappingContext 结果映射上下文
+type MappingContext struct {
+	StatementID string       // 语句 ID，用于日志与错误信息
+	ResultType  reflect.Type // 结果类型
+	ResultMap   *ResultMap   // 结果映射配置，可以为空
 }
 
+// AutoMappingUnknownColumnBehavior 自动映射遇到未知列时的行为
+type AutoMappingUnknownColumnBehavior int
+
+const (
+	// AutoMappingUnknownColumnNone 忽略未知列
+	AutoMappingUnknownColumnNone AutoMappingUnknownColumnBehavior = iota
+	// AutoMappingUnknownColumnWarning 输出警告日志
+	AutoMappingUnknownColumnWarning
+	// AutoMappingUnknownColumnFailing 返回错误
+	AutoMappingUnknownColumnFailing
+)
+
 // DefaultResultMapper 默认结果映射器
-type DefaultResultMapper struct{}
+type DefaultResultMapper struct {
+	typeHandlers           *types.TypeHandlerRegistry
+	namingStrategy         reflection.NamingStrategy
+	logger                 logger.Interface
+	unknownColumnBehavior  AutoMappingUnknownColumnBehavior
+	requireAllFieldsMapped bool
+}
+
+// Options 结果映射器选项，零值字段使用默认值
+type Options struct {
+	TypeHandlers   *types.TypeHandlerRegistry
+	NamingStrategy reflection.NamingStrategy
+	Logger         logger.Interface
+	// AutoMappingUnknownColumnBehavior 结果集中存在无对应字段的列时的行为
+	AutoMappingUnknownColumnBehavior AutoMappingUnknownColumnBehavior
+	// RequireAllFieldsMapped 要求除 db:",optional" 外的所有字段都有对应的列
+	RequireAllFieldsMapped bool
+}
 
 // NewResultMapper 创建新的结果映射器
 func NewResultMapper() ResultMapper {
-	return &DefaultResultMapper{}
+	return NewResultMapperWithOptions(Options{})
+}
+
+// NewResultMapperWithTypeHandlers 创建使用指定类型处理器注册表的结果映射器
+func NewResultMapperWithTypeHandlers(typeHandlers *types.TypeHandlerRegistry) ResultMapper {
+	return NewResultMapperWithOptions(Options{TypeHandlers: typeHandlers})
+}
+
+// NewResultMapperWithOptions 创建使用指定选项的结果映射器
+func NewResultMapperWithOptions(options Options) ResultMapper {
+	if options.TypeHandlers == nil {
+		options.TypeHandlers = types.Default
+	}
+	if options.NamingStrategy == nil {
+		options.NamingStrategy = reflection.DefaultNamingStrategy
+	}
+	if options.Logger == nil {
+		options.Logger = logger.Default
+	}
+	return &DefaultResultMapper{
+		typeHandlers:           options.TypeHandlers,
+		namingStrategy:         options.NamingStrategy,
+		logger:                 options.Logger,
+		unknownColumnBehavior:  options.AutoMappingUnknownColumnBehavior,
+		requireAllFieldsMapped: options.RequireAllFieldsMapped,
+	}
 }
 
 // MapResult 映射单个结果
@@ -38,15 +106,39 @@ func (m *DefaultResultMapper) MapResult(rows *sql.Rows, resultType reflect.Type)
 
 // MapResults 映射多个结果
 func (m *DefaultResultMapper) MapResults(rows *sql.Rows, resultType reflect.Type) ([]interface{}, error) {
+	return m.MapResultsWithContext(rows, MappingContext{ResultType: resultType})
+}
+
+// MapResultsWithContext 按映射上下文映射多个结果
+func (m *DefaultResultMapper) MapResultsWithContext(rows *sql.Rows, mc MappingContext) ([]interface{}, error) {
+	resultType := mc.ResultType
+
 	columns, err := rows.Columns()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get columns: %w", err)
 	}
 
+	// 获取列的数据库类型，用于匹配类型处理器
+	jdbcTypes := make([]string, len(columns))
+	if columnTypes, err := rows.ColumnTypes(); err == nil {
+		for i, columnType := range columnTypes {
+			jdbcTypes[i] = columnType.DatabaseTypeName()
+		}
+	}
+
+	// 结构体结果的列映射在整个结果集内不变，只计算一次
+	var plans []columnPlan
+	if structType, ok := m.structResultType(resultType, jdbcTypes); ok {
+		plans, err = m.buildColumnPlans(mc, structType, columns, jdbcTypes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var results []interface{}
 
 	for rows.Next() {
-		result, err := m.scanRow(rows, columns, resultType)
+		result, err := m.scanRow(rows, columns, jdbcTypes, resultType, plans)
 		if err != nil {
 			return nil, err
 		}
@@ -60,8 +152,24 @@ func (m *DefaultResultMapper) MapResults(rows *sql.Rows, resultType reflect.Type
 	return results, nil
 }
 
+// structResultType 判断结果类型是否按结构体字段映射
+func (m *DefaultResultMapper) structResultType(resultType reflect.Type, jdbcTypes []string) (reflect.Type, bool) {
+	if resultType.Kind() == reflect.Ptr {
+		resultType = resultType.Elem()
+	}
+	if resultType.Kind() != reflect.Struct || implementsScanner(resultType) {
+		return nil, false
+	}
+	if len(jdbcTypes) > 0 {
+		if _, exists := m.typeHandlers.GetHandler(resultType, jdbcTypes[0]); exists {
+			return nil, false
+		}
+	}
+	return resultType, true
+}
+
 // scanRow 扫描单行数据
-func (m *DefaultResultMapper) scanRow(rows *sql.Rows, columns []string, resultType reflect.Type) (interface{}, error) {
+func (m *DefaultResultMapper) scanRow(rows *sql.Rows, columns []string, jdbcTypes []string, resultType reflect.Type, plans []columnPlan) (interface{}, error) {
 	// 创建结果对象
 	var result reflect.Value
 	var isPtr bool
@@ -74,6 +182,53 @@ func (m *DefaultResultMapper) scanRow(rows *sql.Rows, columns []string, resultTy
 		result = reflect.New(resultType)
 	}
 
+	// 如果是结构体，按预先计算的列映射扫描
+	if plans != nil {
+		err := m.scanStruct(rows, columns, result.Elem(), plans)
+		if err != nil {
+			return nil, err
+		}
+
+		if isPtr {
+			return result.Interface(), nil
+		}
+
+		return result.Elem().Interface(), nil
+	}
+
+	// 实现 sql.Scanner 的类型直接由驱动扫描
+	if implementsScanner(resultType) {
+		if err := rows.Scan(result.Interface()); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", resultType, err)
+		}
+
+		if isPtr {
+			return result.Interface(), nil
+		}
+
+		return result.Elem().Interface(), nil
+	}
+
+	// 注册了类型处理器的类型，扫描原始值后交由处理器转换
+	if handler, exists := m.typeHandlers.GetHandler(resultType, jdbcTypes[0]); exists {
+		var value interface{}
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", resultType, err)
+		}
+
+		convertedValue, err := handler.GetResult(value, resultType)
+		if err != nil {
+			return nil, err
+		}
+
+		if isPtr {
+			result.Elem().Set(reflect.ValueOf(convertedValue))
+			return result.Interface(), nil
+		}
+
+		return convertedValue, nil
+	}
+
 	// 如果是基础类型，直接扫描
 	if isBasicType(resultType.Kind()) {
 		var value interface{}
@@ -95,63 +250,228 @@ func (m *DefaultResultMapper) scanRow(rows *sql.Rows, columns []string, resultTy
 		return convertedValue, nil
 	}
 
-	// 如果是结构体，按字段映射
-	if resultType.Kind() == reflect.Struct {
-		err := m.scanStruct(rows, columns, result.Elem())
-		if err != nil {
-			return nil, err
+	return nil, fmt.Errorf("unsupported result type: %s", resultType.Kind())
+}
+
+// fieldBinding 列对应的字段及其映射选项
+type fieldBinding struct {
+	name        string
+	index       []int
+	fieldType   reflect.Type
+	jdbcType    string
+	typeHandler string
+}
+
+// structMeta 结构体的自动映射元数据，按类型和命名策略缓存
+type structMeta struct {
+	columns map[string]fieldBinding
+	folded  map[string][]string // 小写列名 -> 列名，按字段声明顺序
+}
+
+// metaKey 结构体元数据缓存键
+type metaKey struct {
+	structType     reflect.Type
+	namingStrategy reflection.NamingStrategy
+}
+
+// structMetaCache 结构体元数据缓存
+var structMetaCache sync.Map // map[metaKey]*structMeta
+
+// getStructMeta 获取结构体的自动映射元数据，不可比较的命名策略不缓存
+func getStructMeta(structType reflect.Type, namingStrategy reflection.NamingStrategy) *structMeta {
+	cacheable := reflect.TypeOf(namingStrategy).Comparable()
+	key := metaKey{structType: structType}
+	if cacheable {
+		key.namingStrategy = namingStrategy
+		if cached, ok := structMetaCache.Load(key); ok {
+			return cached.(*structMeta)
 		}
+	}
 
-		if isPtr {
-			return result.Interface(), nil
+	meta := &structMeta{
+		columns: make(map[string]fieldBinding),
+		folded:  make(map[string][]string),
+	}
+	for _, field := range reflection.StructFields(structType) {
+		// 获取字段对应的列名，未声明标签时按命名策略转换
+		columnName := field.Tag.Name
+		if columnName == "" {
+			columnName = namingStrategy.ColumnName(field.Name)
 		}
+		columnName = field.Prefix + columnName
 
-		return result.Elem().Interface(), nil
+		meta.columns[columnName] = fieldBinding{
+			name:        field.Name,
+			index:       field.Index,
+			fieldType:   field.Type,
+			typeHandler: field.Tag.TypeHandler(),
+		}
+		lower := strings.ToLower(columnName)
+		meta.folded[lower] = append(meta.folded[lower], columnName)
 	}
 
-	return nil, fmt.Errorf("unsupported result type: %s", resultType.Kind())
+	if !cacheable {
+		return meta
+	}
+	cached, _ := structMetaCache.LoadOrStore(key, meta)
+	return cached.(*structMeta)
 }
 
-// scanStruct 扫描结构体
-func (m *DefaultResultMapper) scanStruct(rows *sql.Rows, columns []string, structValue reflect.Value) error {
-	structType := structValue.Type()
+// matchColumn 按列名匹配字段，精确匹配优先，其次忽略大小写匹配
+func (m *DefaultResultMapper) matchColumn(meta *structMeta, structType reflect.Type, column string) (fieldBinding, bool) {
+	if binding, exists := meta.columns[column]; exists {
+		return binding, true
+	}
 
-	// 创建字段映射
-	fieldMap := make(map[string]reflect.Value)
-	for i := 0; i < structValue.NumField(); i++ {
-		field := structType.Field(i)
-		fieldValue := structValue.Field(i)
+	candidates := meta.folded[strings.ToLower(column)]
+	if len(candidates) == 0 {
+		return fieldBinding{}, false
+	}
+	binding := meta.columns[candidates[0]]
+	if len(candidates) > 1 {
+		m.logger.Warn(context.Background(), "column %s matches multiple fields of %s ignoring case %v, using %s",
+			column, structType, candidates, binding.name)
+	}
+	return binding, true
+}
+
+// scanMode 列的扫描方式
+type scanMode int
+
+const (
+	scanIgnore  scanMode = iota // 无对应字段，丢弃
+	scanHandler                 // 扫描原始值后交由类型处理器转换
+	scanDirect                  // 直接扫描到字段
+	scanConvert                 // 扫描到临时值后转换为字段类型
+)
+
+// columnPlan 单列的扫描计划
+type columnPlan struct {
+	mode      scanMode
+	index     []int
+	fieldType reflect.Type
+	handler   types.TypeHandler
+}
 
-		if !fieldValue.CanSet() {
+// buildColumnPlans 为结果集的每一列计算扫描计划
+func (m *DefaultResultMapper) buildColumnPlans(mc MappingContext, structType reflect.Type, columns []string, jdbcTypes []string) ([]columnPlan, error) {
+	meta := getStructMeta(structType, m.namingStrategy)
+	resultMap := mc.ResultMap
+
+	// 结果映射配置优先于自动映射，列名忽略大小写
+	var overrides map[string]fieldBinding
+	if resultMap != nil {
+		overrides = make(map[string]fieldBinding, len(resultMap.Mappings))
+		for _, mapping := range resultMap.Mappings {
+			field, exists := structType.FieldByName(mapping.Property)
+			if !exists || field.PkgPath != "" {
+				return nil, fmt.Errorf("property %s not found on %s for result map %s", mapping.Property, structType, resultMap.ID)
+			}
+			overrides[strings.ToLower(mapping.Column)] = fieldBinding{
+				name:        field.Name,
+				index:       field.Index,
+				fieldType:   field.Type,
+				jdbcType:    mapping.JdbcType,
+				typeHandler: mapping.TypeHandler,
+			}
+		}
+	}
+
+	plans := make([]columnPlan, len(columns))
+	for i, column := range columns {
+		binding, exists := overrides[strings.ToLower(column)]
+		if !exists {
+			binding, exists = m.matchColumn(meta, structType, column)
+		}
+		if !exists {
+			if err := m.handleUnknownColumn(mc, structType, column); err != nil {
+				return nil, err
+			}
 			continue
 		}
 
-		// 获取字段对应的列名
-		columnName := field.Name
-		if dbTag := field.Tag.Get("db"); dbTag != "" {
-			columnName = dbTag
-		} else {
-			// 转换为下划线命名
-			columnName = camelToSnake(field.Name)
+		handler, hasHandler, err := m.resolveHandler(binding, jdbcTypes[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve type handler for field %s: %w", column, err)
+		}
+
+		plan := columnPlan{index: binding.index, fieldType: binding.fieldType}
+		switch {
+		case hasHandler:
+			plan.mode = scanHandler
+			plan.handler = handler
+		case implementsScanner(binding.fieldType) || binding.fieldType.Kind() == reflect.Ptr:
+			// sql.Scanner 与指针字段直接扫描到字段，NULL 由驱动处理为零值或 nil
+			plan.mode = scanDirect
+		default:
+			plan.mode = scanConvert
+		}
+		plans[i] = plan
+	}
+
+	if m.requireAllFieldsMapped {
+		if err := checkAllFieldsMapped(mc, structType, plans); err != nil {
+			return nil, err
+		}
+	}
+
+	return plans, nil
+}
+
+// handleUnknownColumn 按配置处理没有对应字段的列
+func (m *DefaultResultMapper) handleUnknownColumn(mc MappingContext, structType reflect.Type, column string) error {
+	switch m.unknownColumnBehavior {
+	case AutoMappingUnknownColumnWarning:
+		m.logger.Warn(context.Background(), "statement %s: unknown column %s for %s", mc.StatementID, column, structType)
+	case AutoMappingUnknownColumnFailing:
+		return fmt.Errorf("statement %s: unknown column %s for %s", mc.StatementID, column, structType)
+	}
+	return nil
+}
+
+// checkAllFieldsMapped 检查除 db:",optional" 外的字段都有对应的列
+func checkAllFieldsMapped(mc MappingContext, structType reflect.Type, plans []columnPlan) error {
+	mapped := make(map[string]bool, len(plans))
+	for _, plan := range plans {
+		if plan.mode != scanIgnore {
+			mapped[fmt.Sprint(plan.index)] = true
 		}
+	}
 
-		fieldMap[columnName] = fieldValue
+	for _, field := range reflection.StructFields(structType) {
+		if field.Tag.HasOption("optional") || mapped[fmt.Sprint(field.Index)] {
+			continue
+		}
+		return fmt.Errorf("statement %s: field %s (%s) of %s has no matching column", mc.StatementID, field.Prefix+field.Name, field.Type, structType)
 	}
+	return nil
+}
 
+// scanStruct 扫描结构体
+func (m *DefaultResultMapper) scanStruct(rows *sql.Rows, columns []string, structValue reflect.Value, plans []columnPlan) error {
 	// 准备扫描目标
 	scanTargets := make([]interface{}, len(columns))
-	scanValues := make([]reflect.Value, len(columns))
+	fieldValues := make([]reflect.Value, len(columns))
+	var discard interface{}
 
-	for i, column := range columns {
-		if fieldValue, exists := fieldMap[column]; exists {
-			// 创建对应类型的指针用于扫描
-			scanValue := reflect.New(fieldValue.Type())
-			scanTargets[i] = scanValue.Interface()
-			scanValues[i] = scanValue
-		} else {
+	for i, plan := range plans {
+		switch plan.mode {
+		case scanIgnore:
 			// 如果没有对应字段，使用 interface{} 接收
-			var dummy interface{}
-			scanTargets[i] = &dummy
+			scanTargets[i] = &discard
+			continue
+		case scanHandler:
+			scanTargets[i] = new(interface{})
+		case scanConvert:
+			// 创建对应类型的指针用于扫描
+			scanTargets[i] = reflect.New(plan.fieldType).Interface()
+		}
+
+		// 获取字段值，必要时为嵌入的结构体指针分配内存
+		fieldValue, _ := reflection.FieldByIndex(structValue, plan.index, true)
+		fieldValues[i] = fieldValue
+		if plan.mode == scanDirect {
+			scanTargets[i] = fieldValue.Addr().Interface()
 		}
 	}
 
@@ -161,24 +481,74 @@ func (m *DefaultResultMapper) scanStruct(rows *sql.Rows, columns []string, struc
 	}
 
 	// 设置字段值
-	for i, column := range columns {
-		if fieldValue, exists := fieldMap[column]; exists && scanValues[i].IsValid() {
-			scannedValue := scanValues[i].Elem()
-			if scannedValue.IsValid() {
-				convertedValue, err := convertToFieldType(scannedValue.Interface(), fieldValue.Type())
-				if err != nil {
-					return fmt.Errorf("failed to convert value for field %s: %w", column, err)
-				}
-				if convertedValue != nil {
-					fieldValue.Set(reflect.ValueOf(convertedValue))
-				}
-			}
+	for i, plan := range plans {
+		var convertedValue interface{}
+		var err error
+		switch plan.mode {
+		case scanHandler:
+			raw := *(scanTargets[i].(*interface{}))
+			convertedValue, err = plan.handler.GetResult(raw, plan.fieldType)
+		case scanConvert:
+			convertedValue, err = convertToFieldType(reflect.ValueOf(scanTargets[i]).Elem().Interface(), plan.fieldType)
+		default:
+			// 无对应字段或已直接扫描到字段
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to convert value for field %s: %w", columns[i], err)
+		}
+
+		if err := setFieldValue(fieldValues[i], convertedValue); err != nil {
+			return fmt.Errorf("failed to set field %s: %w", columns[i], err)
 		}
 	}
 
 	return nil
 }
 
+// resolveHandler 解析字段使用的类型处理器，具名处理器优先
+func (m *DefaultResultMapper) resolveHandler(binding fieldBinding, columnJdbcType string) (types.TypeHandler, bool, error) {
+	if binding.typeHandler != "" {
+		handler, exists := m.typeHandlers.GetNamedHandler(binding.typeHandler)
+		if !exists {
+			return nil, false, fmt.Errorf("type handler not found: %s", binding.typeHandler)
+		}
+		return handler, true, nil
+	}
+
+	jdbcType := binding.jdbcType
+	if jdbcType == "" {
+		jdbcType = columnJdbcType
+	}
+	handler, exists := m.typeHandlers.GetHandler(binding.fieldType, jdbcType)
+	return handler, exists, nil
+}
+
+// setFieldValue 设置字段值，nil 表示保持零值
+func setFieldValue(fieldValue reflect.Value, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	if !v.Type().AssignableTo(fieldValue.Type()) {
+		return fmt.Errorf("cannot assign %s to %s", v.Type(), fieldValue.Type())
+	}
+	fieldValue.Set(v)
+	return nil
+}
+
+// scannerType sql.Scanner 接口类型
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// implementsScanner 判断类型（或其指针）是否实现 sql.Scanner
+func implementsScanner(t reflect.Type) bool {
+	if t.Implements(scannerType) {
+		return true
+	}
+	return t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(scannerType)
+}
+
 // convertToType 转换到指定类型
 func convertToType(value interface{}, targetType reflect.Type) (interface{}, error) {
 	if value == nil {
@@ -255,15 +625,3 @@ func isBasicType(kind reflect.Kind) bool {
 		return false
 	}
 }
-
-// camelToSnake 驼峰转下划线
-func camelToSnake(s string) string {
-	var result strings.Builder
-	for i, r := range s {
-		if i > 0 && r >= 'A' && r <= 'Z' {
-			result.WriteRune('_')
-		}
-		result.WriteRune(r)
-	}
-	return strings.ToLower(result.String())
-}
@@ -1,10 +1,18 @@
 package mapping
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"gobatis/logger"
+	"gobatis/reflection"
+	"gobatis/types"
+
 	"github.com/DATA-DOG/go-sqlmock"
 )
 
@@ -328,24 +336,6 @@ func TestIsBasicType(t *testing.T) {
 	}
 }
 
-func TestCamelToSnake(t *testing.T) {
-	testCases := map[string]string{
-		"ID":        "i_d",
-		"Name":      "name",
-		"FirstName": "first_name",
-		"CreatedAt": "created_at",
-		"XMLData":   "x_m_l_data",
-		"HTTPCode":  "h_t_t_p_code",
-	}
-
-	for input, expected := range testCases {
-		result := camelToSnake(input)
-		if result != expected {
-			t.Errorf("camelToSnake(%s) = %s, expected %s", input, result, expected)
-		}
-	}
-}
-
 func TestDefaultResultMapper_ScanStruct_WithDBTags(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -423,3 +413,580 @@ func TestDefaultResultMapper_ScanStruct_MissingColumns(t *testing.T) {
 		t.Errorf("Unexpected user values: %+v", user)
 	}
 }
+
+// TestProduct 使用类型处理器的测试结构体
+type TestProduct struct {
+	ID    int      `db:"id"`
+	Price string   `db:"price"`
+	Tags  []string `db:"tags,typeHandler=strings"`
+	Meta  Meta     `db:"meta"`
+}
+
+// Meta 测试 JSON 字段
+type Meta struct {
+	Color string `json:"color"`
+}
+
+func TestDefaultResultMapper_ScanStruct_TypeHandlers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRowsWithColumnDefinition(
+		sqlmock.NewColumn("id").OfType("INT", 0),
+		sqlmock.NewColumn("price").OfType("DECIMAL", ""),
+		sqlmock.NewColumn("tags").OfType("VARCHAR", ""),
+		sqlmock.NewColumn("meta").OfType("JSON", ""),
+	).AddRow(1, float64(0.0000001), []byte("red,green"), []byte(`{"color":"blue"}`))
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	queryRows, err := db.Query("SELECT id, price, tags, meta FROM products")
+	if err != nil {
+		t.Fatalf("Failed to execute query: %v", err)
+	}
+	defer queryRows.Close()
+
+	registry := types.NewTypeHandlerRegistry()
+	registry.Register(reflect.TypeOf(Meta{}), &types.JSONTypeHandler{})
+
+	mapper := NewResultMapperWithTypeHandlers(registry)
+	results, err := mapper.MapResults(queryRows, reflect.TypeOf(TestProduct{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	product := results[0].(TestProduct)
+	if product.Price != "0.0000001" {
+		t.Errorf("Expected decimal handler to format price, got: %s", product.Price)
+	}
+	if !reflect.DeepEqual(product.Tags, []string{"red", "green"}) {
+		t.Errorf("Expected tags to be split, got: %v", product.Tags)
+	}
+	if product.Meta.Color != "blue" {
+		t.Errorf("Expected meta to be decoded, got: %+v", product.Meta)
+	}
+}
+
+// Email 自定义 sql.Scanner 类型
+type Email struct {
+	Local  string
+	Domain string
+}
+
+// Scan 实现 sql.Scanner
+func (e *Email) Scan(src interface{}) error {
+	var text string
+	switch v := src.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("unsupported email source: %T", src)
+	}
+	parts := strings.SplitN(text, "@", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid email: %s", text)
+	}
+	e.Local, e.Domain = parts[0], parts[1]
+	return nil
+}
+
+// TestNullableUser 带 Null* 与 Scanner 字段的测试结构体
+type TestNullableUser struct {
+	ID       int            `db:"id"`
+	Nickname sql.NullString `db:"nickname"`
+	Age      *int           `db:"age"`
+	Bio      *string        `db:"bio"`
+	Email    Email          `db:"email"`
+	Backup   *Email         `db:"backup"`
+}
+
+func TestDefaultResultMapper_ScanStruct_ScannerAndNull(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "nickname", "age", "bio", "email", "backup"}).
+		AddRow(1, "johnny", int64(30), nil, "john@example.com", nil).
+		AddRow(2, nil, nil, []byte("hello"), "jane@example.org", "jane@backup.org")
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	queryRows, err := db.Query("SELECT id, nickname, age, bio, email, backup FROM users")
+	if err != nil {
+		t.Fatalf("Failed to execute query: %v", err)
+	}
+	defer queryRows.Close()
+
+	mapper := NewResultMapper()
+	results, err := mapper.MapResults(queryRows, reflect.TypeOf(TestNullableUser{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	first := results[0].(TestNullableUser)
+	if !first.Nickname.Valid || first.Nickname.String != "johnny" {
+		t.Errorf("Unexpected nickname: %+v", first.Nickname)
+	}
+	if first.Age == nil || *first.Age != 30 {
+		t.Errorf("Unexpected age: %v", first.Age)
+	}
+	if first.Bio != nil {
+		t.Errorf("Expected nil bio for NULL, got: %v", *first.Bio)
+	}
+	if first.Email.Domain != "example.com" {
+		t.Errorf("Expected scanner to parse email, got: %+v", first.Email)
+	}
+	if first.Backup != nil {
+		t.Errorf("Expected nil backup for NULL, got: %+v", first.Backup)
+	}
+
+	second := results[1].(TestNullableUser)
+	if second.Nickname.Valid {
+		t.Errorf("Expected invalid nickname for NULL, got: %+v", second.Nickname)
+	}
+	if second.Age != nil {
+		t.Errorf("Expected nil age for NULL, got: %v", *second.Age)
+	}
+	if second.Bio == nil || *second.Bio != "hello" {
+		t.Errorf("Unexpected bio: %v", second.Bio)
+	}
+	if second.Backup == nil || second.Backup.Domain != "backup.org" {
+		t.Errorf("Expected scanner pointer to be allocated, got: %+v", second.Backup)
+	}
+}
+
+func TestDefaultResultMapper_MapResults_ScannerResultType(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"nickname"}).AddRow("johnny").AddRow(nil)
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	queryRows, err := db.Query("SELECT nickname FROM users")
+	if err != nil {
+		t.Fatalf("Failed to execute query: %v", err)
+	}
+	defer queryRows.Close()
+
+	mapper := NewResultMapper()
+	results, err := mapper.MapResults(queryRows, reflect.TypeOf(sql.NullString{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if first := results[0].(sql.NullString); !first.Valid || first.String != "johnny" {
+		t.Errorf("Unexpected first result: %+v", first)
+	}
+	if second := results[1].(sql.NullString); second.Valid {
+		t.Errorf("Expected invalid second result, got: %+v", second)
+	}
+}
+
+// TestAccount 带 JSON 字段的测试结构体
+type TestAccount struct {
+	ID       int               `db:"id"`
+	Settings Meta              `db:"settings,json"`
+	Labels   map[string]string `db:"labels,json"`
+	Scores   []int             `db:"scores,json"`
+	Profile  *Meta
+}
+
+func TestDefaultResultMapper_ScanStruct_JSONTag(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "settings", "labels", "scores", "profile_json"}).
+		AddRow(1, []byte(`{"color":"red"}`), `{"env":"prod"}`, []byte(`[1,2,3]`), []byte(`{"color":"green"}`)).
+		AddRow(2, nil, nil, nil, nil)
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	queryRows, err := db.Query("SELECT id, settings, labels, scores, profile_json FROM accounts")
+	if err != nil {
+		t.Fatalf("Failed to execute query: %v", err)
+	}
+	defer queryRows.Close()
+
+	resultMap := &ResultMap{
+		ID: "AccountMapper.AccountMap",
+		Mappings: []ResultMapping{
+			{Property: "Profile", Column: "profile_json", TypeHandler: "json"},
+		},
+	}
+
+	mapper := NewResultMapper()
+	results, err := mapper.MapResultsWithContext(queryRows, MappingContext{ResultType: reflect.TypeOf(TestAccount{}), ResultMap: resultMap})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	account := results[0].(TestAccount)
+	if account.Settings.Color != "red" {
+		t.Errorf("Unexpected settings: %+v", account.Settings)
+	}
+	if account.Labels["env"] != "prod" {
+		t.Errorf("Unexpected labels: %v", account.Labels)
+	}
+	if !reflect.DeepEqual(account.Scores, []int{1, 2, 3}) {
+		t.Errorf("Unexpected scores: %v", account.Scores)
+	}
+	if account.Profile == nil || account.Profile.Color != "green" {
+		t.Errorf("Expected result map to decode profile, got: %+v", account.Profile)
+	}
+
+	empty := results[1].(TestAccount)
+	if empty.Labels != nil || empty.Scores != nil || empty.Profile != nil {
+		t.Errorf("Expected NULL json columns to leave zero values, got: %+v", empty)
+	}
+}
+
+func TestDefaultResultMapper_ResultMap_UnknownProperty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	queryRows, err := db.Query("SELECT id FROM accounts")
+	if err != nil {
+		t.Fatalf("Failed to execute query: %v", err)
+	}
+	defer queryRows.Close()
+
+	resultMap := &ResultMap{
+		ID:       "AccountMapper.AccountMap",
+		Mappings: []ResultMapping{{Property: "Missing", Column: "id"}},
+	}
+
+	mapper := NewResultMapper()
+	if _, err := mapper.MapResultsWithContext(queryRows, MappingContext{ResultType: reflect.TypeOf(TestAccount{}), ResultMap: resultMap}); err == nil {
+		t.Fatal("Expected error for unknown result map property")
+	}
+}
+
+// BaseEntity 公共实体字段
+type BaseEntity struct {
+	ID        int       `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Address 地址
+type Address struct {
+	City string `db:"city"`
+}
+
+// TestOrder 带嵌入结构体的测试结构体
+type TestOrder struct {
+	*BaseEntity
+	Name     string  `db:"name"`
+	Billing  Address `db:",prefix=billing_"`
+	Shipping Address `db:",prefix=shipping_"`
+	Secret   string  `db:"-"`
+}
+
+func TestDefaultResultMapper_ScanStruct_Embedded(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	createdAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "created_at", "name", "billing_city", "shipping_city", "secret"}).
+		AddRow(1, createdAt, "order-1", "Berlin", "Paris", "hidden")
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	queryRows, err := db.Query("SELECT * FROM orders")
+	if err != nil {
+		t.Fatalf("Failed to execute query: %v", err)
+	}
+	defer queryRows.Close()
+
+	mapper := NewResultMapper()
+	results, err := mapper.MapResults(queryRows, reflect.TypeOf(&TestOrder{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	order := results[0].(*TestOrder)
+	if order.BaseEntity == nil || order.ID != 1 || !order.CreatedAt.Equal(createdAt) {
+		t.Errorf("Expected embedded BaseEntity to be populated, got: %+v", order.BaseEntity)
+	}
+	if order.Name != "order-1" {
+		t.Errorf("Unexpected name: %s", order.Name)
+	}
+	if order.Billing.City != "Berlin" || order.Shipping.City != "Paris" {
+		t.Errorf("Unexpected addresses: %+v / %+v", order.Billing, order.Shipping)
+	}
+	if order.Secret != "" {
+		t.Errorf("Expected db:\"-\" field to be skipped, got: %s", order.Secret)
+	}
+}
+
+func TestGetStructMeta_Cached(t *testing.T) {
+	structType := reflect.TypeOf(TestUser{})
+
+	meta := getStructMeta(structType, reflection.SnakeCaseStrategy{})
+	if _, exists := meta.columns["created_at"]; !exists {
+		t.Fatalf("Expected created_at column, got: %v", meta.columns)
+	}
+
+	if getStructMeta(structType, reflection.SnakeCaseStrategy{}) != meta {
+		t.Error("Expected struct metadata to be cached")
+	}
+}
+
+// TestAcronymUser 带缩写词字段、未声明标签的测试结构体
+type TestAcronymUser struct {
+	UserID    int
+	HTTPCode  int
+	FirstName string
+}
+
+func TestDefaultResultMapper_CaseInsensitiveColumns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// Oracle 风格的大写列名
+	rows := sqlmock.NewRows([]string{"USER_ID", "HTTP_CODE", "FIRST_NAME"}).
+		AddRow(7, 200, "John")
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	queryRows, err := db.Query("SELECT * FROM users")
+	if err != nil {
+		t.Fatalf("Failed to execute query: %v", err)
+	}
+	defer queryRows.Close()
+
+	mapper := NewResultMapper()
+	result, err := mapper.MapResult(queryRows, reflect.TypeOf(TestAcronymUser{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	user := result.(TestAcronymUser)
+	if user.UserID != 7 || user.HTTPCode != 200 || user.FirstName != "John" {
+		t.Errorf("Unexpected result: %+v", user)
+	}
+}
+
+func TestDefaultResultMapper_NamingStrategy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"userID", "httpCode", "firstName"}).
+		AddRow(7, 200, "John")
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	queryRows, err := db.Query("SELECT * FROM users")
+	if err != nil {
+		t.Fatalf("Failed to execute query: %v", err)
+	}
+	defer queryRows.Close()
+
+	mapper := NewResultMapperWithOptions(Options{NamingStrategy: reflection.LowerCamelStrategy{}})
+	result, err := mapper.MapResult(queryRows, reflect.TypeOf(TestAcronymUser{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	user := result.(TestAcronymUser)
+	if user.UserID != 7 || user.HTTPCode != 200 || user.FirstName != "John" {
+		t.Errorf("Unexpected result: %+v", user)
+	}
+}
+
+// testLogWriter 记录日志输出
+type testLogWriter struct {
+	messages []string
+}
+
+func (w *testLogWriter) Printf(format string, args ...interface{}) {
+	w.messages = append(w.messages, fmt.Sprintf(format, args...))
+}
+
+func TestDefaultResultMapper_AmbiguousColumn(t *testing.T) {
+	type ambiguous struct {
+		Code      string `db:"code"`
+		LowerCode string `db:"CODE"`
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"Code"}).AddRow("A")
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	queryRows, err := db.Query("SELECT * FROM codes")
+	if err != nil {
+		t.Fatalf("Failed to execute query: %v", err)
+	}
+	defer queryRows.Close()
+
+	writer := &testLogWriter{}
+	mapper := NewResultMapperWithOptions(Options{Logger: logger.New(writer, logger.Config{LogLevel: logger.Warn})})
+	result, err := mapper.MapResult(queryRows, reflect.TypeOf(ambiguous{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// 歧义时使用先声明的字段并输出警告
+	if value := result.(ambiguous); value.Code != "A" || value.LowerCode != "" {
+		t.Errorf("Unexpected result: %+v", value)
+	}
+	if len(writer.messages) != 1 || !strings.Contains(writer.messages[0], "matches multiple fields") {
+		t.Errorf("Expected ambiguity warning, got: %v", writer.messages)
+	}
+}
+
+// queryUsers 执行返回指定列的查询
+func queryUsers(t *testing.T, columns []string, values ...driver.Value) (*sql.DB, *sql.Rows) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows(columns).AddRow(values...))
+
+	rows, err := db.Query("SELECT * FROM users")
+	if err != nil {
+		t.Fatalf("Failed to execute query: %v", err)
+	}
+	return db, rows
+}
+
+func TestDefaultResultMapper_UnknownColumnBehavior(t *testing.T) {
+	columns := []string{"id", "name", "email", "age", "created_at", "nickname"}
+	values := []driver.Value{1, "John", "john@example.com", 30, time.Now(), "Johnny"}
+	mc := MappingContext{StatementID: "UserMapper.GetUser", ResultType: reflect.TypeOf(TestUser{})}
+
+	// 默认忽略未知列
+	db, rows := queryUsers(t, columns, values...)
+	if _, err := NewResultMapper().MapResultsWithContext(rows, mc); err != nil {
+		t.Errorf("Expected unknown column to be ignored, got: %v", err)
+	}
+	db.Close()
+
+	// WARNING 输出警告日志
+	db, rows = queryUsers(t, columns, values...)
+	writer := &testLogWriter{}
+	mapper := NewResultMapperWithOptions(Options{
+		Logger:                           logger.New(writer, logger.Config{LogLevel: logger.Warn}),
+		AutoMappingUnknownColumnBehavior: AutoMappingUnknownColumnWarning,
+	})
+	if _, err := mapper.MapResultsWithContext(rows, mc); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if len(writer.messages) != 1 || !strings.Contains(writer.messages[0], "unknown column nickname") {
+		t.Errorf("Expected unknown column warning, got: %v", writer.messages)
+	}
+	db.Close()
+
+	// FAILING 返回包含语句 ID、列名和类型的错误
+	db, rows = queryUsers(t, columns, values...)
+	mapper = NewResultMapperWithOptions(Options{AutoMappingUnknownColumnBehavior: AutoMappingUnknownColumnFailing})
+	_, err := mapper.MapResultsWithContext(rows, mc)
+	if err == nil {
+		t.Fatal("Expected error for unknown column")
+	}
+	for _, expected := range []string{"UserMapper.GetUser", "nickname", "mapping.TestUser"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to contain %q, got: %v", expected, err)
+		}
+	}
+	db.Close()
+}
+
+// TestProfile 带可选字段的测试结构体
+type TestProfile struct {
+	ID       int    `db:"id"`
+	Name     string `db:"name"`
+	Nickname string `db:"nickname,optional"`
+}
+
+func TestDefaultResultMapper_RequireAllFieldsMapped(t *testing.T) {
+	mapper := NewResultMapperWithOptions(Options{RequireAllFieldsMapped: true})
+	mc := MappingContext{StatementID: "ProfileMapper.GetProfile", ResultType: reflect.TypeOf(&TestProfile{})}
+
+	// 可选字段缺失不报错
+	db, rows := queryUsers(t, []string{"id", "name"}, 1, "John")
+	if _, err := mapper.MapResultsWithContext(rows, mc); err != nil {
+		t.Errorf("Expected optional field to be allowed missing, got: %v", err)
+	}
+	db.Close()
+
+	// 必填字段缺失时报错
+	db, rows = queryUsers(t, []string{"id", "full_name"}, 1, "John")
+	_, err := mapper.MapResultsWithContext(rows, mc)
+	if err == nil {
+		t.Fatal("Expected error for unmapped field")
+	}
+	for _, expected := range []string{"ProfileMapper.GetProfile", "field Name (string)", "mapping.TestProfile"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to contain %q, got: %v", expected, err)
+		}
+	}
+	db.Close()
+}
+
+// benchmarkRows 构造基准测试使用的结果集
+func benchmarkRows(b *testing.B, rowCount int) (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		b.Fatalf("Failed to create mock: %v", err)
+	}
+
+	createdAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < b.N; i++ {
+		rows := sqlmock.NewRows([]string{"id", "name", "email", "age", "created_at"})
+		for j := 0; j < rowCount; j++ {
+			rows.AddRow(j, "user", "user@example.com", 30, createdAt)
+		}
+		mock.ExpectQuery("SELECT").WillReturnRows(rows)
+	}
+	return db, mock
+}
+
+// BenchmarkMapResults_Struct 映射结构体结果，按行统计内存分配
+func BenchmarkMapResults_Struct(b *testing.B) {
+	const rowCount = 100
+	db, _ := benchmarkRows(b, rowCount)
+	defer db.Close()
+
+	mapper := NewResultMapper()
+	resultType := reflect.TypeOf(&TestUser{})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows, err := db.Query("SELECT * FROM users")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := mapper.MapResults(rows, resultType); err != nil {
+			b.Fatal(err)
+		}
+		rows.Close()
+	}
+}
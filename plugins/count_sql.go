@@ -0,0 +1,67 @@
+package plugins
+
+import (
+	"strings"
+
+	"gobatis/dialect"
+)
+
+// stripOrderBy 移除位于语句末尾的顶层 ORDER BY，其后有 LIMIT 等子句时保留
+func stripOrderBy(sql string) string {
+	keywords := dialect.TopLevelKeywords(sql)
+	index := dialect.FindKeywords(keywords, "ORDER", "BY")
+	if index < 0 {
+		return sql
+	}
+	for _, keyword := range keywords[index+2:] {
+		switch keyword.Word {
+		case "LIMIT", "OFFSET", "FETCH", "FOR":
+			return sql
+		}
+	}
+	return strings.TrimSpace(sql[:keywords[index].Start])
+}
+
+// complexKeywords 出现在顶层时需要包装为子查询才能正确计数的关键字
+var complexKeywords = map[string]bool{
+	"DISTINCT":  true,
+	"GROUP":     true,
+	"HAVING":    true,
+	"UNION":     true,
+	"INTERSECT": true,
+	"EXCEPT":    true,
+	"LIMIT":     true,
+	"OFFSET":    true,
+	"FETCH":     true,
+	"TOP":       true,
+}
+
+// deriveCountSQL 由查询语句推导计数语句：简单查询替换选择列表为 COUNT(*)，
+// 含 DISTINCT、GROUP BY、UNION、CTE 等的查询包装为 SELECT COUNT(*) FROM (...) t
+func deriveCountSQL(sql string) string {
+	sql = strings.TrimRight(strings.TrimSpace(sql), ";")
+	if sql == "" {
+		return ""
+	}
+	sql = stripOrderBy(sql)
+
+	keywords := dialect.TopLevelKeywords(sql)
+	if len(keywords) == 0 || keywords[0].Word != "SELECT" {
+		return wrapCountSQL(sql)
+	}
+	for _, keyword := range keywords {
+		if complexKeywords[keyword.Word] {
+			return wrapCountSQL(sql)
+		}
+	}
+
+	from := dialect.FindKeywords(keywords, "FROM")
+	if from < 0 {
+		return wrapCountSQL(sql)
+	}
+	return "SELECT COUNT(*) " + sql[keywords[from].Start:]
+}
+
+func wrapCountSQL(sql string) string {
+	return "SELECT COUNT(*) FROM (" + sql + ") t"
+}
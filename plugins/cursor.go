@@ -0,0 +1,406 @@
+package plugins
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gobatis/core/example"
+	"gobatis/dialect"
+	"gobatis/reflection"
+)
+
+// CursorPageRequest 键集（游标）分页请求，按排序列的值定位，避免深分页时的 OFFSET 扫描
+type CursorPageRequest struct {
+	After    string   `json:"after"`    // 取自上一次结果的 NextCursor，向后翻页
+	Before   string   `json:"before"`   // 取自上一次结果的 PrevCursor，向前翻页
+	Size     int      `json:"size"`     // 每页大小
+	SortKeys []string `json:"sortKeys"` // 排序列，组合后需唯一且值不能为 NULL，如 created_at, id
+	Desc     bool     `json:"desc"`     // 按排序列降序
+}
+
+// CursorPageResult 键集分页结果
+type CursorPageResult struct {
+	Data       interface{} `json:"data"`                 // 数据列表
+	Size       int         `json:"size"`                 // 每页大小
+	NextCursor string      `json:"nextCursor,omitempty"` // 下一页游标
+	PrevCursor string      `json:"prevCursor,omitempty"` // 上一页游标
+	HasNext    bool        `json:"hasNext"`              // 是否有下一页
+	HasPrev    bool        `json:"hasPrev"`              // 是否有上一页
+}
+
+// cursorParameterPrefix 改写后 SQL 中游标值占位符的参数名前缀
+const cursorParameterPrefix = "__cursor_"
+
+// validate 校验分页大小与排序列
+func (r *CursorPageRequest) validate() error {
+	if r.Size <= 0 || r.Size > 1000 {
+		return fmt.Errorf("cursor page size must be between 1 and 1000, got %d", r.Size)
+	}
+	if len(r.SortKeys) == 0 {
+		return fmt.Errorf("cursor pagination requires sort keys")
+	}
+	for _, key := range r.SortKeys {
+		if !sortKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid sort key %q", key)
+		}
+	}
+	if r.After != "" && r.Before != "" {
+		return fmt.Errorf("cursor page request cannot set both after and before")
+	}
+	return nil
+}
+
+// seek 解码游标，返回排序列的值、是否向前翻页
+func (r *CursorPageRequest) seek() ([]interface{}, bool, error) {
+	cursor, backward := r.After, false
+	if r.Before != "" {
+		cursor, backward = r.Before, true
+	}
+	if cursor == "" {
+		return nil, false, nil
+	}
+
+	values, err := DecodeCursor(cursor)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(values) != len(r.SortKeys) {
+		return nil, false, fmt.Errorf("cursor has %d values, expected %d sort keys", len(values), len(r.SortKeys))
+	}
+	for i, value := range values {
+		if value == nil {
+			return nil, false, fmt.Errorf("cursor value of sort key %s is null", r.SortKeys[i])
+		}
+	}
+	return values, backward, nil
+}
+
+// descending 实际查询的排序方向，向前翻页时与请求的方向相反
+func (r *CursorPageRequest) descending(backward bool) bool {
+	return r.Desc != backward
+}
+
+// orderByClause 构建排序子句
+func orderByClause(columns []string, descending bool) string {
+	direction := " ASC"
+	if descending {
+		direction = " DESC"
+	}
+	return strings.Join(columns, direction+", ") + direction
+}
+
+// unqualified 去掉排序列的表名前缀，用于引用子查询的结果列
+func unqualified(columns []string) []string {
+	result := make([]string, len(columns))
+	for i, column := range columns {
+		result[i] = column[strings.LastIndexByte(column, '.')+1:]
+	}
+	return result
+}
+
+// ApplyTo 将键集条件、排序与多取一行的限制写入 Example，查询结果交由 NewCursorPageResult 处理
+func (r *CursorPageRequest) ApplyTo(e *example.Example) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+	values, backward, err := r.seek()
+	if err != nil {
+		return err
+	}
+
+	descending := r.descending(backward)
+	if values != nil {
+		e.SetSeek(r.SortKeys, values, descending)
+	}
+	e.SetOrderByClause(orderByClause(r.SortKeys, descending))
+	e.SetLimit(0, r.Size+1)
+	return nil
+}
+
+// buildCursorSQL 将查询包装为子查询并追加键集条件、排序与方言的分页子句，游标值与行数以具名占位符绑定
+func (r *CursorPageRequest) buildCursorSQL(originalSQL string, values []interface{}, backward bool, d dialect.Dialect) (string, map[string]interface{}) {
+	descending := r.descending(backward)
+	columns := unqualified(r.SortKeys)
+	var b strings.Builder
+	b.WriteString("SELECT * FROM (")
+	b.WriteString(stripOrderBy(strings.TrimRight(strings.TrimSpace(originalSQL), ";")))
+	b.WriteString(") t")
+
+	additional := map[string]interface{}{limitParameter: r.Size + 1}
+	if values != nil {
+		operator := ">"
+		if descending {
+			operator = "<"
+		}
+		placeholders := make([]string, len(values))
+		for i, value := range values {
+			name := fmt.Sprintf("%s%d", cursorParameterPrefix, i)
+			placeholders[i] = "#{" + name + "}"
+			additional[name] = value
+		}
+		b.WriteString(" WHERE ")
+		b.WriteString(seekPredicate(columns, placeholders, operator, d.SupportsRowValues()))
+	}
+
+	fmt.Fprintf(&b, " ORDER BY %s", orderByClause(columns, descending))
+	return d.PagingSQL(b.String(), "#{"+limitParameter+"}", ""), additional
+}
+
+// seekPredicate 构建键集条件，方言不支持行值比较时将 (k1, k2) > (v1, v2)
+// 展开为 (k1 > v1) OR (k1 = v1 AND k2 > v2)
+func seekPredicate(columns, placeholders []string, operator string, rowValues bool) string {
+	if rowValues {
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), operator, strings.Join(placeholders, ", "))
+	}
+	branches := make([]string, len(columns))
+	for i := range columns {
+		terms := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			terms = append(terms, columns[j]+" = "+placeholders[j])
+		}
+		terms = append(terms, columns[i]+" "+operator+" "+placeholders[i])
+		branches[i] = "(" + strings.Join(terms, " AND ") + ")"
+	}
+	return "(" + strings.Join(branches, " OR ") + ")"
+}
+
+// NewCursorPageResult 由多取一行的查询结果构建分页结果，排序列的值取自结果的 db 标签或按命名策略匹配的字段
+func NewCursorPageResult(request *CursorPageRequest, rows []interface{}, naming reflection.NamingStrategy) (*CursorPageResult, error) {
+	if naming == nil {
+		naming = reflection.DefaultNamingStrategy
+	}
+	backward := request.Before != ""
+
+	hasMore := len(rows) > request.Size
+	if hasMore {
+		rows = rows[:request.Size]
+	}
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	result := &CursorPageResult{
+		Data:    rows,
+		Size:    request.Size,
+		HasNext: hasMore,
+		HasPrev: request.After != "",
+	}
+	if backward {
+		result.HasNext, result.HasPrev = true, hasMore
+	}
+	if len(rows) == 0 {
+		return result, nil
+	}
+
+	var err error
+	if result.HasNext {
+		if result.NextCursor, err = rowCursor(rows[len(rows)-1], request.SortKeys, naming); err != nil {
+			return nil, err
+		}
+	}
+	if result.HasPrev {
+		if result.PrevCursor, err = rowCursor(rows[0], request.SortKeys, naming); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// rowCursor 以行中排序列的值生成游标
+func rowCursor(row interface{}, columns []string, naming reflection.NamingStrategy) (string, error) {
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		value, err := columnValue(row, column, naming)
+		if err != nil {
+			return "", err
+		}
+		// 与 NULL 比较的条件不成立，游标无法定位到之后的行
+		if rv := reflect.ValueOf(value); !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+			return "", fmt.Errorf("sort key %s is null; keyset pagination requires non-null sort keys", column)
+		}
+		values[i] = value
+	}
+	return EncodeCursor(values)
+}
+
+// columnValue 获取结果行中列对应的值，map 按键、结构体按 db 标签或命名策略匹配
+func columnValue(row interface{}, column string, naming reflection.NamingStrategy) (interface{}, error) {
+	// 带表名前缀的排序列按列名匹配
+	if dot := strings.LastIndexByte(column, '.'); dot >= 0 {
+		column = column[dot+1:]
+	}
+
+	v := reflect.ValueOf(row)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, fmt.Errorf("cannot read sort key %s from nil row", column)
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() == reflect.String {
+			for _, key := range v.MapKeys() {
+				if strings.EqualFold(key.String(), column) {
+					return v.MapIndex(key).Interface(), nil
+				}
+			}
+		}
+	case reflect.Struct:
+		for _, field := range reflection.StructFields(v.Type()) {
+			name := field.Tag.Name
+			if name == "" {
+				name = naming.ColumnName(field.Name)
+			}
+			if strings.EqualFold(field.Prefix+name, column) || strings.EqualFold(field.Name, column) {
+				if fieldValue, ok := reflection.FieldByIndex(v, field.Index, false); ok {
+					return fieldValue.Interface(), nil
+				}
+				return nil, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("sort key %s not found in result %s", column, v.Type())
+}
+
+// EncodeCursor 将排序列的值编码为不透明的游标
+func EncodeCursor(values []interface{}) (string, error) {
+	encoded := make([]interface{}, len(values))
+	for i, value := range values {
+		if valuer, ok := value.(driver.Valuer); ok {
+			v, err := valuer.Value()
+			if err != nil {
+				return "", fmt.Errorf("failed to encode cursor value: %w", err)
+			}
+			value = v
+		}
+		if rv := reflect.ValueOf(value); rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				value = nil
+			} else {
+				value = rv.Elem().Interface()
+			}
+		}
+
+		switch v := value.(type) {
+		case time.Time:
+			encoded[i] = map[string]string{"t": v.Format(time.RFC3339Nano)}
+		case []byte:
+			encoded[i] = map[string]string{"b": base64.StdEncoding.EncodeToString(v)}
+		default:
+			encoded[i] = v
+		}
+	}
+
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor 解码游标，整数解码为 int64，时间解码为 time.Time
+func DecodeCursor(cursor string) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	var encoded []interface{}
+	if err := decoder.Decode(&encoded); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	values := make([]interface{}, len(encoded))
+	for i, value := range encoded {
+		switch v := value.(type) {
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				values[i] = n
+			} else if f, err := v.Float64(); err == nil {
+				values[i] = f
+			} else {
+				return nil, fmt.Errorf("invalid cursor number %s", v)
+			}
+		case map[string]interface{}:
+			if s, ok := v["t"].(string); ok {
+				t, err := time.Parse(time.RFC3339Nano, s)
+				if err != nil {
+					return nil, fmt.Errorf("invalid cursor time: %w", err)
+				}
+				values[i] = t
+			} else if s, ok := v["b"].(string); ok {
+				b, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					return nil, fmt.Errorf("invalid cursor bytes: %w", err)
+				}
+				values[i] = b
+			} else {
+				return nil, fmt.Errorf("invalid cursor value %v", v)
+			}
+		default:
+			values[i] = v
+		}
+	}
+	return values, nil
+}
+
+// interceptCursor 以键集条件改写语句并执行，返回 *CursorPageResult
+func (p *PaginationPlugin) interceptCursor(invocation *Invocation, request *CursorPageRequest) (interface{}, error) {
+	if err := request.validate(); err != nil {
+		return nil, err
+	}
+	values, backward, err := request.seek()
+	if err != nil {
+		return nil, err
+	}
+
+	statement := invocation.Statement
+	if statement == nil {
+		return nil, fmt.Errorf("cursor pagination requires the statement")
+	}
+
+	// 改写语句，游标值与行数作为附加参数与原参数一起绑定
+	pagedSQL, additional := request.buildCursorSQL(statement.SQL, values, backward, pagingDialect(invocation.Configuration))
+	paged := *statement
+	paged.SQL = pagedSQL
+	invocation.Statement = &paged
+	for name, value := range additional {
+		invocation.Parameter = withAdditionalParameter(invocation.Parameter, name, value)
+	}
+
+	result, err := invocation.Proceed()
+	if err != nil {
+		return nil, err
+	}
+	rows, ok := result.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type for cursor pagination: %T", result)
+	}
+
+	var naming reflection.NamingStrategy
+	if invocation.Configuration != nil {
+		naming = invocation.Configuration.NamingStrategy
+	}
+	return NewCursorPageResult(request, rows, naming)
+}
+
+// extractCursorPageRequest 从参数中提取键集分页请求
+func (p *PaginationPlugin) extractCursorPageRequest(args []interface{}) *CursorPageRequest {
+	for _, arg := range requestArgs(args) {
+		if request, ok := arg.(*CursorPageRequest); ok && request != nil {
+			return request
+		}
+	}
+	return nil
+}
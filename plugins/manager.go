@@ -4,19 +4,71 @@ import (
 	"reflect"
 	"sort"
 	"sync"
+	"sync/atomic"
+
+	"gobatis/core/config"
+	"gobatis/core/executor"
 )
 
-// PluginManager 插件管理器 - 线程安全版本
+// PluginManager 插件管理器 - 线程安全版本，插件变更时为各拦截点预先组合插件链
 type PluginManager struct {
 	plugins []Plugin
 	mutex   sync.RWMutex
+	chains  atomic.Pointer[chainSet]
+}
+
+// chainSet 插件变更时构建的不可变快照
+type chainSet struct {
+	// chains 已知拦截点的插件链，没有插件拦截时为 nil
+	chains map[Signature]*PluginChain
+	// plugins 构建快照时的插件，用于未预先组合的拦截点
+	plugins []Plugin
+	// scoped 是否有插件限定了适用的语句
+	scoped bool
+	// statementChains 按语句筛选后的插件链，键为 statementKey
+	statementChains sync.Map
+}
+
+// statementKey 决定语句适用哪些插件的属性
+type statementKey struct {
+	signature     Signature
+	id            string
+	namespace     string
+	statementType config.StatementType
+	plugins       string
+}
+
+// interceptionPoints 预先组合插件链的拦截点
+var interceptionPoints = []Signature{
+	{Target: TargetSqlSession, Method: "SelectOne"},
+	{Target: TargetSqlSession, Method: "SelectList"},
+	{Target: TargetSqlSession, Method: "Insert"},
+	{Target: TargetSqlSession, Method: "Update"},
+	{Target: TargetSqlSession, Method: "Delete"},
+	{Target: TargetExecutor, Method: executor.MethodQuery},
+	{Target: TargetExecutor, Method: executor.MethodUpdate},
+	{Target: TargetParameterHandler, Method: executor.MethodSetParameters},
+	{Target: TargetStatementHandler, Method: executor.MethodQuery},
+	{Target: TargetStatementHandler, Method: executor.MethodUpdate},
+	{Target: TargetResultSetHandler, Method: executor.MethodHandleResultSets},
 }
 
 // NewPluginManager 创建插件管理器
 func NewPluginManager() *PluginManager {
-	return &PluginManager{
+	pm := &PluginManager{
 		plugins: make([]Plugin, 0),
 	}
+	pm.rebuild()
+	return pm
+}
+
+// NewPluginManagerWithConfiguration 以配置中的插件创建插件管理器
+func NewPluginManagerWithConfiguration(configuration *config.Configuration) *PluginManager {
+	pm := NewPluginManager()
+	for _, plugin := range configuration.Plugins {
+		pm.AddPlugin(plugin)
+	}
+	return pm
 }
 
 // AddPlugin 添加插件 - 线程安全
@@ -25,10 +77,11 @@ func (pm *PluginManager) AddPlugin(plugin Plugin) {
 	defer pm.mutex.Unlock()
 
 	pm.plugins = append(pm.plugins, plugin)
-	// 按优先级排序
-	sort.Slice(pm.plugins, func(i, j int) bool {
+	// 按优先级排序，相同优先级保持添加顺序
+	sort.SliceStable(pm.plugins, func(i, j int) bool {
 		return pm.plugins[i].GetOrder() < pm.plugins[j].GetOrder()
 	})
+	pm.rebuild()
 }
 
 // RemovePlugin 移除插件 - 线程安全
@@ -39,12 +92,96 @@ func (pm *PluginManager) RemovePlugin(pluginType reflect.Type) bool {
 	for i, plugin := range pm.plugins {
 		if reflect.TypeOf(plugin) == pluginType {
 			pm.plugins = append(pm.plugins[:i], pm.plugins[i+1:]...)
+			pm.rebuild()
 			return true
 		}
 	}
 	return false
 }
 
+// rebuild 重新组合各拦截点的插件链，调用方需持有写锁
+func (pm *PluginManager) rebuild() {
+	set := &chainSet{
+		chains:  make(map[Signature]*PluginChain, len(interceptionPoints)),
+		plugins: append([]Plugin(nil), pm.plugins...),
+	}
+	for _, plugin := range set.plugins {
+		if _, ok := plugin.(config.ScopedPlugin); ok {
+			set.scoped = true
+		}
+	}
+	for _, signature := range interceptionPoints {
+		set.chains[signature] = set.build(signature)
+	}
+	pm.chains.Store(set)
+}
+
+// build 组合拦截 signature 的插件链，没有插件拦截时返回 nil
+func (set *chainSet) build(signature Signature) *PluginChain {
+	var plugins []Plugin
+	for _, plugin := range set.plugins {
+		if matches(plugin, signature.Target, signature.Method) {
+			plugins = append(plugins, plugin)
+		}
+	}
+	if len(plugins) == 0 {
+		return nil
+	}
+	return NewPluginChain(plugins, nil)
+}
+
+// forStatement 从拦截点的插件链中筛选作用于 statement 的插件，结果按语句缓存
+func (set *chainSet) forStatement(signature Signature, chain *PluginChain, statement *config.MapperStatement) *PluginChain {
+	key := statementKey{
+		signature:     signature,
+		id:            statement.ID,
+		namespace:     statement.Namespace,
+		statementType: statement.StatementType,
+		plugins:       statement.Plugins,
+	}
+	if cached, ok := set.statementChains.Load(key); ok {
+		return cached.(*PluginChain)
+	}
+
+	var plugins []Plugin
+	for _, plugin := range chain.plugins {
+		if config.PluginAppliesTo(plugin, statement) {
+			plugins = append(plugins, plugin)
+		}
+	}
+	switch len(plugins) {
+	case len(chain.plugins):
+	case 0:
+		chain = nil
+	default:
+		chain = NewPluginChain(plugins, nil)
+	}
+	set.statementChains.Store(key, chain)
+	return chain
+}
+
+// chain 获取拦截 statement 在 signature 的插件链，statement 为 nil 时不按语句筛选；
+// 未预先组合的拦截点按当前插件临时组合
+func (pm *PluginManager) chain(signature Signature, statement *config.MapperStatement) *PluginChain {
+	set := pm.chains.Load()
+	if set == nil {
+		pm.mutex.Lock()
+		if set = pm.chains.Load(); set == nil {
+			pm.rebuild()
+			set = pm.chains.Load()
+		}
+		pm.mutex.Unlock()
+	}
+	chain, exists := set.chains[signature]
+	if !exists {
+		chain = set.build(signature)
+	}
+	if chain == nil || statement == nil || (!set.scoped && statement.Plugins == "") {
+		return chain
+	}
+	return set.forStatement(signature, chain, statement)
+}
+
 // GetPlugins 获取所有插件 - 返回副本确保线程安全
 func (pm *PluginManager) GetPlugins() []Plugin {
 	pm.mutex.RLock()
@@ -94,29 +231,74 @@ func (pm *PluginManager) CreateInterceptorChain(target interface{}) *Interceptor
 
 // InterceptMethod 拦截方法调用 - 使用新的线程安全 PluginChain
 func (pm *PluginManager) InterceptMethod(target interface{}, method reflect.Method, args []interface{}, statementId string, proceed func() (interface{}, error)) (interface{}, error) {
-	plugins := pm.GetPlugins()
-	if len(plugins) == 0 {
-		return proceed()
-	}
-
-	// 创建新的线程安全插件链
-	chain := NewPluginChain(plugins, target)
-
-	// 创建调用信息
-	invocation := &Invocation{
+	return pm.Intercept(&Invocation{
 		Target:      target,
 		Method:      method,
 		Args:        args,
 		StatementId: statementId,
 		Properties:  make(map[string]interface{}),
 		Proceed:     proceed,
-		Context:     NewInvocationContext(),
+		Signature:   Signature{Target: TargetSqlSession, Method: method.Name},
+	})
+}
+
+// Intercept 以拦截 invocation.Signature 的插件链执行调用，未指定目标时视为 SqlSession 的方法；
+// 链末端调用 invocation 原有的 Proceed，插件对 invocation 的修改在此之前写回
+func (pm *PluginManager) Intercept(invocation *Invocation) (interface{}, error) {
+	if invocation.Signature.Target == "" {
+		invocation.Signature = Signature{Target: TargetSqlSession, Method: invocation.Method.Name}
+	}
+	chain := pm.chain(invocation.Signature, invocation.Statement)
+	if chain == nil {
+		return invocation.Proceed()
 	}
 
-	// 执行插件链
+	if invocation.Properties == nil {
+		invocation.Properties = make(map[string]interface{})
+	}
 	return chain.Proceed(invocation)
 }
 
+// Execute 以拦截 invocation.Signature 且作用于 invocation.Statement 的插件链执行调用，
+// target 接收经插件修改后的 invocation，可被插件并发或重复调用
+func (pm *PluginManager) Execute(invocation *Invocation, target Target) (interface{}, error) {
+	chain := pm.chain(invocation.Signature, invocation.Statement)
+	if chain == nil {
+		return target(invocation)
+	}
+
+	if invocation.Properties == nil {
+		invocation.Properties = make(map[string]interface{})
+	}
+	return chain.Execute(invocation, target)
+}
+
+// Intercepts 实现 executor.Interceptor，statement 为 nil 时不按语句筛选插件
+func (pm *PluginManager) Intercepts(target, method string, statement *config.MapperStatement) bool {
+	return pm.chain(Signature{Target: target, Method: method}, statement) != nil
+}
+
+// InterceptStage 实现 executor.Interceptor，插件对 invocation 中语句、参数、BoundSQL 与结果集的替换在 Proceed 时生效
+func (pm *PluginManager) InterceptStage(stage *executor.Stage, proceed func() (interface{}, error)) (interface{}, error) {
+	invocation := &Invocation{
+		Target:      stage.Executor,
+		Method:      reflect.Method{Name: stage.Method},
+		Args:        []interface{}{stage.Statement.ID, stage.Parameter},
+		StatementId: stage.Statement.ID,
+		Statement:   stage.Statement,
+		Parameter:   stage.Parameter,
+		Executor:    stage.Executor,
+		Signature:   Signature{Target: stage.Target, Method: stage.Method},
+		BoundSQL:    stage.BoundSQL,
+		Rows:        stage.Rows,
+	}
+	return pm.Execute(invocation, func(current *Invocation) (interface{}, error) {
+		stage.Statement, stage.Parameter = current.Statement, current.Parameter
+		stage.BoundSQL, stage.Rows = current.BoundSQL, current.Rows
+		return proceed()
+	})
+}
+
 // PluginRegistry 插件注册表 - 管理多个插件管理器
 type PluginRegistry struct {
 	managers map[string]*PluginManager
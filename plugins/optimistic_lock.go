@@ -0,0 +1,232 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gobatis/binding"
+	"gobatis/core/config"
+	"gobatis/core/executor"
+	"gobatis/dialect"
+	"gobatis/reflection"
+)
+
+// ErrOptimisticLock 更新语句未匹配到版本号一致的行，数据已被其他事务修改或删除
+var ErrOptimisticLock = errors.New("optimistic lock conflict")
+
+// versionParameter 改写后 SQL 中版本号占位符的参数名
+const versionParameter = "__version"
+
+// OptimisticLockPlugin 乐观锁插件，参数结构体带 db:"version,version" 标签的字段为版本号：
+// UPDATE 语句追加 SET version = version + 1 与 version = #{当前版本号} 条件，
+// 更新成功后递增字段（参数为结构体指针时），未更新任何行时返回 ErrOptimisticLock；
+// 批量执行器中带版本号的更新返回错误
+type OptimisticLockPlugin struct {
+	properties map[string]string
+	order      int
+}
+
+func init() {
+	config.RegisterPlugin("optimisticLock", func() config.Plugin { return NewOptimisticLockPlugin() })
+}
+
+// NewOptimisticLockPlugin 创建乐观锁插件
+func NewOptimisticLockPlugin() *OptimisticLockPlugin {
+	return &OptimisticLockPlugin{
+		properties: make(map[string]string),
+	}
+}
+
+// Signatures 实现 Interceptor，拦截 Executor.Update
+func (p *OptimisticLockPlugin) Signatures() []Signature {
+	return []Signature{{Target: TargetExecutor, Method: executor.MethodUpdate}}
+}
+
+// Intercept 改写带版本号参数的 UPDATE 语句并检查影响行数
+func (p *OptimisticLockPlugin) Intercept(invocation *Invocation) (interface{}, error) {
+	statement := invocation.Statement
+	if statement == nil || statement.StatementType != config.UPDATE {
+		return invocation.Proceed()
+	}
+	version, ok := findVersionField(invocation.Parameter, invocation.Configuration)
+	if !ok {
+		return invocation.Proceed()
+	}
+	if !isIntegerKind(version.value.Kind()) {
+		return nil, fmt.Errorf("statement %s: version field %s must be an integer, got %s", statement.ID, version.name, version.value.Type())
+	}
+
+	// 批量执行器在刷新时才执行语句，无法逐条检查影响行数并递增版本号
+	if isBatchExecutor(invocation.Executor) {
+		return nil, fmt.Errorf("statement %s: optimistic locking is not supported by the batch executor", statement.ID)
+	}
+
+	locked := *statement
+	locked.SQL = buildOptimisticLockSQL(statement.SQL, version.column)
+	invocation.Statement = &locked
+	invocation.Parameter = withAdditionalParameter(invocation.Parameter, versionParameter, version.value.Interface())
+
+	result, err := invocation.Proceed()
+	if err != nil {
+		return nil, err
+	}
+	affected, ok := result.(int64)
+	if !ok {
+		return result, nil
+	}
+	if affected == 0 {
+		return affected, fmt.Errorf("%w: statement %s updated no row with %s = %v", ErrOptimisticLock, statement.ID, version.column, version.value.Interface())
+	}
+	if version.value.CanSet() {
+		incrementVersion(version.value)
+	}
+	return affected, nil
+}
+
+// SetProperties 设置插件属性
+func (p *OptimisticLockPlugin) SetProperties(properties map[string]string) {
+	p.properties = properties
+}
+
+// GetOrder 获取插件执行顺序
+func (p *OptimisticLockPlugin) GetOrder() int {
+	return p.order
+}
+
+// versionField 参数中的版本号字段
+type versionField struct {
+	name   string
+	column string
+	value  reflect.Value // 参数为结构体指针时可设置
+}
+
+// findVersionField 查找参数结构体中带 version 选项的字段，列名为标签名或按命名策略由字段名推导
+func findVersionField(parameter interface{}, configuration *config.Configuration) (versionField, bool) {
+	if additional, ok := parameter.(*binding.AdditionalParameters); ok {
+		parameter = additional.Parameter
+	}
+	v := reflect.ValueOf(parameter)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return versionField{}, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return versionField{}, false
+	}
+
+	for _, field := range reflection.StructFields(v.Type()) {
+		if !field.Tag.HasOption("version") {
+			continue
+		}
+		value, ok := reflection.FieldByIndex(v, field.Index, false)
+		if !ok {
+			return versionField{}, false
+		}
+		column := field.Tag.Name
+		if column == "" {
+			naming := reflection.DefaultNamingStrategy
+			if configuration != nil && configuration.NamingStrategy != nil {
+				naming = configuration.NamingStrategy
+			}
+			column = naming.ColumnName(field.Name)
+		}
+		return versionField{name: field.Name, column: field.Prefix + column, value: value}, true
+	}
+	return versionField{}, false
+}
+
+// buildOptimisticLockSQL 在顶层 SET 子句末尾追加版本号递增，并以版本号条件限定 WHERE 子句
+func buildOptimisticLockSQL(originalSQL, column string) string {
+	sql := strings.TrimRight(strings.TrimSpace(originalSQL), ";")
+	keywords := dialect.TopLevelKeywords(sql)
+
+	// WHERE 子句结束于 RETURNING 等子句或语句末尾
+	whereIndex := dialect.FindKeywords(keywords, "WHERE")
+	searchFrom := 0
+	if whereIndex >= 0 {
+		searchFrom = whereIndex + 1
+	} else if setIndex := dialect.FindKeywords(keywords, "SET"); setIndex >= 0 {
+		searchFrom = setIndex + 1
+	}
+	end := len(sql)
+	for _, keyword := range keywords[searchFrom:] {
+		if keyword.Word == "RETURNING" || keyword.Word == "ORDER" || keyword.Word == "LIMIT" {
+			end = keyword.Start
+			break
+		}
+	}
+
+	increment := fmt.Sprintf(", %s = %s + 1", column, column)
+	predicate := fmt.Sprintf("%s = #{%s}", column, versionParameter)
+	tail := strings.TrimSpace(sql[end:])
+
+	var b strings.Builder
+	if whereIndex >= 0 {
+		where := keywords[whereIndex]
+		b.WriteString(strings.TrimRight(sql[:where.Start], " \t\r\n"))
+		b.WriteString(increment)
+		fmt.Fprintf(&b, " WHERE (%s) AND %s", strings.TrimSpace(sql[where.End:end]), predicate)
+	} else {
+		b.WriteString(strings.TrimRight(sql[:end], " \t\r\n"))
+		b.WriteString(increment)
+		b.WriteString(" WHERE ")
+		b.WriteString(predicate)
+	}
+	if tail != "" {
+		b.WriteString(" ")
+		b.WriteString(tail)
+	}
+	return b.String()
+}
+
+// withAdditionalParameter 在原参数之外附加具名参数
+func withAdditionalParameter(parameter interface{}, name string, value interface{}) interface{} {
+	additional := map[string]interface{}{name: value}
+	if existing, ok := parameter.(*binding.AdditionalParameters); ok {
+		for k, v := range existing.Additional {
+			if _, exists := additional[k]; !exists {
+				additional[k] = v
+			}
+		}
+		parameter = existing.Parameter
+	}
+	return &binding.AdditionalParameters{Parameter: parameter, Additional: additional}
+}
+
+// isBatchExecutor 执行器是否为（被装饰的）批量执行器
+func isBatchExecutor(exec interface{}) bool {
+	for exec != nil {
+		if _, ok := exec.(*executor.BatchExecutor); ok {
+			return true
+		}
+		decorator, ok := exec.(interface{ Delegate() executor.Executor })
+		if !ok {
+			return false
+		}
+		exec = decorator.Delegate()
+	}
+	return false
+}
+
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// incrementVersion 将版本号字段加一
+func incrementVersion(value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt(value.Int() + 1)
+	default:
+		value.SetUint(value.Uint() + 1)
+	}
+}
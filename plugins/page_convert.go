@@ -0,0 +1,82 @@
+package plugins
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var pageResultPkgPath = reflect.TypeOf(PageResult[interface{}]{}).PkgPath()
+
+// IsPageType 判断类型是否为 PageResult[T]、Page[T] 或其指针
+func IsPageType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t.PkgPath() != pageResultPkgPath {
+		return false
+	}
+	return strings.HasPrefix(t.Name(), "PageResult[") || strings.HasPrefix(t.Name(), "Page[")
+}
+
+// ConvertPage 将分页插件返回的 *PageResult[interface{}] 转换为 target 类型，
+// target 为 PageResult[T]、Page[T] 或其指针，每行需可赋值给 T（指针与值可互转）
+func ConvertPage(result interface{}, target reflect.Type) (reflect.Value, error) {
+	if !IsPageType(target) {
+		return reflect.Value{}, fmt.Errorf("%s is not a page type", target)
+	}
+	source, ok := result.(*PageResult[interface{}])
+	if !ok || source == nil {
+		return reflect.Value{}, fmt.Errorf("expected a page result, got %T; is the pagination plugin installed and a PageRequest passed?", result)
+	}
+
+	pageType := target
+	if pageType.Kind() == reflect.Ptr {
+		pageType = pageType.Elem()
+	}
+	page := reflect.New(pageType).Elem()
+	sourceValue := reflect.ValueOf(source).Elem()
+	for i := 0; i < pageType.NumField(); i++ {
+		field := pageType.Field(i)
+		if field.Name == "Data" {
+			data, err := convertRows(source.Data, field.Type)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			page.Field(i).Set(data)
+			continue
+		}
+		page.Field(i).Set(sourceValue.FieldByName(field.Name))
+	}
+
+	if target.Kind() == reflect.Ptr {
+		return page.Addr(), nil
+	}
+	return page, nil
+}
+
+// convertRows 将结果行转换为 sliceType 的切片
+func convertRows(rows []interface{}, sliceType reflect.Type) (reflect.Value, error) {
+	elemType := sliceType.Elem()
+	data := reflect.MakeSlice(sliceType, 0, len(rows))
+	for _, row := range rows {
+		if row == nil {
+			data = reflect.Append(data, reflect.Zero(elemType))
+			continue
+		}
+		v := reflect.ValueOf(row)
+		switch {
+		case v.Type().AssignableTo(elemType):
+		case v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Type().AssignableTo(elemType):
+			v = v.Elem()
+		case elemType.Kind() == reflect.Ptr && v.Type().AssignableTo(elemType.Elem()):
+			ptr := reflect.New(elemType.Elem())
+			ptr.Elem().Set(v)
+			v = ptr
+		default:
+			return reflect.Value{}, fmt.Errorf("cannot convert row of type %s to %s", v.Type(), elemType)
+		}
+		data = reflect.Append(data, v)
+	}
+	return data, nil
+}
@@ -2,35 +2,70 @@ package plugins
 
 import (
 	"fmt"
+	"math"
 	"reflect"
 	"regexp"
 	"strings"
+
+	"gobatis/core/config"
+	"gobatis/dialect"
 )
 
 // PageRequest 分页请求
 type PageRequest struct {
-	Page    int    `json:"page"`    // 页码（从1开始）
-	Size    int    `json:"size"`    // 每页大小
-	Offset  int    `json:"offset"`  // 偏移量
-	SortBy  string `json:"sortBy"`  // 排序字段
-	SortDir string `json:"sortDir"` // 排序方向（ASC/DESC）
+	Page    int         `json:"page"`    // 页码（从1开始）
+	Size    int         `json:"size"`    // 每页大小
+	Offset  int         `json:"offset"`  // 偏移量
+	SortBy  string      `json:"sortBy"`  // 排序字段
+	SortDir string      `json:"sortDir"` // 排序方向（ASC/DESC）
+	Sorts   []SortOrder `json:"sorts"`   // 多列排序，依次作为 ORDER BY 的各列
+}
+
+// SortOrder 排序列及方向
+type SortOrder struct {
+	Column string `json:"column"`
+	Desc   bool   `json:"desc"`
 }
 
-// PageResult 分页结果
-type PageResult struct {
-	Data       interface{} `json:"data"`       // 数据列表
-	Total      int64       `json:"total"`      // 总记录数
-	Page       int         `json:"page"`       // 当前页码
-	Size       int         `json:"size"`       // 每页大小
-	TotalPages int         `json:"totalPages"` // 总页数
-	HasNext    bool        `json:"hasNext"`    // 是否有下一页
-	HasPrev    bool        `json:"hasPrev"`    // 是否有上一页
+// PageResult 分页结果，T 为每行的类型；插件返回 *PageResult[interface{}]，
+// mapper 方法可声明为 *PageResult[User] 或 Page[User] 由 ConvertPage 转换
+type PageResult[T any] struct {
+	Data       []T   `json:"data"`       // 数据列表
+	Total      int64 `json:"total"`      // 总记录数
+	Page       int   `json:"page"`       // 当前页码
+	Size       int   `json:"size"`       // 每页大小
+	TotalPages int   `json:"totalPages"` // 总页数
+	HasNext    bool  `json:"hasNext"`    // 是否有下一页
+	HasPrev    bool  `json:"hasPrev"`    // 是否有上一页
+}
+
+// Page 以值返回的分页结果，字段与 PageResult 相同
+type Page[T any] PageResult[T]
+
+// NewPageResult 由当前页数据与总数构建分页结果
+func NewPageResult[T any](data []T, total int64, request *PageRequest) *PageResult[T] {
+	totalPages := int((total + int64(request.Size) - 1) / int64(request.Size))
+	return &PageResult[T]{
+		Data:       data,
+		Total:      total,
+		Page:       request.Page,
+		Size:       request.Size,
+		TotalPages: totalPages,
+		HasNext:    request.Page < totalPages,
+		HasPrev:    request.Page > 1,
+	}
 }
 
 // PaginationPlugin 分页插件
 type PaginationPlugin struct {
-	properties map[string]string
-	order      int
+	properties         map[string]string
+	order              int
+	allowedSortColumns map[string]bool // 请求可使用的排序列
+	allowAnySortColumn bool            // 允许按任何安全的列名排序
+}
+
+func init() {
+	config.RegisterPlugin("pagination", func() config.Plugin { return NewPaginationPlugin() })
 }
 
 // NewPaginationPlugin 创建分页插件
@@ -43,6 +78,11 @@ func NewPaginationPlugin() *PaginationPlugin {
 
 // Intercept 拦截方法调用
 func (p *PaginationPlugin) Intercept(invocation *Invocation) (interface{}, error) {
+	// 键集分页
+	if cursorRequest := p.extractCursorPageRequest(invocation.Args); cursorRequest != nil {
+		return p.interceptCursor(invocation, cursorRequest)
+	}
+
 	// 检查是否需要分页
 	pageRequest := p.extractPageRequest(invocation.Args)
 	if pageRequest == nil {
@@ -50,24 +90,33 @@ func (p *PaginationPlugin) Intercept(invocation *Invocation) (interface{}, error
 		return invocation.Proceed()
 	}
 
-	// 修改 SQL 添加分页
-	originalSQL := p.getOriginalSQL(invocation)
-	if originalSQL == "" {
+	// 排序列必须是允许的列
+	if _, err := p.sortOrders(pageRequest); err != nil {
+		return nil, err
+	}
+
+	// 没有可执行的语句时无法分页
+	statement := invocation.Statement
+	if statement == nil || invocation.Executor == nil {
 		return invocation.Proceed()
 	}
 
-	// 先查询总数
-	countSQL := p.buildCountSQL(originalSQL)
-	total, err := p.executeCountQuery(invocation, countSQL)
+	// 先以相同参数在同一执行器中查询总数
+	total, err := p.executeCountQuery(invocation)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute count query: %w", err)
 	}
 
-	// 构建分页 SQL
-	pagedSQL := p.buildPagedSQL(originalSQL, pageRequest)
-
-	// 更新调用参数中的 SQL
-	p.updateSQL(invocation, pagedSQL)
+	// 以分页 SQL 替换将要执行的语句
+	pagedSQL, err := p.buildPagedSQL(statement.SQL, pageRequest, pagingDialect(invocation.Configuration))
+	if err != nil {
+		return nil, err
+	}
+	paged := *statement
+	paged.SQL = pagedSQL
+	invocation.Statement = &paged
+	invocation.Parameter = withAdditionalParameter(invocation.Parameter, limitParameter, pageRequest.Size)
+	invocation.Parameter = withAdditionalParameter(invocation.Parameter, offsetParameter, pageRequest.Offset)
 
 	// 执行分页查询
 	result, err := invocation.Proceed()
@@ -75,23 +124,39 @@ func (p *PaginationPlugin) Intercept(invocation *Invocation) (interface{}, error
 		return nil, err
 	}
 
-	// 构建分页结果
-	pageResult := &PageResult{
-		Data:       result,
-		Total:      total,
-		Page:       pageRequest.Page,
-		Size:       pageRequest.Size,
-		TotalPages: int((total + int64(pageRequest.Size) - 1) / int64(pageRequest.Size)),
-		HasNext:    pageRequest.Page < int((total+int64(pageRequest.Size)-1)/int64(pageRequest.Size)),
-		HasPrev:    pageRequest.Page > 1,
+	rows, ok := result.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected paged query result type %T", result)
 	}
-
-	return pageResult, nil
+	return NewPageResult(rows, total, pageRequest), nil
 }
 
 // SetProperties 设置插件属性
+// allowedSortColumns 以逗号分隔允许排序的列，allowAnySortColumn 为 true 时允许按任何安全的列名排序
 func (p *PaginationPlugin) SetProperties(properties map[string]string) {
 	p.properties = properties
+	if columns, exists := properties["allowedSortColumns"]; exists {
+		p.SetAllowedSortColumns(strings.Split(columns, ",")...)
+	}
+	if allowAny, exists := properties["allowAnySortColumn"]; exists {
+		p.SetAllowAnySortColumn(strings.EqualFold(strings.TrimSpace(allowAny), "true"))
+	}
+}
+
+// SetAllowAnySortColumn 允许请求按任何安全的列名排序，不再要求允许列表
+func (p *PaginationPlugin) SetAllowAnySortColumn(allow bool) {
+	p.allowAnySortColumn = allow
+}
+
+// SetAllowedSortColumns 设置 SortBy 与 Sorts 允许使用的排序列，
+// 未设置且未调用 SetAllowAnySortColumn 时请求中的排序列一律拒绝
+func (p *PaginationPlugin) SetAllowedSortColumns(columns ...string) {
+	p.allowedSortColumns = make(map[string]bool, len(columns))
+	for _, column := range columns {
+		if column = strings.TrimSpace(column); column != "" {
+			p.allowedSortColumns[strings.ToLower(column)] = true
+		}
+	}
 }
 
 // GetOrder 获取插件执行顺序
@@ -119,130 +184,238 @@ func (p *PaginationPlugin) validatePageRequest(pageReq *PageRequest) bool {
 	return true
 }
 
-// extractPageRequest 从参数中提取分页请求
+// extractPageRequest 从参数中提取分页请求，返回计算好偏移量的副本，不修改调用方的请求；
+// 参数 map 中的值只识别 PageRequest，其他带 Page 与 Size 字段的结构体须直接作为参数
 func (p *PaginationPlugin) extractPageRequest(args []interface{}) *PageRequest {
-	for _, arg := range args {
-		if pageReq, ok := arg.(*PageRequest); ok {
-			// 验证分页参数
-			if !p.validatePageRequest(pageReq) {
-				return nil
+	for _, arg := range requestArgs(args) {
+		var pageReq PageRequest
+		switch request := arg.(type) {
+		case *PageRequest:
+			if request == nil {
+				continue
 			}
+			pageReq = *request
+		case PageRequest:
+			pageReq = request
+		default:
+			continue
+		}
 
-			// 计算偏移量
-			if pageReq.Offset == 0 && pageReq.Page > 0 {
-				pageReq.Offset = (pageReq.Page - 1) * pageReq.Size
-			}
-			return pageReq
+		// 验证分页参数
+		if !p.validatePageRequest(&pageReq) {
+			return nil
 		}
 
-		// 检查是否为包含分页信息的结构体
-		v := reflect.ValueOf(arg)
-		if v.Kind() == reflect.Ptr {
-			v = v.Elem()
+		// 计算偏移量
+		if pageReq.Offset == 0 {
+			pageReq.Offset = (pageReq.Page - 1) * pageReq.Size
 		}
-		if v.Kind() == reflect.Struct {
-			pageField := v.FieldByName("Page")
-			sizeField := v.FieldByName("Size")
-			if pageField.IsValid() && sizeField.IsValid() {
-				page := int(pageField.Int())
-				size := int(sizeField.Int())
-
-				pageReq := &PageRequest{
-					Page:   page,
-					Size:   size,
-					Offset: (page - 1) * size,
-				}
-
-				// 验证分页参数
-				if p.validatePageRequest(pageReq) {
-					return pageReq
-				}
-			}
+		return &pageReq
+	}
+
+	// 检查是否为包含分页信息的结构体
+	for _, arg := range args {
+		if pageReq := p.structPageRequest(arg); pageReq != nil {
+			return pageReq
 		}
 	}
 	return nil
 }
 
-// getOriginalSQL 获取原始 SQL
-func (p *PaginationPlugin) getOriginalSQL(invocation *Invocation) string {
-	if sql, exists := invocation.Properties["sql"]; exists {
-		if sqlStr, ok := sql.(string); ok {
-			return sqlStr
+// structPageRequest 由带整型 Page 与 Size 字段的结构体构建分页请求，字段缺失或不是整型时返回 nil
+func (p *PaginationPlugin) structPageRequest(arg interface{}) *PageRequest {
+	v := reflect.ValueOf(arg)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
 		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
 	}
-	return ""
-}
 
-// buildCountSQL 构建计数 SQL
-func (p *PaginationPlugin) buildCountSQL(originalSQL string) string {
-	// 简单的计数 SQL 构建
-	// 实际项目中可能需要更复杂的 SQL 解析
-	lowerSQL := strings.ToLower(strings.TrimSpace(originalSQL))
+	page, ok := intField(v.FieldByName("Page"))
+	if !ok {
+		return nil
+	}
+	size, ok := intField(v.FieldByName("Size"))
+	if !ok {
+		return nil
+	}
+
+	pageReq := &PageRequest{
+		Page:   page,
+		Size:   size,
+		Offset: (page - 1) * size,
+	}
 
-	// 查找 FROM 子句
-	fromIndex := strings.Index(lowerSQL, "from")
-	if fromIndex == -1 {
-		return ""
+	// 验证分页参数
+	if !p.validatePageRequest(pageReq) {
+		return nil
 	}
+	return pageReq
+}
 
-	// 查找 ORDER BY 子句并移除
-	orderByIndex := strings.LastIndex(lowerSQL, "order by")
-	fromClause := originalSQL[fromIndex:]
-	if orderByIndex > fromIndex {
-		fromClause = originalSQL[fromIndex:orderByIndex]
+// intField 读取整型字段的值，过大的无符号值视为无效
+func intField(field reflect.Value) (int, bool) {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(field.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if field.Uint() > math.MaxInt32 {
+			return 0, false
+		}
+		return int(field.Uint()), true
+	default:
+		return 0, false
 	}
+}
 
-	return fmt.Sprintf("SELECT COUNT(*) %s", fromClause)
+// requestArgs 展开调用参数，mapper 多参数方法的参数 map 中的值也参与查找
+func requestArgs(args []interface{}) []interface{} {
+	expanded := append([]interface{}(nil), args...)
+	for _, arg := range args {
+		if params, ok := arg.(map[string]interface{}); ok {
+			for _, value := range params {
+				expanded = append(expanded, value)
+			}
+		}
+	}
+	return expanded
+}
+
+// buildCountSQL 构建计数 SQL，仅移除顶层 ORDER BY，复杂查询包装为子查询
+func (p *PaginationPlugin) buildCountSQL(originalSQL string) string {
+	return deriveCountSQL(originalSQL)
 }
 
+// sortKeyPattern 安全的排序列：只允许字母、数字、下划线和点号（用于表名.列名）
+var sortKeyPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+
 // isValidColumnName 验证列名是否安全（防止SQL注入）
 func (p *PaginationPlugin) isValidColumnName(columnName string) bool {
-	// 只允许字母、数字、下划线和点号（用于表名.列名）
-	validPattern := regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
-	return validPattern.MatchString(columnName)
+	return sortKeyPattern.MatchString(columnName)
 }
 
-// buildPagedSQL 构建分页 SQL
-func (p *PaginationPlugin) buildPagedSQL(originalSQL string, pageRequest *PageRequest) string {
-	sql := originalSQL
+// isAllowedSortColumn 列在允许列表中，或已允许任何安全的列
+func (p *PaginationPlugin) isAllowedSortColumn(column string) bool {
+	return p.allowAnySortColumn || p.allowedSortColumns[strings.ToLower(column)]
+}
 
-	// 添加排序
+// sortOrders 汇总请求中的排序列，SortBy 与 Sorts 中不安全或不允许的列返回错误
+func (p *PaginationPlugin) sortOrders(pageRequest *PageRequest) ([]SortOrder, error) {
+	requested := pageRequest.Sorts
 	if pageRequest.SortBy != "" {
-		// 验证排序字段名，防止SQL注入
-		if !p.isValidColumnName(pageRequest.SortBy) {
-			// 如果字段名不安全，跳过排序
-			pageRequest.SortBy = ""
-		} else {
-			sortDir := "ASC"
-			if strings.ToUpper(pageRequest.SortDir) == "DESC" {
-				sortDir = "DESC"
-			}
+		sortBy := SortOrder{Column: pageRequest.SortBy, Desc: strings.ToUpper(pageRequest.SortDir) == "DESC"}
+		requested = append([]SortOrder{sortBy}, requested...)
+	}
 
-			// 检查是否已有 ORDER BY
-			if !strings.Contains(strings.ToLower(sql), "order by") {
-				sql += fmt.Sprintf(" ORDER BY %s %s", pageRequest.SortBy, sortDir)
-			}
+	var orders []SortOrder
+	for _, order := range requested {
+		if !p.isValidColumnName(order.Column) {
+			return nil, fmt.Errorf("invalid sort column %q", order.Column)
 		}
+		if !p.isAllowedSortColumn(order.Column) {
+			return nil, fmt.Errorf("sort column %q is not allowed", order.Column)
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+// 分页子句中行数与偏移量占位符的参数名
+const (
+	limitParameter  = "__limit"
+	offsetParameter = "__offset"
+)
+
+// pagingDialect 获取生成分页子句的方言，未配置时使用通用方言
+func pagingDialect(configuration *config.Configuration) dialect.Dialect {
+	if configuration == nil || configuration.Dialect == nil {
+		return dialect.GenericDialect{}
 	}
+	return configuration.Dialect
+}
 
-	// 添加 LIMIT 和 OFFSET
-	sql += fmt.Sprintf(" LIMIT %d OFFSET %d", pageRequest.Size, pageRequest.Offset)
+// buildPagedSQL 构建分页 SQL，请求指定排序时替换语句末尾的 ORDER BY，
+// 分页子句由方言生成，行数与偏移量以具名占位符绑定
+func (p *PaginationPlugin) buildPagedSQL(originalSQL string, pageRequest *PageRequest, d dialect.Dialect) (string, error) {
+	sql := originalSQL
 
-	return sql
+	orders, err := p.sortOrders(pageRequest)
+	if err != nil {
+		return "", err
+	}
+	if len(orders) > 0 {
+		clauses := make([]string, len(orders))
+		for i, order := range orders {
+			clauses[i] = order.Column + " ASC"
+			if order.Desc {
+				clauses[i] = order.Column + " DESC"
+			}
+		}
+		sql = stripOrderBy(sql)
+		if dialect.FindKeywords(dialect.TopLevelKeywords(sql), "ORDER", "BY") < 0 {
+			sql += " ORDER BY " + strings.Join(clauses, ", ")
+		}
+	}
+
+	return d.PagingSQL(sql, "#{"+limitParameter+"}", "#{"+offsetParameter+"}"), nil
 }
 
-// executeCountQuery 执行计数查询
-func (p *PaginationPlugin) executeCountQuery(invocation *Invocation, countSQL string) (int64, error) {
-	// 这里需要实际的数据库执行逻辑
-	// 在实际实现中，需要访问 SqlSession 来执行查询
-	// 暂时返回模拟数据
-	return 100, nil
+// countStatement 获取计数语句，优先使用 countStatement 属性指定的语句，否则由原查询推导
+func (p *PaginationPlugin) countStatement(invocation *Invocation) (*config.MapperStatement, error) {
+	statement := invocation.Statement
+	if statement.CountStatement != "" {
+		if invocation.Configuration == nil {
+			return nil, fmt.Errorf("count statement %s requires the configuration", statement.CountStatement)
+		}
+		countStatement, exists := invocation.Configuration.GetMapperStatement(statement.CountStatement)
+		if !exists {
+			return nil, fmt.Errorf("count statement %s not found for statement %s", statement.CountStatement, statement.ID)
+		}
+		if countStatement.ResultType == nil && countStatement.ResultMap == nil {
+			counted := *countStatement
+			counted.ResultType = reflect.TypeOf(int64(0))
+			countStatement = &counted
+		}
+		return countStatement, nil
+	}
+
+	return &config.MapperStatement{
+		ID:            statement.ID + "_COUNT",
+		Namespace:     statement.Namespace,
+		SQL:           p.buildCountSQL(statement.SQL),
+		ResultType:    reflect.TypeOf(int64(0)),
+		StatementType: config.SELECT,
+	}, nil
 }
 
-// updateSQL 更新调用中的 SQL
-func (p *PaginationPlugin) updateSQL(invocation *Invocation, newSQL string) {
-	if invocation.Properties == nil {
-		invocation.Properties = make(map[string]interface{})
+// executeCountQuery 以原查询的参数执行计数查询
+func (p *PaginationPlugin) executeCountQuery(invocation *Invocation) (int64, error) {
+	countStatement, err := p.countStatement(invocation)
+	if err != nil {
+		return 0, err
+	}
+
+	results, err := invocation.Executor.Query(countStatement, invocation.Parameter)
+	if err != nil {
+		return 0, err
+	}
+	if len(results) == 0 {
+		return 0, nil
+	}
+	total := reflect.ValueOf(results[0])
+	for total.Kind() == reflect.Ptr && !total.IsNil() {
+		total = total.Elem()
+	}
+	switch total.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return total.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(total.Uint()), nil
+	default:
+		return 0, fmt.Errorf("unexpected count result type %T", results[0])
 	}
-	invocation.Properties["sql"] = newSQL
 }
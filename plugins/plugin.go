@@ -4,135 +4,110 @@ import (
 	"fmt"
 	"reflect"
 	"sync"
-	"time"
+
+	"gobatis/core/config"
 )
 
-// Plugin 插件接口
-type Plugin interface {
-	// Intercept 拦截方法调用
-	Intercept(invocation *Invocation) (interface{}, error)
-	// SetProperties 设置插件属性
-	SetProperties(properties map[string]string)
-	// GetOrder 获取插件执行顺序（数字越小优先级越高）
-	GetOrder() int
-}
+// Plugin 插件接口，与 config.Plugin 为同一类型
+type Plugin = config.Plugin
+
+// 可拦截的目标：SqlSession 的 SelectOne、SelectList、Insert、Update、Delete，
+// 以及执行器内部的 Executor、StatementHandler、ParameterHandler、ResultSetHandler
+const (
+	TargetSqlSession       = config.TargetSqlSession
+	TargetExecutor         = config.TargetExecutor
+	TargetStatementHandler = config.TargetStatementHandler
+	TargetParameterHandler = config.TargetParameterHandler
+	TargetResultSetHandler = config.TargetResultSetHandler
+)
 
-// Invocation 拦截调用信息
-type Invocation struct {
-	Target      interface{}                 // 目标对象
-	Method      reflect.Method              // 调用的方法
-	Args        []interface{}               // 方法参数
-	StatementId string                      // SQL 语句 ID
-	Properties  map[string]interface{}      // 额外属性
-	Proceed     func() (interface{}, error) // 继续执行的函数
-	Context     *InvocationContext          // 调用上下文
-}
+// BoundSQL 绑定参数后发送到数据库的 SQL，插件可修改 SQL 与 Args
+type BoundSQL = config.BoundSQL
 
-// InvocationContext 调用上下文，用于错误处理和回滚
-type InvocationContext struct {
-	StartTime     time.Time              // 开始时间
-	PluginStates  map[string]interface{} // 插件状态
-	RollbackFuncs []func() error         // 回滚函数列表
-	mutex         sync.RWMutex           // 保护并发访问
-}
+// Signature 插件拦截的目标与方法
+type Signature = config.Signature
 
-// NewInvocationContext 创建新的调用上下文
-func NewInvocationContext() *InvocationContext {
-	return &InvocationContext{
-		StartTime:     time.Now(),
-		PluginStates:  make(map[string]interface{}),
-		RollbackFuncs: make([]func() error, 0),
-	}
-}
+// Interceptor 声明拦截点的插件
+type Interceptor = config.Interceptor
 
-// SetPluginState 设置插件状态
-func (ctx *InvocationContext) SetPluginState(pluginName string, state interface{}) {
-	ctx.mutex.Lock()
-	defer ctx.mutex.Unlock()
-	ctx.PluginStates[pluginName] = state
-}
+// Invocation 拦截调用信息
+type Invocation = config.Invocation
 
-// GetPluginState 获取插件状态
-func (ctx *InvocationContext) GetPluginState(pluginName string) (interface{}, bool) {
-	ctx.mutex.RLock()
-	defer ctx.mutex.RUnlock()
-	state, exists := ctx.PluginStates[pluginName]
-	return state, exists
-}
+// InvocationContext 调用上下文
+type InvocationContext = config.InvocationContext
 
-// AddRollbackFunc 添加回滚函数
-func (ctx *InvocationContext) AddRollbackFunc(rollbackFunc func() error) {
-	ctx.mutex.Lock()
-	defer ctx.mutex.Unlock()
-	ctx.RollbackFuncs = append(ctx.RollbackFuncs, rollbackFunc)
+// NewInvocationContext 创建新的调用上下文
+func NewInvocationContext() *InvocationContext {
+	return config.NewInvocationContext()
 }
 
-// ExecuteRollback 执行回滚
-func (ctx *InvocationContext) ExecuteRollback() []error {
-	ctx.mutex.Lock()
-	defer ctx.mutex.Unlock()
-
-	var errors []error
-	// 逆序执行回滚函数
-	for i := len(ctx.RollbackFuncs) - 1; i >= 0; i-- {
-		if err := ctx.RollbackFuncs[i](); err != nil {
-			errors = append(errors, err)
+// matches 插件是否拦截 target 的 method
+func matches(plugin Plugin, target, method string) bool {
+	interceptor, ok := plugin.(Interceptor)
+	if !ok {
+		return target == TargetSqlSession
+	}
+	for _, signature := range interceptor.Signatures() {
+		if signature.Target == target && (signature.Method == "" || signature.Method == method) {
+			return true
 		}
 	}
-	return errors
+	return false
 }
 
-// PluginChain 插件链 - 线程安全版本
+// Target 插件链末端执行的原方法，接收最内层插件传入的 invocation
+type Target func(invocation *Invocation) (interface{}, error)
+
+// handler 插件链中的一环
+type handler func(invocation *Invocation, target Target) (interface{}, error)
+
+// PluginChain 预先组合的插件链，不保存调用状态，可被并发与重复执行；
+// 每个插件收到 invocation 的浅拷贝，其 Proceed 固定指向下一环，
+// 因此插件可多次（重试）或并发调用 Proceed，修改只影响之后的插件与原方法
 type PluginChain struct {
-	plugins      []Plugin
-	currentIndex int
-	target       interface{}
-	invocation   *Invocation
-	completed    bool
-	mutex        sync.RWMutex
+	plugins []Plugin
+	target  interface{}
+	head    handler
 }
 
-// NewPluginChain 创建插件链 - 每次调用都创建新实例
+// NewPluginChain 创建插件链，插件按给定顺序组合
 func NewPluginChain(plugins []Plugin, target interface{}) *PluginChain {
 	// 创建插件副本，避免并发修改
 	pluginsCopy := make([]Plugin, len(plugins))
 	copy(pluginsCopy, plugins)
 
 	return &PluginChain{
-		plugins:      pluginsCopy,
-		currentIndex: 0,
-		target:       target,
-		completed:    false,
+		plugins: pluginsCopy,
+		target:  target,
+		head:    compose(pluginsCopy),
 	}
 }
 
-// Proceed 执行插件链 - 线程安全版本
-func (chain *PluginChain) Proceed(invocation *Invocation) (interface{}, error) {
-	chain.mutex.Lock()
-	defer chain.mutex.Unlock()
-
-	// 检查是否已完成
-	if chain.completed {
-		return nil, fmt.Errorf("plugin chain already completed")
+// compose 由内向外组合插件
+func compose(plugins []Plugin) handler {
+	next := func(invocation *Invocation, target Target) (interface{}, error) {
+		return target(invocation)
 	}
+	for i := len(plugins) - 1; i >= 0; i-- {
+		plugin, inner := plugins[i], next
+		next = func(invocation *Invocation, target Target) (interface{}, error) {
+			current := *invocation
+			current.Proceed = func() (interface{}, error) {
+				return inner(&current, target)
+			}
+			return plugin.Intercept(&current)
+		}
+	}
+	return next
+}
 
-	// 设置调用上下文
+// Execute 以插件链执行调用，target 接收经插件修改后的 invocation
+func (chain *PluginChain) Execute(invocation *Invocation, target Target) (interface{}, error) {
 	if invocation.Context == nil {
 		invocation.Context = NewInvocationContext()
 	}
 
-	chain.invocation = invocation
-
-	// 保存原始的 Proceed 函数
-	originalProceed := invocation.Proceed
-
-	// 设置新的 Proceed 函数
-	invocation.Proceed = func() (interface{}, error) {
-		return chain.proceedNext(originalProceed)
-	}
-
-	// 开始执行插件链
-	result, err := chain.proceedNext(originalProceed)
+	result, err := chain.head(invocation, target)
 
 	// 如果发生错误，执行回滚
 	if err != nil {
@@ -142,23 +117,23 @@ func (chain *PluginChain) Proceed(invocation *Invocation) (interface{}, error) {
 			return nil, fmt.Errorf("original error: %v, rollback errors: %v", err, rollbackErrors)
 		}
 	}
-
-	chain.completed = true
 	return result, err
 }
 
-// proceedNext 执行下一个插件
-func (chain *PluginChain) proceedNext(originalProceed func() (interface{}, error)) (interface{}, error) {
-	if chain.currentIndex >= len(chain.plugins) {
-		// 所有插件都执行完毕，调用原始方法
-		return chain.invokeTarget(originalProceed)
-	}
-
-	plugin := chain.plugins[chain.currentIndex]
-	chain.currentIndex++
-
-	// 执行插件拦截
-	return plugin.Intercept(chain.invocation)
+// Proceed 以插件链执行调用，链末端调用 invocation 原有的 Proceed，未设置时反射调用目标方法；
+// 插件对 invocation 的修改在调用原有 Proceed 前写回 invocation
+func (chain *PluginChain) Proceed(invocation *Invocation) (interface{}, error) {
+	original := invocation.Proceed
+	return chain.Execute(invocation, func(current *Invocation) (interface{}, error) {
+		if current != invocation {
+			*invocation = *current
+			invocation.Proceed = original
+		}
+		if original != nil {
+			return original()
+		}
+		return chain.invokeTarget(invocation)
+	})
 }
 
 // 反射调用缓存
@@ -230,27 +205,27 @@ func (mc *methodCache) getMethodInfo(target interface{}, methodName string) (*me
 	return info, nil
 }
 
-// invokeTarget 调用目标方法 - 优化版本
-func (chain *PluginChain) invokeTarget(originalProceed func() (interface{}, error)) (interface{}, error) {
-	// 如果有原始的 Proceed 函数，直接调用
-	if originalProceed != nil {
-		return originalProceed()
+// invokeTarget 反射调用目标方法
+func (chain *PluginChain) invokeTarget(invocation *Invocation) (interface{}, error) {
+	target := chain.target
+	if target == nil {
+		target = invocation.Target
 	}
 
 	// 使用缓存的反射调用
-	methodInfo, err := globalMethodCache.getMethodInfo(chain.target, chain.invocation.Method.Name)
+	methodInfo, err := globalMethodCache.getMethodInfo(target, invocation.Method.Name)
 	if err != nil {
 		return nil, err
 	}
 
 	// 类型安全的参数准备
-	args, err := chain.prepareArgs(methodInfo)
+	args, err := chain.prepareArgs(invocation, methodInfo)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare arguments: %w", err)
 	}
 
 	// 调用方法
-	targetValue := reflect.ValueOf(chain.target)
+	targetValue := reflect.ValueOf(target)
 	results := methodInfo.method.Func.Call(append([]reflect.Value{targetValue}, args...))
 
 	// 类型安全的结果处理
@@ -258,17 +233,17 @@ func (chain *PluginChain) invokeTarget(originalProceed func() (interface{}, erro
 }
 
 // prepareArgs 准备参数 - 增强类型安全
-func (chain *PluginChain) prepareArgs(methodInfo *methodInfo) ([]reflect.Value, error) {
-	args := make([]reflect.Value, len(chain.invocation.Args))
+func (chain *PluginChain) prepareArgs(invocation *Invocation, methodInfo *methodInfo) ([]reflect.Value, error) {
+	args := make([]reflect.Value, len(invocation.Args))
 
 	// 跳过第一个参数（receiver）
 	paramOffset := 1
 
-	for i, arg := range chain.invocation.Args {
+	for i, arg := range invocation.Args {
 		paramIndex := i + paramOffset
 		if paramIndex >= len(methodInfo.paramTypes) {
 			return nil, fmt.Errorf("too many arguments: expected %d, got %d",
-				len(methodInfo.paramTypes)-paramOffset, len(chain.invocation.Args))
+				len(methodInfo.paramTypes)-paramOffset, len(invocation.Args))
 		}
 
 		expectedType := methodInfo.paramTypes[paramIndex]
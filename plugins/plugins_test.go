@@ -1,51 +1,269 @@
 package plugins
 
 import (
+	"errors"
 	"reflect"
+	"regexp"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"gobatis/core/config"
+	"gobatis/core/example"
+	"gobatis/core/executor"
+	"gobatis/dialect"
+
+	"github.com/DATA-DOG/go-sqlmock"
 )
 
-// TestPaginationPlugin 测试分页插件
+// TestPaginationPlugin 测试分页插件以相同参数执行计数查询与分页查询
 func TestPaginationPlugin(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
 	plugin := NewPaginationPlugin()
+	exec := executor.NewSimpleExecutor(&config.Configuration{DataSource: &config.DataSource{DB: db}})
 
 	// 创建分页请求
 	pageRequest := &PageRequest{
-		Page: 1,
+		Page: 2,
 		Size: 10,
 	}
-
-	method := reflect.Method{
-		Name: "SelectUsers",
-		Func: reflect.ValueOf(func() []string { return []string{"user1", "user2"} }),
-	}
+	parameter := map[string]interface{}{"name": "john"}
 
 	invocation := &Invocation{
 		Target:      &struct{}{},
-		Method:      method,
-		Args:        []interface{}{pageRequest},
-		StatementId: "selectUsers",
-		Properties:  map[string]interface{}{"sql": "SELECT * FROM users"},
+		Method:      reflect.Method{Name: "SelectList"},
+		Args:        []interface{}{"UserMapper.selectUsers", parameter, pageRequest},
+		StatementId: "UserMapper.selectUsers",
+		Statement: &config.MapperStatement{
+			ID:            "UserMapper.selectUsers",
+			SQL:           "SELECT name FROM users WHERE name = #{name}",
+			ResultType:    reflect.TypeOf(""),
+			StatementType: config.SELECT,
+		},
+		Parameter: parameter,
+		Executor:  exec,
 	}
 
-	// 模拟 Proceed 方法
+	// Proceed 执行插件替换后的语句
 	invocation.Proceed = func() (interface{}, error) {
-		return []string{"user1", "user2"}, nil
+		return exec.Query(invocation.Statement, invocation.Parameter)
 	}
 
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM users WHERE name = ?")).WithArgs("john").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(25))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name FROM users WHERE name = ? LIMIT ? OFFSET ?")).WithArgs("john", 10, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("john"))
+
 	result, err := plugin.Intercept(invocation)
 	if err != nil {
-		t.Errorf("Unexpected error: %v", err)
+		t.Fatalf("Unexpected error: %v", err)
 	}
 
-	pageResult, ok := result.(*PageResult)
+	pageResult, ok := result.(*PageResult[interface{}])
 	if !ok {
-		t.Errorf("Expected PageResult, got %T", result)
+		t.Fatalf("Expected PageResult, got %T", result)
+	}
+
+	if pageResult.Page != 2 || pageResult.Size != 10 {
+		t.Errorf("Expected page=2, size=10, got page=%d, size=%d", pageResult.Page, pageResult.Size)
+	}
+	if pageResult.Total != 25 || pageResult.TotalPages != 3 || !pageResult.HasNext || !pageResult.HasPrev {
+		t.Errorf("Unexpected page metadata: %+v", pageResult)
+	}
+	if len(pageResult.Data) != 1 {
+		t.Errorf("Expected paged data, got %v", pageResult.Data)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestPaginationPlugin_Dialect 测试分页子句由配置的方言生成，行数与偏移量作为参数绑定
+func TestPaginationPlugin_Dialect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
 	}
+	defer db.Close()
 
-	if pageResult.Page != 1 || pageResult.Size != 10 {
-		t.Errorf("Expected page=1, size=10, got page=%d, size=%d", pageResult.Page, pageResult.Size)
+	configuration := &config.Configuration{DataSource: &config.DataSource{DB: db}, Dialect: dialect.SQLServerDialect{}}
+	exec := executor.NewSimpleExecutor(configuration)
+	parameter := map[string]interface{}{"name": "john"}
+	invocation := &Invocation{
+		Args: []interface{}{"UserMapper.selectUsers", parameter, &PageRequest{Page: 3, Size: 10}},
+		Statement: &config.MapperStatement{
+			ID:            "UserMapper.selectUsers",
+			SQL:           "SELECT name FROM users WHERE name = #{name}",
+			ResultType:    reflect.TypeOf(""),
+			StatementType: config.SELECT,
+		},
+		Parameter:     parameter,
+		Executor:      exec,
+		Configuration: configuration,
+	}
+	invocation.Proceed = func() (interface{}, error) {
+		return exec.Query(invocation.Statement, invocation.Parameter)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM users WHERE name = ?")).WithArgs("john").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(25))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name FROM users WHERE name = ? ORDER BY (SELECT NULL) OFFSET ? ROWS FETCH NEXT ? ROWS ONLY")).
+		WithArgs("john", 20, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("john"))
+
+	if _, err := NewPaginationPlugin().Intercept(invocation); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// stagePlugin 声明拦截点的测试插件
+type stagePlugin struct {
+	name       string
+	signatures []Signature
+	intercept  func(invocation *Invocation) (interface{}, error)
+}
+
+func (p *stagePlugin) Name() string { return p.name }
+
+func (p *stagePlugin) Intercept(invocation *Invocation) (interface{}, error) {
+	return p.intercept(invocation)
+}
+
+func (p *stagePlugin) SetProperties(properties map[string]string) {}
+
+func (p *stagePlugin) GetOrder() int { return 0 }
+
+func (p *stagePlugin) Signatures() []Signature { return p.signatures }
+
+// TestPluginManager_InterceptStages 测试插件拦截执行器的各阶段
+func TestPluginManager_InterceptStages(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	var executorCalls, statementCalls []string
+	var affected int64
+	manager := NewPluginManager()
+	manager.AddPlugin(&stagePlugin{
+		signatures: []Signature{{Target: TargetExecutor}},
+		intercept: func(invocation *Invocation) (interface{}, error) {
+			executorCalls = append(executorCalls, invocation.Signature.Method)
+			return invocation.Proceed()
+		},
+	})
+	manager.AddPlugin(&stagePlugin{
+		signatures: []Signature{{Target: TargetParameterHandler, Method: "SetParameters"}},
+		intercept: func(invocation *Invocation) (interface{}, error) {
+			result, err := invocation.Proceed()
+			if err != nil {
+				return nil, err
+			}
+			// 追加租户条件
+			invocation.BoundSQL.SQL += " AND tenant_id = ?"
+			invocation.BoundSQL.Args = append(invocation.BoundSQL.Args, 7)
+			return result, nil
+		},
+	})
+	manager.AddPlugin(&stagePlugin{
+		signatures: []Signature{{Target: TargetStatementHandler}},
+		intercept: func(invocation *Invocation) (interface{}, error) {
+			statementCalls = append(statementCalls, invocation.Signature.Method+" "+invocation.BoundSQL.StatementID)
+			result, err := invocation.Proceed()
+			if execResult, ok := result.(interface{ RowsAffected() (int64, error) }); ok {
+				affected, _ = execResult.RowsAffected()
+			}
+			return result, err
+		},
+	})
+	manager.AddPlugin(&stagePlugin{
+		signatures: []Signature{{Target: TargetResultSetHandler, Method: "HandleResultSets"}},
+		intercept: func(invocation *Invocation) (interface{}, error) {
+			result, err := invocation.Proceed()
+			if err != nil {
+				return nil, err
+			}
+			rows := result.([]interface{})
+			for i, row := range rows {
+				rows[i] = strings.ToUpper(row.(string))
+			}
+			return rows, nil
+		},
+	})
+	// 未声明拦截点的插件只拦截 SqlSession
+	manager.AddPlugin(&TestPlugin{})
+
+	exec := executor.NewExecutorWithInterceptor(&config.Configuration{DataSource: &config.DataSource{DB: db}}, config.ExecutorSimple, manager)
+	parameter := map[string]interface{}{"id": 1, "name": "jane"}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name FROM users WHERE id = ? AND tenant_id = ?")).WithArgs(1, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("john"))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET name = ? WHERE id = ? AND tenant_id = ?")).WithArgs("jane", 1, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	results, err := exec.Query(&config.MapperStatement{
+		ID:            "UserMapper.selectName",
+		SQL:           "SELECT name FROM users WHERE id = #{id}",
+		ResultType:    reflect.TypeOf(""),
+		StatementType: config.SELECT,
+	}, parameter)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0] != "JOHN" {
+		t.Errorf("Expected results mapped by the plugin, got %v", results)
+	}
+
+	count, err := exec.Update(&config.MapperStatement{
+		ID:            "UserMapper.updateName",
+		SQL:           "UPDATE users SET name = #{name} WHERE id = #{id}",
+		StatementType: config.UPDATE,
+	}, parameter)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if count != 1 || affected != 1 {
+		t.Errorf("Expected 1 affected row, got %d (plugin saw %d)", count, affected)
+	}
+
+	if !reflect.DeepEqual(executorCalls, []string{"Query", "Update"}) {
+		t.Errorf("Unexpected executor calls: %v", executorCalls)
+	}
+	if !reflect.DeepEqual(statementCalls, []string{"Query UserMapper.selectName", "Update UserMapper.updateName"}) {
+		t.Errorf("Unexpected statement handler calls: %v", statementCalls)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestNewPluginManagerWithConfiguration 测试以配置中注册名创建的插件构建插件链
+func TestNewPluginManagerWithConfiguration(t *testing.T) {
+	plugin, err := config.NewPlugin("pagination", map[string]string{"allowedSortColumns": "name"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	pagination, ok := plugin.(*PaginationPlugin)
+	if !ok || !pagination.isAllowedSortColumn("name") || pagination.isAllowedSortColumn("id") {
+		t.Fatalf("Expected configured pagination plugin, got %#v", plugin)
+	}
+
+	cfg := config.NewConfiguration()
+	cfg.AddPlugin(pagination)
+	cfg.AddPlugin(&TestPlugin{order: 10})
+	plugins := NewPluginManagerWithConfiguration(cfg).GetPlugins()
+	if len(plugins) != 2 || plugins[1] != Plugin(pagination) {
+		t.Errorf("Expected plugins from configuration sorted by order, got %v", plugins)
 	}
 }
 
@@ -187,6 +405,43 @@ func TestPageRequest(t *testing.T) {
 	if extractedReq.Offset != expectedOffset {
 		t.Errorf("Expected offset %d, got %d", expectedOffset, extractedReq.Offset)
 	}
+	// 偏移量在副本上计算，调用方的请求可以再次使用
+	if pageReq.Offset != 0 {
+		t.Errorf("Expected caller's request to be unchanged, got offset %d", pageReq.Offset)
+	}
+	pageReq.Page = 3
+	if extractedReq = plugin.extractPageRequest([]interface{}{pageReq}); extractedReq.Offset != 40 {
+		t.Errorf("Expected offset 40 for the reused request, got %d", extractedReq.Offset)
+	}
+
+	// 带整型 Page 与 Size 字段的结构体参数
+	type query struct {
+		Name string
+		Page uint
+		Size int32
+	}
+	if extractedReq = plugin.extractPageRequest([]interface{}{&query{Page: 2, Size: 5}}); extractedReq == nil || extractedReq.Offset != 5 {
+		t.Errorf("Expected page request from struct fields, got %+v", extractedReq)
+	}
+
+	// 字段不是整型时不分页，也不会 panic
+	type textPage struct {
+		Page string
+		Size string
+	}
+	if extractedReq = plugin.extractPageRequest([]interface{}{textPage{Page: "1", Size: "10"}}); extractedReq != nil {
+		t.Errorf("Expected no page request for string fields, got %+v", extractedReq)
+	}
+
+	// 参数 map 中只识别 PageRequest，其他带 Page 与 Size 字段的结构体不触发分页
+	params := map[string]interface{}{"filter": query{Page: 1, Size: 10}}
+	if extractedReq = plugin.extractPageRequest([]interface{}{params}); extractedReq != nil {
+		t.Errorf("Expected struct values in the parameter map to be ignored, got %+v", extractedReq)
+	}
+	params["page"] = PageRequest{Page: 1, Size: 10}
+	if extractedReq = plugin.extractPageRequest([]interface{}{params}); extractedReq == nil || extractedReq.Size != 10 {
+		t.Errorf("Expected page request from the parameter map, got %+v", extractedReq)
+	}
 }
 
 // TestPaginationSQLInjectionPrevention 测试分页插件的SQL注入防护
@@ -228,8 +483,9 @@ func TestPaginationSQLInjectionPrevention(t *testing.T) {
 
 	// 测试buildPagedSQL对不安全排序字段的处理
 	originalSQL := "SELECT * FROM users WHERE status = 'active'"
+	plugin.SetAllowAnySortColumn(true)
 
-	// 不安全的排序字段应该被忽略
+	// 不安全的排序字段即使允许任何列也返回错误
 	unsafePageReq := &PageRequest{
 		Page:    1,
 		Size:    10,
@@ -238,16 +494,102 @@ func TestPaginationSQLInjectionPrevention(t *testing.T) {
 		SortDir: "ASC",
 	}
 
-	pagedSQL := plugin.buildPagedSQL(originalSQL, unsafePageReq)
+	if pagedSQL, err := plugin.buildPagedSQL(originalSQL, unsafePageReq, dialect.GenericDialect{}); err == nil {
+		t.Errorf("Expected error for unsafe sort column, got %s", pagedSQL)
+	}
+
+	// 没有排序字段时只追加分页子句
+	pagedSQL, err := plugin.buildPagedSQL(originalSQL, &PageRequest{Page: 1, Size: 10}, dialect.GenericDialect{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pagedSQL != originalSQL+" LIMIT #{__limit} OFFSET #{__offset}" {
+		t.Errorf("Unexpected paged SQL: %s", pagedSQL)
+	}
+}
+
+// TestPaginationPlugin_Sorts 测试多列排序与允许列表
+func TestPaginationPlugin_Sorts(t *testing.T) {
+	plugin := NewPaginationPlugin()
+	plugin.SetProperties(map[string]string{"allowedSortColumns": "name, u.created_at"})
+
+	pageReq := &PageRequest{Page: 1, Size: 10, Sorts: []SortOrder{
+		{Column: "U.CREATED_AT", Desc: true},
+		{Column: "name"},
+	}}
+	if _, err := plugin.sortOrders(pageReq); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	pagedSQL, err := plugin.buildPagedSQL("SELECT * FROM users u ORDER BY id", pageReq, dialect.GenericDialect{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pagedSQL != "SELECT * FROM users u ORDER BY U.CREATED_AT DESC, name ASC LIMIT #{__limit} OFFSET #{__offset}" {
+		t.Errorf("Unexpected paged SQL: %s", pagedSQL)
+	}
+
+	// SortBy 与 Sorts 中不允许的列同样返回错误
+	intercept := func(plugin *PaginationPlugin, pageReq *PageRequest) error {
+		invocation := &Invocation{
+			Args:    []interface{}{"users.list", pageReq},
+			Proceed: func() (interface{}, error) { return []interface{}{}, nil },
+		}
+		_, err := plugin.Intercept(invocation)
+		return err
+	}
+	for _, column := range []string{"password", "name; DROP TABLE users"} {
+		if err := intercept(plugin, &PageRequest{Page: 1, Size: 10, Sorts: []SortOrder{{Column: column}}}); err == nil {
+			t.Errorf("Expected error for sort column %q", column)
+		}
+		if err := intercept(plugin, &PageRequest{Page: 1, Size: 10, SortBy: column}); err == nil {
+			t.Errorf("Expected error for SortBy %q", column)
+		}
+	}
+
+	// 未设置允许列表时拒绝请求中的排序列，显式允许任何列后只校验列名是否安全
+	unrestricted := NewPaginationPlugin()
+	if err := intercept(unrestricted, &PageRequest{Page: 1, Size: 10, SortBy: "name"}); err == nil {
+		t.Error("Expected error for sort column without an allow-list")
+	}
+	unrestricted.SetProperties(map[string]string{"allowAnySortColumn": "true"})
+	if err := intercept(unrestricted, &PageRequest{Page: 1, Size: 10, SortBy: "password"}); err != nil {
+		t.Errorf("Unexpected error with allowAnySortColumn: %v", err)
+	}
+}
+
+// TestConvertPage 测试分页结果转换为类型化的分页结果
+func TestConvertPage(t *testing.T) {
+	type user struct{ Name string }
+	source := NewPageResult([]interface{}{&user{Name: "a"}, user{Name: "b"}}, 12, &PageRequest{Page: 2, Size: 2})
+
+	converted, err := ConvertPage(source, reflect.TypeOf(&PageResult[user]{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	page := converted.Interface().(*PageResult[user])
+	if len(page.Data) != 2 || page.Data[0].Name != "a" || page.Data[1].Name != "b" {
+		t.Errorf("Unexpected data: %+v", page.Data)
+	}
+	if page.Total != 12 || page.TotalPages != 6 || !page.HasNext || !page.HasPrev {
+		t.Errorf("Unexpected page metadata: %+v", page)
+	}
 
-	// 不应该包含不安全的排序字段
-	if strings.Contains(pagedSQL, "DROP TABLE") {
-		t.Error("Unsafe SQL injection attempt was not prevented")
+	converted, err = ConvertPage(source, reflect.TypeOf(Page[*user]{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value := converted.Interface().(Page[*user]); len(value.Data) != 2 || value.Data[1].Name != "b" {
+		t.Errorf("Unexpected data: %+v", value.Data)
 	}
 
-	// 应该只包含LIMIT子句
-	if !strings.Contains(pagedSQL, "LIMIT 10 OFFSET 0") {
-		t.Error("Expected LIMIT clause in paged SQL")
+	if _, err := ConvertPage([]interface{}{}, reflect.TypeOf(Page[user]{})); err == nil {
+		t.Error("Expected error for non-page result")
+	}
+	if _, err := ConvertPage(source, reflect.TypeOf(Page[int]{})); err == nil {
+		t.Error("Expected error for incompatible row type")
+	}
+	if IsPageType(reflect.TypeOf(CursorPageResult{})) {
+		t.Error("CursorPageResult is not a page type")
 	}
 }
 
@@ -295,3 +637,561 @@ func TestPaginationParameterValidation(t *testing.T) {
 		t.Error("Expected extractPageRequest to return nil for invalid parameters")
 	}
 }
+
+// TestBuildCountSQL 测试计数 SQL 的推导
+func TestBuildCountSQL(t *testing.T) {
+	plugin := NewPaginationPlugin()
+
+	testCases := map[string]string{
+		"SELECT id, name FROM users WHERE name = #{name} ORDER BY id DESC": "SELECT COUNT(*) FROM users WHERE name = #{name}",
+		// 选择列表中的子查询与窗口函数中的 ORDER BY 不影响推导
+		"SELECT id, (SELECT COUNT(*) FROM orders o WHERE o.user_id = u.id) AS n, ROW_NUMBER() OVER (ORDER BY id) FROM users u": "SELECT COUNT(*) FROM users u",
+		"SELECT DISTINCT name FROM users ORDER BY name":                              "SELECT COUNT(*) FROM (SELECT DISTINCT name FROM users) t",
+		"SELECT dept, COUNT(*) FROM users GROUP BY dept":                             "SELECT COUNT(*) FROM (SELECT dept, COUNT(*) FROM users GROUP BY dept) t",
+		"SELECT id FROM users UNION SELECT id FROM admins ORDER BY id":               "SELECT COUNT(*) FROM (SELECT id FROM users UNION SELECT id FROM admins) t",
+		"WITH active AS (SELECT * FROM users WHERE active = 1) SELECT * FROM active": "SELECT COUNT(*) FROM (WITH active AS (SELECT * FROM users WHERE active = 1) SELECT * FROM active) t",
+		// 字符串中的关键字被忽略，LIMIT 前的 ORDER BY 保留
+		"SELECT * FROM users WHERE note = 'order by from' ORDER BY id LIMIT 5": "SELECT COUNT(*) FROM (SELECT * FROM users WHERE note = 'order by from' ORDER BY id LIMIT 5) t",
+	}
+	for sql, expected := range testCases {
+		if actual := plugin.buildCountSQL(sql); actual != expected {
+			t.Errorf("buildCountSQL(%q)\n got: %s\nwant: %s", sql, actual, expected)
+		}
+	}
+}
+
+// TestPaginationPlugin_CountStatement 测试使用 countStatement 指定的计数语句
+func TestPaginationPlugin_CountStatement(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	configuration := config.NewConfiguration()
+	configuration.DataSource = &config.DataSource{DB: db}
+	configuration.MapperConfig.Mappers["UserMapper.countUsers"] = &config.MapperStatement{
+		ID:            "UserMapper.countUsers",
+		SQL:           "SELECT total FROM user_stats",
+		ResultType:    reflect.TypeOf(int(0)),
+		StatementType: config.SELECT,
+	}
+	exec := executor.NewSimpleExecutor(configuration)
+
+	invocation := &Invocation{
+		Args: []interface{}{"UserMapper.listUsers", &PageRequest{Page: 1, Size: 5}},
+		Statement: &config.MapperStatement{
+			ID:             "UserMapper.listUsers",
+			SQL:            "SELECT name FROM users",
+			ResultType:     reflect.TypeOf(""),
+			StatementType:  config.SELECT,
+			CountStatement: "UserMapper.countUsers",
+		},
+		Executor:      exec,
+		Configuration: configuration,
+	}
+	invocation.Proceed = func() (interface{}, error) {
+		return exec.Query(invocation.Statement, invocation.Parameter)
+	}
+
+	mock.ExpectQuery("SELECT total FROM user_stats").
+		WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(7))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name FROM users LIMIT ? OFFSET ?")).WithArgs(5, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("john"))
+
+	result, err := NewPaginationPlugin().Intercept(invocation)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pageResult := result.(*PageResult[interface{}]); pageResult.Total != 7 || pageResult.TotalPages != 2 {
+		t.Errorf("Unexpected page metadata: %+v", pageResult)
+	}
+
+	invocation.Statement.CountStatement = "UserMapper.missing"
+	if _, err := NewPaginationPlugin().Intercept(invocation); err == nil {
+		t.Error("Expected error for missing count statement")
+	}
+}
+
+// cursorEvent 键集分页测试的结果类型
+type cursorEvent struct {
+	ID      int64     `db:"id"`
+	Created time.Time `db:"created_at"`
+}
+
+// TestPaginationPlugin_Cursor 测试键集分页改写语句并返回游标
+func TestPaginationPlugin_Cursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	exec := executor.NewSimpleExecutor(&config.Configuration{DataSource: &config.DataSource{DB: db}})
+	statement := &config.MapperStatement{
+		ID:            "EventMapper.listEvents",
+		SQL:           "SELECT id, created_at FROM events WHERE kind = #{kind} ORDER BY id",
+		ResultType:    reflect.TypeOf(cursorEvent{}),
+		StatementType: config.SELECT,
+	}
+	page := func(request *CursorPageRequest) *CursorPageResult {
+		parameter := map[string]interface{}{"kind": "login"}
+		invocation := &Invocation{
+			Args:      []interface{}{statement.ID, parameter, request},
+			Statement: statement,
+			Parameter: parameter,
+			Executor:  exec,
+		}
+		invocation.Proceed = func() (interface{}, error) {
+			return exec.Query(invocation.Statement, invocation.Parameter)
+		}
+		result, err := NewPaginationPlugin().Intercept(invocation)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return result.(*CursorPageResult)
+	}
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	eventRows := func(ids ...int64) *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"id", "created_at"})
+		for _, id := range ids {
+			rows.AddRow(id, created)
+		}
+		return rows
+	}
+	sortKeys := []string{"created_at", "id"}
+
+	// 第一页多取一行判断是否有下一页
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM (SELECT id, created_at FROM events WHERE kind = ?) t ORDER BY created_at ASC, id ASC LIMIT ?")).
+		WithArgs("login", 3).WillReturnRows(eventRows(1, 2, 3))
+	first := page(&CursorPageRequest{Size: 2, SortKeys: sortKeys})
+	if len(first.Data.([]interface{})) != 2 || !first.HasNext || first.HasPrev || first.NextCursor == "" {
+		t.Fatalf("Unexpected first page: %+v", first)
+	}
+
+	// 下一页以游标中的值定位，通用方言不使用行值比较
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM (SELECT id, created_at FROM events WHERE kind = ?) t "+
+		"WHERE ((created_at > ?) OR (created_at = ? AND id > ?)) ORDER BY created_at ASC, id ASC LIMIT ?")).
+		WithArgs("login", created, created, int64(2), 3).WillReturnRows(eventRows(3))
+	second := page(&CursorPageRequest{After: first.NextCursor, Size: 2, SortKeys: sortKeys})
+	if len(second.Data.([]interface{})) != 1 || second.HasNext || !second.HasPrev || second.PrevCursor == "" {
+		t.Fatalf("Unexpected second page: %+v", second)
+	}
+
+	// 向前翻页反向查询后恢复顺序
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM (SELECT id, created_at FROM events WHERE kind = ?) t "+
+		"WHERE ((created_at < ?) OR (created_at = ? AND id < ?)) ORDER BY created_at DESC, id DESC LIMIT ?")).
+		WithArgs("login", created, created, int64(3), 3).WillReturnRows(eventRows(2, 1))
+	previous := page(&CursorPageRequest{Before: second.PrevCursor, Size: 2, SortKeys: sortKeys})
+	data := previous.Data.([]interface{})
+	if len(data) != 2 || data[0].(cursorEvent).ID != 1 || !previous.HasNext || previous.HasPrev {
+		t.Fatalf("Unexpected previous page: %+v", previous)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestCursorPageRequest_BuildCursorSQL 测试键集条件与分页子句随方言变化，以及 NULL 游标值被拒绝
+func TestCursorPageRequest_BuildCursorSQL(t *testing.T) {
+	request := &CursorPageRequest{Size: 2, SortKeys: []string{"e.created_at", "e.id"}}
+	values := []interface{}{"2024-01-01", int64(2)}
+
+	testCases := []struct {
+		dialect  dialect.Dialect
+		expected string
+	}{
+		{dialect.PostgreSQLDialect{}, "SELECT * FROM (SELECT * FROM events e) t WHERE (created_at, id) > (#{__cursor_0}, #{__cursor_1}) " +
+			"ORDER BY created_at ASC, id ASC LIMIT #{__limit}"},
+		{dialect.SQLServerDialect{}, "SELECT * FROM (SELECT * FROM events e) t WHERE ((created_at > #{__cursor_0}) OR (created_at = #{__cursor_0} AND id > #{__cursor_1})) " +
+			"ORDER BY created_at ASC, id ASC OFFSET 0 ROWS FETCH NEXT #{__limit} ROWS ONLY"},
+	}
+	for _, tc := range testCases {
+		sql, additional := request.buildCursorSQL("SELECT * FROM events e ORDER BY e.id", values, false, tc.dialect)
+		if sql != tc.expected {
+			t.Errorf("%s:\n got: %s\nwant: %s", tc.dialect.Name(), sql, tc.expected)
+		}
+		if additional[limitParameter] != 3 || additional["__cursor_1"] != int64(2) {
+			t.Errorf("%s: unexpected parameters %v", tc.dialect.Name(), additional)
+		}
+	}
+
+	// 排序列为 NULL 时无法生成或使用游标
+	if _, err := rowCursor(map[string]interface{}{"created_at": nil, "id": 1}, request.SortKeys, nil); err == nil {
+		t.Error("Expected error for null sort key")
+	}
+	cursor, err := EncodeCursor([]interface{}{nil, int64(1)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, _, err := (&CursorPageRequest{After: cursor, Size: 2, SortKeys: request.SortKeys}).seek(); err == nil {
+		t.Error("Expected error for null cursor value")
+	}
+}
+
+// TestCursorPageRequest_ApplyTo 测试键集分页与 Example 配合使用
+func TestCursorPageRequest_ApplyTo(t *testing.T) {
+	cursor, err := EncodeCursor([]interface{}{int64(42)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	e := example.NewExample()
+	e.CreateCriteria().AndEqualTo("kind", "login")
+	request := &CursorPageRequest{After: cursor, Size: 10, SortKeys: []string{"id"}, Desc: true}
+	if err := request.ApplyTo(e); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sql, args := e.BuildSQL("SELECT * FROM events")
+	expected := "SELECT * FROM events WHERE ((kind = ?)) AND (id) < (?) ORDER BY id DESC LIMIT 0, 11"
+	if sql != expected || len(args) != 2 || args[1] != int64(42) {
+		t.Errorf("Unexpected SQL %q with args %v", sql, args)
+	}
+
+	if err := (&CursorPageRequest{After: "!", Size: 10, SortKeys: []string{"id"}}).ApplyTo(e); err == nil {
+		t.Error("Expected error for invalid cursor")
+	}
+	if err := (&CursorPageRequest{Size: 10, SortKeys: []string{"id; DROP TABLE"}}).ApplyTo(e); err == nil {
+		t.Error("Expected error for unsafe sort key")
+	}
+}
+
+// TestPluginChain_RetryAndConcurrentProceed 测试插件重复与并发调用 Proceed
+func TestPluginChain_RetryAndConcurrentProceed(t *testing.T) {
+	var mutex sync.Mutex
+	var inner, targets int
+	attempts := 0
+
+	manager := NewPluginManager()
+	// 失败时重试一次
+	manager.AddPlugin(&stagePlugin{
+		signatures: []Signature{{Target: TargetSqlSession}},
+		intercept: func(invocation *Invocation) (interface{}, error) {
+			invocation.Parameter = "retried"
+			if result, err := invocation.Proceed(); err == nil {
+				return result, nil
+			}
+			return invocation.Proceed()
+		},
+	})
+	manager.AddPlugin(&stagePlugin{
+		signatures: []Signature{{Target: TargetSqlSession}},
+		intercept: func(invocation *Invocation) (interface{}, error) {
+			mutex.Lock()
+			inner++
+			mutex.Unlock()
+			return invocation.Proceed()
+		},
+	})
+
+	invocation := &Invocation{Signature: Signature{Target: TargetSqlSession, Method: "SelectList"}}
+	result, err := manager.Execute(invocation, func(current *Invocation) (interface{}, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, errors.New("deadlock")
+		}
+		return current.Parameter, nil
+	})
+	if err != nil || result != "retried" {
+		t.Fatalf("Expected retried result, got %v, %v", result, err)
+	}
+	if inner != 2 || attempts != 2 {
+		t.Errorf("Expected inner plugin and target to run twice, got %d and %d", inner, attempts)
+	}
+
+	// 同一插件链被并发执行，插件内部也并发调用 Proceed
+	fanOut := NewPluginManager()
+	fanOut.AddPlugin(&stagePlugin{
+		signatures: []Signature{{Target: TargetSqlSession}},
+		intercept: func(invocation *Invocation) (interface{}, error) {
+			var wg sync.WaitGroup
+			for i := 0; i < 2; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					invocation.Proceed()
+				}()
+			}
+			wg.Wait()
+			return nil, nil
+		},
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fanOut.Execute(&Invocation{Signature: Signature{Target: TargetSqlSession, Method: "Update"}}, func(*Invocation) (interface{}, error) {
+				mutex.Lock()
+				targets++
+				mutex.Unlock()
+				return int64(1), nil
+			})
+		}()
+	}
+	wg.Wait()
+	if targets != 16 {
+		t.Errorf("Expected 16 target calls, got %d", targets)
+	}
+}
+
+// TestPluginManager_StatementScope 测试按插件的适用范围与语句的 plugins 属性筛选插件链
+func TestPluginManager_StatementScope(t *testing.T) {
+	var calls []string
+	newPlugin := func(name string) *stagePlugin {
+		return &stagePlugin{
+			name:       name,
+			signatures: []Signature{{Target: TargetSqlSession}},
+			intercept: func(invocation *Invocation) (interface{}, error) {
+				calls = append(calls, name)
+				return invocation.Proceed()
+			},
+		}
+	}
+
+	manager := NewPluginManager()
+	manager.AddPlugin(config.NewScopedPlugin(newPlugin("tenant"), config.StatementScope{ExcludeNamespaces: []string{"admin*"}}))
+	manager.AddPlugin(config.NewScopedPlugin(newPlugin("audit"), config.StatementScope{StatementTypes: []config.StatementType{config.UPDATE, config.DELETE}}))
+	manager.AddPlugin(newPlugin("pagination"))
+
+	tests := []struct {
+		statement config.MapperStatement
+		expected  string
+	}{
+		{config.MapperStatement{ID: "UserMapper.select", Namespace: "UserMapper"}, "tenant,pagination"},
+		{config.MapperStatement{ID: "UserMapper.update", Namespace: "UserMapper", StatementType: config.UPDATE}, "tenant,audit,pagination"},
+		{config.MapperStatement{ID: "adminUser.select", Namespace: "adminUser"}, "pagination"},
+		{config.MapperStatement{ID: "UserMapper.selectAll", Namespace: "UserMapper", Plugins: "!pagination"}, "tenant"},
+		{config.MapperStatement{ID: "adminUser.selectAll", Namespace: "adminUser", Plugins: "tenant"}, "tenant,pagination"},
+		{config.MapperStatement{ID: "UserMapper.raw", Namespace: "UserMapper", Plugins: "!*"}, ""},
+	}
+	for _, tt := range tests {
+		// 第二次执行使用缓存的插件链
+		for i := 0; i < 2; i++ {
+			calls = nil
+			statement := tt.statement
+			invocation := &Invocation{Statement: &statement, Signature: Signature{Target: TargetSqlSession, Method: "SelectList"}}
+			if _, err := manager.Execute(invocation, func(*Invocation) (interface{}, error) { return nil, nil }); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := strings.Join(calls, ","); got != tt.expected {
+				t.Errorf("%s: expected plugins %q, got %q", statement.ID, tt.expected, got)
+			}
+		}
+	}
+
+	raw := &config.MapperStatement{ID: "UserMapper.raw", Plugins: "!*"}
+	if manager.Intercepts(TargetSqlSession, "SelectList", raw) {
+		t.Error("Expected no plugin to intercept a statement that disables all plugins")
+	}
+	if !manager.Intercepts(TargetSqlSession, "SelectList", nil) {
+		t.Error("Expected plugins to intercept when no statement is given")
+	}
+}
+
+// BenchmarkPluginManager_NoMatchingPlugin 没有插件拦截该拦截点时的开销
+func BenchmarkPluginManager_NoMatchingPlugin(b *testing.B) {
+	manager := NewPluginManager()
+	manager.AddPlugin(&stagePlugin{
+		signatures: []Signature{{Target: TargetResultSetHandler}},
+		intercept:  func(invocation *Invocation) (interface{}, error) { return invocation.Proceed() },
+	})
+
+	statement := &config.MapperStatement{ID: "UserMapper.selectUser", Namespace: "UserMapper"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if manager.Intercepts(TargetStatementHandler, "Query", statement) {
+			b.Fatal("unexpected plugin")
+		}
+	}
+}
+
+// BenchmarkPluginManager_Execute 三个插件的插件链
+func BenchmarkPluginManager_Execute(b *testing.B) {
+	manager := NewPluginManager()
+	for i := 0; i < 3; i++ {
+		manager.AddPlugin(&TestPlugin{order: i})
+	}
+	target := func(*Invocation) (interface{}, error) { return nil, nil }
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		invocation := &Invocation{Signature: Signature{Target: TargetSqlSession, Method: "SelectList"}}
+		if _, err := manager.Execute(invocation, target); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// versionedUser 带版本号字段的实体
+type versionedUser struct {
+	ID      int64  `db:"id"`
+	Name    string `db:"name"`
+	Version int    `db:"version,version"`
+}
+
+// TestOptimisticLockPlugin 测试乐观锁插件改写 UPDATE、递增版本号并在冲突时返回 ErrOptimisticLock
+func TestOptimisticLockPlugin(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	cfg := &config.Configuration{DataSource: &config.DataSource{DB: db}}
+	manager := NewPluginManager()
+	manager.AddPlugin(NewOptimisticLockPlugin())
+	exec := executor.NewExecutorWithInterceptor(cfg, config.ExecutorSimple, manager)
+	statement := &config.MapperStatement{
+		ID:            "UserMapper.updateName",
+		SQL:           "UPDATE users SET name = #{name} WHERE id = #{id}",
+		StatementType: config.UPDATE,
+	}
+	lockedSQL := "UPDATE users SET name = ?, version = version + 1 WHERE (id = ?) AND version = ?"
+
+	user := &versionedUser{ID: 1, Name: "john", Version: 3}
+	mock.ExpectExec(regexp.QuoteMeta(lockedSQL)).WithArgs("john", int64(1), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := exec.Update(statement, user); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if user.Version != 4 {
+		t.Errorf("Expected version to be bumped to 4, got %d", user.Version)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(lockedSQL)).WithArgs("john", int64(1), 4).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if _, err := exec.Update(statement, user); !errors.Is(err, ErrOptimisticLock) {
+		t.Fatalf("Expected ErrOptimisticLock, got %v", err)
+	}
+	if user.Version != 4 {
+		t.Errorf("Expected version to stay 4 after a conflict, got %d", user.Version)
+	}
+
+	// 没有版本号字段的参数不改写
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET name = ? WHERE id = ?")).WithArgs("jane", 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if _, err := exec.Update(statement, map[string]interface{}{"name": "jane", "id": 2}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+
+	if !isBatchExecutor(executor.NewExecutorWithInterceptor(cfg, config.ExecutorBatch, manager)) {
+		t.Error("Expected batch executor behind the intercepting executor to be detected")
+	}
+}
+
+// TestOptimisticLockPlugin_ValueParameter 测试以值传递的结构体照常检查版本号，但不递增调用方的字段
+func TestOptimisticLockPlugin_ValueParameter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	cfg := &config.Configuration{DataSource: &config.DataSource{DB: db}}
+	manager := NewPluginManager()
+	manager.AddPlugin(NewOptimisticLockPlugin())
+	exec := executor.NewExecutorWithInterceptor(cfg, config.ExecutorSimple, manager)
+	statement := &config.MapperStatement{
+		ID:            "UserMapper.updateName",
+		SQL:           "UPDATE users SET name = #{name} WHERE id = #{id}",
+		StatementType: config.UPDATE,
+	}
+
+	user := versionedUser{ID: 1, Name: "john", Version: 3}
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET name = ?, version = version + 1 WHERE (id = ?) AND version = ?")).
+		WithArgs("john", int64(1), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	affected, err := exec.Update(statement, user)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if affected != 1 {
+		t.Errorf("Expected 1 affected row, got %d", affected)
+	}
+	if user.Version != 3 {
+		t.Errorf("Expected the caller's copy to keep version 3, got %d", user.Version)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestOptimisticLockPlugin_Batch 测试批量执行器拒绝带版本号的更新，其他更新照常排队
+func TestOptimisticLockPlugin_Batch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	cfg := &config.Configuration{DataSource: &config.DataSource{DB: db}}
+	manager := NewPluginManager()
+	manager.AddPlugin(NewOptimisticLockPlugin())
+	exec := executor.NewExecutorWithInterceptor(cfg, config.ExecutorBatch, manager)
+	statement := &config.MapperStatement{
+		ID:            "UserMapper.updateName",
+		SQL:           "UPDATE users SET name = #{name} WHERE id = #{id}",
+		StatementType: config.UPDATE,
+	}
+
+	user := &versionedUser{ID: 1, Name: "john", Version: 3}
+	if _, err := exec.Update(statement, user); err == nil {
+		t.Fatal("Expected versioned update to be rejected by the batch executor")
+	}
+	if user.Version != 3 {
+		t.Errorf("Expected version to stay 3, got %d", user.Version)
+	}
+
+	// 被拒绝的更新没有排队，刷新时只执行其他更新
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET name = ? WHERE id = ?")).WithArgs("jane", 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := exec.Update(statement, map[string]interface{}{"name": "jane", "id": 2}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	results, err := exec.FlushStatements()
+	if err != nil {
+		t.Fatalf("FlushStatements failed: %v", err)
+	}
+	if len(results) != 1 || len(results[0].Parameters) != 1 {
+		t.Errorf("Expected only the unversioned update to be flushed, got %+v", results)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// TestBuildOptimisticLockSQL 测试版本号条件与递增的追加位置
+func TestBuildOptimisticLockSQL(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+	}{
+		{
+			"UPDATE users SET name = #{name} WHERE id = #{id} OR email = #{email};",
+			"UPDATE users SET name = #{name}, version = version + 1 WHERE (id = #{id} OR email = #{email}) AND version = #{__version}",
+		},
+		{
+			"UPDATE users SET name = #{name}",
+			"UPDATE users SET name = #{name}, version = version + 1 WHERE version = #{__version}",
+		},
+		{
+			"UPDATE users SET name = #{name} WHERE id IN (SELECT id FROM t WHERE x = 1) RETURNING id",
+			"UPDATE users SET name = #{name}, version = version + 1 WHERE (id IN (SELECT id FROM t WHERE x = 1)) AND version = #{__version} RETURNING id",
+		},
+	}
+	for _, tt := range tests {
+		if got := buildOptimisticLockSQL(tt.sql, "version"); got != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package reflection
+
+import (
+	"database/sql"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// scannerType sql.Scanner 接口类型
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// FieldInfo 结构体字段元数据
+type FieldInfo struct {
+	Name   string       // Go 字段名
+	Tag    DBTag        // 解析后的 db 标签
+	Prefix string       // 外层结构体累积的列名前缀
+	Index  []int        // 字段索引路径，用于逐级访问嵌入结构体
+	Type   reflect.Type // 字段类型
+	depth  int
+}
+
+// fieldCache 按类型缓存的字段元数据
+var fieldCache sync.Map // map[reflect.Type][]FieldInfo
+
+// StructFields 获取结构体的可映射字段，展开嵌入结构体（值或指针）及带 prefix 选项的结构体字段，
+// 同名字段按 Go 的字段提升规则处理：浅层优先，同层仅保留带标签的字段，否则均忽略。
+// 结果按类型缓存并在调用方之间共享，调用方不应修改
+func StructFields(t reflect.Type) []FieldInfo {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]FieldInfo)
+	}
+	fields, _ := fieldCache.LoadOrStore(t, structFields(t))
+	return fields.([]FieldInfo)
+}
+
+// structFields 解析结构体字段
+func structFields(t reflect.Type) []FieldInfo {
+	var fields []FieldInfo
+	collectFields(t, nil, "", 0, map[reflect.Type]bool{}, &fields)
+	return dominantFields(fields)
+}
+
+// collectFields 递归收集字段
+func collectFields(t reflect.Type, index []int, prefix string, depth int, visiting map[reflect.Type]bool, fields *[]FieldInfo) {
+	if visiting[t] {
+		return
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := ParseDBTag(field.Tag.Get("db"))
+		if tag.Name == "-" {
+			continue
+		}
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		fieldType := field.Type
+		structType := fieldType
+		if structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+
+		// 匿名嵌入的结构体，或声明了 prefix 的结构体字段，需要展开；sql.Scanner 视为单个字段
+		flatten := structType.Kind() == reflect.Struct && !reflect.PtrTo(structType).Implements(scannerType) &&
+			((field.Anonymous && tag.Name == "" && tag.TypeHandler() == "") || tag.HasOption("prefix"))
+		if flatten {
+			// 未导出的嵌入指针无法分配，跳过
+			if field.PkgPath != "" && fieldType.Kind() == reflect.Ptr {
+				continue
+			}
+			collectFields(structType, fieldIndex, prefix+tag.Option("prefix"), depth+1, visiting, fields)
+			continue
+		}
+
+		// 跳过未导出字段
+		if field.PkgPath != "" {
+			continue
+		}
+
+		*fields = append(*fields, FieldInfo{
+			Name:   field.Name,
+			Tag:    tag,
+			Prefix: prefix,
+			Index:  fieldIndex,
+			Type:   fieldType,
+			depth:  depth,
+		})
+	}
+}
+
+// dominantFields 按字段提升规则去除被覆盖或有歧义的字段
+func dominantFields(fields []FieldInfo) []FieldInfo {
+	byName := make(map[string][]FieldInfo)
+	var names []string
+	for _, field := range fields {
+		key := field.Prefix + field.Name
+		if _, exists := byName[key]; !exists {
+			names = append(names, key)
+		}
+		byName[key] = append(byName[key], field)
+	}
+
+	result := make([]FieldInfo, 0, len(names))
+	for _, name := range names {
+		if field, ok := dominantField(byName[name]); ok {
+			result = append(result, field)
+		}
+	}
+
+	// 保持字段声明顺序
+	sort.Slice(result, func(i, j int) bool {
+		return lessIndex(result[i].Index, result[j].Index)
+	})
+	return result
+}
+
+// dominantField 在同名字段中选出生效的字段
+func dominantField(candidates []FieldInfo) (FieldInfo, bool) {
+	minDepth := candidates[0].depth
+	for _, field := range candidates[1:] {
+		if field.depth < minDepth {
+			minDepth = field.depth
+		}
+	}
+
+	var shallowest []FieldInfo
+	for _, field := range candidates {
+		if field.depth == minDepth {
+			shallowest = append(shallowest, field)
+		}
+	}
+	if len(shallowest) == 1 {
+		return shallowest[0], true
+	}
+
+	var tagged []FieldInfo
+	for _, field := range shallowest {
+		if field.Tag.Name != "" {
+			tagged = append(tagged, field)
+		}
+	}
+	if len(tagged) == 1 {
+		return tagged[0], true
+	}
+
+	return FieldInfo{}, false
+}
+
+// lessIndex 比较两个索引路径
+func lessIndex(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// FieldByIndex 按索引路径获取字段值，alloc 为 true 时为路径上的 nil 指针分配内存，否则返回 false
+func FieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
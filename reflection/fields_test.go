@@ -0,0 +1,115 @@
+package reflection
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// BaseEntity 公共实体字段
+type BaseEntity struct {
+	ID        int64     `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Address 地址
+type Address struct {
+	City   string `db:"city"`
+	Street string `db:"street"`
+}
+
+// Audit 审计字段
+type Audit struct {
+	CreatedBy string
+	Note      string
+}
+
+// Remark 备注字段，与 Audit.Note 同层同名
+type Remark struct {
+	Note string
+}
+
+// Order 测试用订单
+type Order struct {
+	*BaseEntity
+	Audit
+	Remark
+	Name     string  `db:"name"`
+	Billing  Address `db:",prefix=billing_"`
+	Shipping Address `db:",prefix=shipping_"`
+	Secret   string  `db:"-"`
+	internal string
+}
+
+// TestStructFields 测试字段展开与提升规则
+func TestStructFields(t *testing.T) {
+	fields := StructFields(reflect.TypeOf(Order{}))
+
+	names := make(map[string]FieldInfo)
+	for _, field := range fields {
+		names[field.Prefix+field.Name] = field
+	}
+
+	for _, expected := range []string{"ID", "CreatedAt", "UpdatedAt", "CreatedBy", "Name", "billing_City", "billing_Street", "shipping_City", "shipping_Street"} {
+		if _, exists := names[expected]; !exists {
+			t.Errorf("Expected field %s, got %v", expected, names)
+		}
+	}
+
+	// 同层同名字段有歧义，应被忽略
+	if _, exists := names["Note"]; exists {
+		t.Error("Expected ambiguous field Note to be dropped")
+	}
+
+	if _, exists := names["Secret"]; exists {
+		t.Error("Expected db:\"-\" field to be skipped")
+	}
+
+	if _, exists := names["internal"]; exists {
+		t.Error("Expected unexported field to be skipped")
+	}
+
+	if id := names["ID"]; !reflect.DeepEqual(id.Index, []int{0, 0}) {
+		t.Errorf("Unexpected index for ID: %v", id.Index)
+	}
+
+	if fields[0].Name != "ID" {
+		t.Errorf("Expected fields in declaration order, got %s first", fields[0].Name)
+	}
+}
+
+// TestStructFields_Shadowing 测试浅层字段覆盖嵌入字段
+func TestStructFields_Shadowing(t *testing.T) {
+	type user struct {
+		BaseEntity
+		ID string `db:"user_id"`
+	}
+
+	fields := StructFields(reflect.TypeOf(user{}))
+	for _, field := range fields {
+		if field.Name == "ID" && field.Tag.Name != "user_id" {
+			t.Errorf("Expected outer ID to shadow embedded ID, got %+v", field)
+		}
+	}
+}
+
+// TestFieldByIndex 测试按索引访问并分配嵌入指针
+func TestFieldByIndex(t *testing.T) {
+	order := &Order{}
+	v := reflect.ValueOf(order).Elem()
+
+	if _, ok := FieldByIndex(v, []int{0, 0}, false); ok {
+		t.Error("Expected nil embedded pointer to be unreachable without alloc")
+	}
+
+	field, ok := FieldByIndex(v, []int{0, 0}, true)
+	if !ok {
+		t.Fatal("Expected field to be reachable with alloc")
+	}
+	field.SetInt(42)
+
+	if order.BaseEntity == nil || order.ID != 42 {
+		t.Errorf("Expected embedded pointer to be allocated, got %+v", order.BaseEntity)
+	}
+}
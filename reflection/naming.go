@@ -0,0 +1,80 @@
+package reflection
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy 字段名到列名的命名策略
+type NamingStrategy interface {
+	ColumnName(fieldName string) string
+}
+
+// NamingStrategyFunc 函数形式的自定义命名策略
+type NamingStrategyFunc func(fieldName string) string
+
+// ColumnName 实现 NamingStrategy
+func (f NamingStrategyFunc) ColumnName(fieldName string) string {
+	return f(fieldName)
+}
+
+// SnakeCaseStrategy 下划线命名，识别连续大写的缩写词：UserID -> user_id，HTTPCode -> http_code
+type SnakeCaseStrategy struct{}
+
+// ColumnName 实现 NamingStrategy
+func (SnakeCaseStrategy) ColumnName(fieldName string) string {
+	return ToSnakeCase(fieldName)
+}
+
+// LowerCamelStrategy 小驼峰命名：UserID -> userID，HTTPCode -> httpCode
+type LowerCamelStrategy struct{}
+
+// ColumnName 实现 NamingStrategy
+func (LowerCamelStrategy) ColumnName(fieldName string) string {
+	return ToLowerCamel(fieldName)
+}
+
+// IdentityStrategy 列名与字段名相同
+type IdentityStrategy struct{}
+
+// ColumnName 实现 NamingStrategy
+func (IdentityStrategy) ColumnName(fieldName string) string {
+	return fieldName
+}
+
+// DefaultNamingStrategy 默认命名策略
+var DefaultNamingStrategy NamingStrategy = SnakeCaseStrategy{}
+
+// ToSnakeCase 驼峰转下划线，缩写词视为一个单词
+func ToSnakeCase(s string) string {
+	runes := []rune(s)
+	var result strings.Builder
+	result.Grow(len(s) + 4)
+
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			// 小写或数字后的大写字母开始新单词；缩写词末尾的大写字母在其后跟小写字母时开始新单词
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				result.WriteByte('_')
+			}
+		}
+		result.WriteRune(unicode.ToLower(r))
+	}
+
+	return result.String()
+}
+
+// ToLowerCamel 转换为小驼峰，开头的缩写词整体小写
+func ToLowerCamel(s string) string {
+	runes := []rune(s)
+	for i := 0; i < len(runes) && unicode.IsUpper(runes[i]); i++ {
+		// 缩写词后接小写字母时，最后一个大写字母属于下一个单词
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
@@ -0,0 +1,59 @@
+package reflection
+
+import "testing"
+
+// TestToSnakeCase 测试下划线命名
+func TestToSnakeCase(t *testing.T) {
+	testCases := map[string]string{
+		"ID":          "id",
+		"Name":        "name",
+		"FirstName":   "first_name",
+		"CreatedAt":   "created_at",
+		"UserID":      "user_id",
+		"XMLData":     "xml_data",
+		"HTTPCode":    "http_code",
+		"Address2":    "address2",
+		"Line2Street": "line2_street",
+	}
+
+	for input, expected := range testCases {
+		if result := ToSnakeCase(input); result != expected {
+			t.Errorf("ToSnakeCase(%s) = %s, expected %s", input, result, expected)
+		}
+	}
+}
+
+// TestToLowerCamel 测试小驼峰命名
+func TestToLowerCamel(t *testing.T) {
+	testCases := map[string]string{
+		"ID":        "id",
+		"Name":      "name",
+		"UserID":    "userID",
+		"HTTPCode":  "httpCode",
+		"XMLData":   "xmlData",
+		"FirstName": "firstName",
+	}
+
+	for input, expected := range testCases {
+		if result := ToLowerCamel(input); result != expected {
+			t.Errorf("ToLowerCamel(%s) = %s, expected %s", input, result, expected)
+		}
+	}
+}
+
+// TestNamingStrategies 测试内置与自定义命名策略
+func TestNamingStrategies(t *testing.T) {
+	if name := (IdentityStrategy{}).ColumnName("UserID"); name != "UserID" {
+		t.Errorf("IdentityStrategy: got %s", name)
+	}
+	if name := DefaultNamingStrategy.ColumnName("UserID"); name != "user_id" {
+		t.Errorf("DefaultNamingStrategy: got %s", name)
+	}
+
+	custom := NamingStrategyFunc(func(fieldName string) string {
+		return "col_" + ToSnakeCase(fieldName)
+	})
+	if name := custom.ColumnName("UserID"); name != "col_user_id" {
+		t.Errorf("NamingStrategyFunc: got %s", name)
+	}
+}
@@ -0,0 +1,54 @@
+package reflection
+
+import "strings"
+
+// DBTag 解析后的 db 标签，例如 `db:"settings,json"`、`db:",prefix=addr_"`
+type DBTag struct {
+	Name    string            // 列名，可以为空
+	Options map[string]string // 标签选项，无值选项的值为空字符串
+}
+
+// ParseDBTag 解析 db 标签
+func ParseDBTag(tag string) DBTag {
+	parts := strings.Split(tag, ",")
+	dbTag := DBTag{
+		Name:    strings.TrimSpace(parts[0]),
+		Options: make(map[string]string),
+	}
+
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if kv := strings.SplitN(part, "=", 2); len(kv) == 2 {
+			dbTag.Options[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		} else {
+			dbTag.Options[part] = ""
+		}
+	}
+
+	return dbTag
+}
+
+// HasOption 检查是否包含指定选项
+func (t DBTag) HasOption(name string) bool {
+	_, exists := t.Options[name]
+	return exists
+}
+
+// Option 获取选项值
+func (t DBTag) Option(name string) string {
+	return t.Options[name]
+}
+
+// TypeHandler 获取字段声明的具名类型处理器，json 选项等价于 typeHandler=json
+func (t DBTag) TypeHandler() string {
+	if name := t.Option("typeHandler"); name != "" {
+		return name
+	}
+	if t.HasOption("json") {
+		return "json"
+	}
+	return ""
+}
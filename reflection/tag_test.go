@@ -0,0 +1,35 @@
+package reflection
+
+import "testing"
+
+// TestParseDBTag 测试 db 标签解析
+func TestParseDBTag(t *testing.T) {
+	tag := ParseDBTag("settings, json")
+	if tag.Name != "settings" {
+		t.Errorf("Expected name settings, got %s", tag.Name)
+	}
+	if !tag.HasOption("json") {
+		t.Error("Expected json option")
+	}
+	if tag.TypeHandler() != "json" {
+		t.Errorf("Expected json type handler, got %s", tag.TypeHandler())
+	}
+
+	tag = ParseDBTag(",prefix=addr_")
+	if tag.Name != "" {
+		t.Errorf("Expected empty name, got %s", tag.Name)
+	}
+	if tag.Option("prefix") != "addr_" {
+		t.Errorf("Expected prefix addr_, got %s", tag.Option("prefix"))
+	}
+
+	tag = ParseDBTag("tags,typeHandler=strings,json")
+	if tag.TypeHandler() != "strings" {
+		t.Errorf("Expected explicit type handler to win, got %s", tag.TypeHandler())
+	}
+
+	tag = ParseDBTag("")
+	if tag.Name != "" || len(tag.Options) != 0 {
+		t.Errorf("Expected empty tag, got %+v", tag)
+	}
+}
@@ -0,0 +1,323 @@
+package types
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EnumStringTypeHandler 枚举类型处理器，以 String() 名称存储枚举值
+type EnumStringTypeHandler struct {
+	byName map[string]interface{}
+}
+
+// NewEnumStringTypeHandler 创建枚举类型处理器，values 为该枚举的全部取值
+func NewEnumStringTypeHandler(values ...fmt.Stringer) *EnumStringTypeHandler {
+	byName := make(map[string]interface{}, len(values))
+	for _, value := range values {
+		byName[value.String()] = value
+	}
+	return &EnumStringTypeHandler{byName: byName}
+}
+
+// SetParameter 将枚举值转换为名称
+func (h *EnumStringTypeHandler) SetParameter(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	stringer, ok := value.(fmt.Stringer)
+	if !ok {
+		return nil, fmt.Errorf("enum value %v of type %T does not implement fmt.Stringer", value, value)
+	}
+	return stringer.String(), nil
+}
+
+// GetResult 将名称转换为枚举值
+func (h *EnumStringTypeHandler) GetResult(value interface{}, targetType reflect.Type) (interface{}, error) {
+	if value == nil {
+		return reflect.Zero(targetType).Interface(), nil
+	}
+
+	name, err := asString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	enumValue, exists := h.byName[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown enum value %q for %s", name, targetType)
+	}
+	return convertTo(enumValue, targetType)
+}
+
+// JSONTypeHandler JSON 类型处理器，以 JSON 文本存储任意 Go 值
+type JSONTypeHandler struct{}
+
+// SetParameter 将 Go 值序列化为 JSON 文本
+func (h *JSONTypeHandler) SetParameter(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal json: %w", err)
+	}
+	return string(data), nil
+}
+
+// GetResult 将 JSON 文本反序列化为目标类型
+func (h *JSONTypeHandler) GetResult(value interface{}, targetType reflect.Type) (interface{}, error) {
+	if value == nil {
+		return reflect.Zero(targetType).Interface(), nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return convertTo(value, targetType)
+	}
+
+	if len(data) == 0 {
+		return reflect.Zero(targetType).Interface(), nil
+	}
+
+	target := reflect.New(targetType)
+	if err := json.Unmarshal(data, target.Interface()); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal json into %s: %w", targetType, err)
+	}
+	return target.Elem().Interface(), nil
+}
+
+// StringSliceTypeHandler 字符串切片类型处理器，以分隔符拼接的文本存储 []string
+type StringSliceTypeHandler struct {
+	Delimiter string
+}
+
+// SetParameter 将字符串切片拼接为文本
+func (h *StringSliceTypeHandler) SetParameter(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.String {
+		return nil, fmt.Errorf("expected string slice, got %T", value)
+	}
+	if v.IsNil() {
+		return nil, nil
+	}
+
+	parts := make([]string, v.Len())
+	for i := range parts {
+		parts[i] = v.Index(i).String()
+	}
+	return strings.Join(parts, h.delimiter()), nil
+}
+
+// GetResult 将文本拆分为字符串切片
+func (h *StringSliceTypeHandler) GetResult(value interface{}, targetType reflect.Type) (interface{}, error) {
+	if value == nil {
+		return reflect.Zero(targetType).Interface(), nil
+	}
+
+	text, err := asString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := []string{}
+	if text != "" {
+		parts = strings.Split(text, h.delimiter())
+	}
+	return convertTo(parts, targetType)
+}
+
+// delimiter 获取分隔符，默认为逗号
+func (h *StringSliceTypeHandler) delimiter() string {
+	if h.Delimiter == "" {
+		return ","
+	}
+	return h.Delimiter
+}
+
+// decimalPattern 十进制数：可带符号、小数部分与十进制指数，不接受 NaN、Inf 与十六进制，位数不限
+var decimalPattern = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+
+// DecimalStringTypeHandler 十进制类型处理器，以字符串承载 DECIMAL 值以避免精度损失
+type DecimalStringTypeHandler struct{}
+
+// SetParameter 校验并传递十进制字符串
+func (h *DecimalStringTypeHandler) SetParameter(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	text, err := h.format(value)
+	if err != nil {
+		return nil, err
+	}
+	if text == "" {
+		return nil, nil
+	}
+	if !decimalPattern.MatchString(text) {
+		return nil, fmt.Errorf("invalid decimal value %q", text)
+	}
+	return text, nil
+}
+
+// GetResult 将数据库的十进制值转换为字符串
+func (h *DecimalStringTypeHandler) GetResult(value interface{}, targetType reflect.Type) (interface{}, error) {
+	if value == nil {
+		return reflect.Zero(targetType).Interface(), nil
+	}
+
+	text, err := h.format(value)
+	if err != nil {
+		return nil, err
+	}
+	return convertTo(text, targetType)
+}
+
+// format 将数值统一格式化为不带指数的字符串
+func (h *DecimalStringTypeHandler) format(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case int:
+		return strconv.Itoa(v), nil
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.String {
+		return v.String(), nil
+	}
+	return "", fmt.Errorf("unsupported decimal value type: %T", value)
+}
+
+// UUIDBytesTypeHandler UUID 类型处理器，以 16 字节二进制存储 UUID
+type UUIDBytesTypeHandler struct{}
+
+// SetParameter 将 UUID 转换为 16 字节切片
+func (h *UUIDBytesTypeHandler) SetParameter(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	if s, ok := value.(string); ok {
+		return parseUUID(s)
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Array && v.Len() == 16 && v.Type().Elem().Kind() == reflect.Uint8 {
+		data := make([]byte, 16)
+		reflect.Copy(reflect.ValueOf(data), v)
+		return data, nil
+	}
+	if data, ok := value.([]byte); ok && len(data) == 16 {
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("unsupported uuid value type: %T", value)
+}
+
+// GetResult 将 16 字节二进制或文本 UUID 转换为目标类型
+func (h *UUIDBytesTypeHandler) GetResult(value interface{}, targetType reflect.Type) (interface{}, error) {
+	if value == nil {
+		return reflect.Zero(targetType).Interface(), nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		if len(v) == 16 {
+			data = v
+		} else {
+			parsed, err := parseUUID(string(v))
+			if err != nil {
+				return nil, err
+			}
+			data = parsed
+		}
+	case string:
+		parsed, err := parseUUID(v)
+		if err != nil {
+			return nil, err
+		}
+		data = parsed
+	default:
+		return nil, fmt.Errorf("unsupported uuid column type: %T", value)
+	}
+
+	switch targetType.Kind() {
+	case reflect.String:
+		return convertTo(formatUUID(data), targetType)
+	case reflect.Array:
+		if targetType.Len() != 16 || targetType.Elem().Kind() != reflect.Uint8 {
+			break
+		}
+		result := reflect.New(targetType).Elem()
+		reflect.Copy(result, reflect.ValueOf(data))
+		return result.Interface(), nil
+	case reflect.Slice:
+		if targetType.Elem().Kind() == reflect.Uint8 {
+			return convertTo(append([]byte(nil), data...), targetType)
+		}
+	}
+
+	return nil, fmt.Errorf("cannot convert uuid to %s", targetType)
+}
+
+// parseUUID 解析标准格式的 UUID 文本
+func parseUUID(s string) ([]byte, error) {
+	data, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil || len(data) != 16 {
+		return nil, fmt.Errorf("invalid uuid %q", s)
+	}
+	return data, nil
+}
+
+// formatUUID 将 16 字节格式化为标准 UUID 文本
+func formatUUID(data []byte) string {
+	s := hex.EncodeToString(data)
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:32]
+}
+
+// asString 将列值转换为字符串
+func asString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		return "", fmt.Errorf("expected string column value, got %T", value)
+	}
+}
+
+// convertTo 将值转换为目标类型
+func convertTo(value interface{}, targetType reflect.Type) (interface{}, error) {
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(targetType) {
+		return value, nil
+	}
+	if v.Type().ConvertibleTo(targetType) {
+		return v.Convert(targetType).Interface(), nil
+	}
+	return nil, fmt.Errorf("cannot convert %T to %s", value, targetType)
+}
@@ -0,0 +1,100 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// TypeHandler 类型处理器接口，负责 Go 值与数据库值之间的相互转换
+type TypeHandler interface {
+	// SetParameter 将 Go 值转换为可传递给驱动的参数值
+	SetParameter(value interface{}) (interface{}, error)
+	// GetResult 将数据库返回的列值转换为目标 Go 类型
+	GetResult(value interface{}, targetType reflect.Type) (interface{}, error)
+}
+
+// handlerKey 类型处理器注册键
+type handlerKey struct {
+	goType   reflect.Type
+	jdbcType string
+}
+
+// TypeHandlerRegistry 类型处理器注册表 - 线程安全
+type TypeHandlerRegistry struct {
+	handlers map[handlerKey]TypeHandler
+	named    map[string]TypeHandler
+	mutex    sync.RWMutex
+}
+
+// Default 默认类型处理器注册表
+var Default = NewTypeHandlerRegistry()
+
+// NewTypeHandlerRegistry 创建类型处理器注册表，并注册内置处理器；
+// []string 与 [16]byte 的处理器会改变已有字段的存储格式，只注册为具名处理器，需以 typeHandler 选用
+func NewTypeHandlerRegistry() *TypeHandlerRegistry {
+	r := &TypeHandlerRegistry{
+		handlers: make(map[handlerKey]TypeHandler),
+		named:    make(map[string]TypeHandler),
+	}
+
+	stringSlice := &StringSliceTypeHandler{Delimiter: ","}
+	uuidBytes := &UUIDBytesTypeHandler{}
+	decimal := &DecimalStringTypeHandler{}
+
+	r.RegisterWithJdbcType(reflect.TypeOf(""), "DECIMAL", decimal)
+	r.RegisterWithJdbcType(reflect.TypeOf(""), "NUMERIC", decimal)
+
+	r.RegisterNamed("json", &JSONTypeHandler{})
+	r.RegisterNamed("strings", stringSlice)
+	r.RegisterNamed("uuid", uuidBytes)
+	r.RegisterNamed("decimal", decimal)
+
+	return r
+}
+
+// Register 按 Go 类型注册类型处理器
+func (r *TypeHandlerRegistry) Register(goType reflect.Type, handler TypeHandler) {
+	r.RegisterWithJdbcType(goType, "", handler)
+}
+
+// RegisterWithJdbcType 按 Go 类型和 JDBC 类型注册类型处理器
+func (r *TypeHandlerRegistry) RegisterWithJdbcType(goType reflect.Type, jdbcType string, handler TypeHandler) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.handlers[handlerKey{goType: goType, jdbcType: strings.ToUpper(jdbcType)}] = handler
+}
+
+// RegisterNamed 注册具名类型处理器，供 typeHandler=xxx 引用
+func (r *TypeHandlerRegistry) RegisterNamed(name string, handler TypeHandler) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.named[name] = handler
+}
+
+// GetHandler 获取类型处理器，优先匹配 JDBC 类型，其次仅匹配 Go 类型
+func (r *TypeHandlerRegistry) GetHandler(goType reflect.Type, jdbcType string) (TypeHandler, bool) {
+	if goType == nil {
+		return nil, false
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if jdbcType != "" {
+		if handler, exists := r.handlers[handlerKey{goType: goType, jdbcType: strings.ToUpper(jdbcType)}]; exists {
+			return handler, true
+		}
+	}
+
+	handler, exists := r.handlers[handlerKey{goType: goType}]
+	return handler, exists
+}
+
+// GetNamedHandler 获取具名类型处理器
+func (r *TypeHandlerRegistry) GetNamedHandler(name string) (TypeHandler, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	handler, exists := r.named[name]
+	return handler, exists
+}
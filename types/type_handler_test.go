@@ -0,0 +1,243 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Status 测试枚举
+type Status int
+
+const (
+	StatusActive Status = iota + 1
+	StatusDisabled
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusActive:
+		return "ACTIVE"
+	case StatusDisabled:
+		return "DISABLED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Settings 测试 JSON 结构体
+type Settings struct {
+	Theme string   `json:"theme"`
+	Tags  []string `json:"tags"`
+}
+
+// TestNewTypeHandlerRegistry 测试内置处理器注册
+func TestNewTypeHandlerRegistry(t *testing.T) {
+	registry := NewTypeHandlerRegistry()
+
+	// []string 与 [16]byte 的处理器需显式选用，不改变已有字段的绑定方式
+	if _, ok := registry.GetHandler(reflect.TypeOf([]string{}), ""); ok {
+		t.Error("Expected no default handler for []string")
+	}
+
+	if _, ok := registry.GetHandler(reflect.TypeOf([16]byte{}), ""); ok {
+		t.Error("Expected no default handler for [16]byte")
+	}
+
+	if _, ok := registry.GetHandler(reflect.TypeOf(""), ""); ok {
+		t.Error("Expected no handler for plain string")
+	}
+
+	handler, ok := registry.GetHandler(reflect.TypeOf(""), "decimal")
+	if !ok {
+		t.Fatal("Expected DECIMAL handler for string")
+	}
+	if _, isDecimal := handler.(*DecimalStringTypeHandler); !isDecimal {
+		t.Errorf("Expected DecimalStringTypeHandler, got %T", handler)
+	}
+
+	for _, name := range []string{"json", "strings", "uuid", "decimal"} {
+		if _, ok := registry.GetNamedHandler(name); !ok {
+			t.Errorf("Expected named handler %s", name)
+		}
+	}
+}
+
+// TestTypeHandlerRegistry_Register 测试自定义注册与 JDBC 类型优先级
+func TestTypeHandlerRegistry_Register(t *testing.T) {
+	registry := NewTypeHandlerRegistry()
+	statusType := reflect.TypeOf(StatusActive)
+
+	enumHandler := NewEnumStringTypeHandler(StatusActive, StatusDisabled)
+	jsonHandler := &JSONTypeHandler{}
+	registry.Register(statusType, enumHandler)
+	registry.RegisterWithJdbcType(statusType, "JSON", jsonHandler)
+
+	handler, ok := registry.GetHandler(statusType, "")
+	if !ok || handler != enumHandler {
+		t.Error("Expected enum handler for empty jdbc type")
+	}
+
+	handler, ok = registry.GetHandler(statusType, "VARCHAR")
+	if !ok || handler != enumHandler {
+		t.Error("Expected fallback to enum handler for unregistered jdbc type")
+	}
+
+	handler, ok = registry.GetHandler(statusType, "json")
+	if !ok || handler != jsonHandler {
+		t.Error("Expected json handler for JSON jdbc type")
+	}
+}
+
+// TestEnumStringTypeHandler 测试枚举处理器
+func TestEnumStringTypeHandler(t *testing.T) {
+	handler := NewEnumStringTypeHandler(StatusActive, StatusDisabled)
+
+	value, err := handler.SetParameter(StatusDisabled)
+	if err != nil || value != "DISABLED" {
+		t.Errorf("Expected DISABLED, got %v (err: %v)", value, err)
+	}
+
+	result, err := handler.GetResult([]byte("ACTIVE"), reflect.TypeOf(StatusActive))
+	if err != nil || result != StatusActive {
+		t.Errorf("Expected StatusActive, got %v (err: %v)", result, err)
+	}
+
+	if _, err := handler.GetResult("PENDING", reflect.TypeOf(StatusActive)); err == nil {
+		t.Error("Expected error for unknown enum value")
+	}
+
+	if _, err := handler.SetParameter(42); err == nil {
+		t.Error("Expected error for non-Stringer value")
+	}
+}
+
+// TestJSONTypeHandler 测试 JSON 处理器
+func TestJSONTypeHandler(t *testing.T) {
+	handler := &JSONTypeHandler{}
+
+	value, err := handler.SetParameter(Settings{Theme: "dark", Tags: []string{"a"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value != `{"theme":"dark","tags":["a"]}` {
+		t.Errorf("Unexpected json: %v", value)
+	}
+
+	result, err := handler.GetResult([]byte(`{"theme":"light","tags":["x","y"]}`), reflect.TypeOf(Settings{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	settings := result.(Settings)
+	if settings.Theme != "light" || len(settings.Tags) != 2 {
+		t.Errorf("Unexpected settings: %+v", settings)
+	}
+
+	result, err = handler.GetResult(nil, reflect.TypeOf(map[string]int{}))
+	if err != nil || !reflect.ValueOf(result).IsNil() {
+		t.Errorf("Expected nil map for NULL, got %v (err: %v)", result, err)
+	}
+
+	if _, err := handler.GetResult("{invalid", reflect.TypeOf(Settings{})); err == nil {
+		t.Error("Expected error for invalid json")
+	}
+}
+
+// TestStringSliceTypeHandler 测试字符串切片处理器
+func TestStringSliceTypeHandler(t *testing.T) {
+	handler := &StringSliceTypeHandler{Delimiter: "|"}
+
+	value, err := handler.SetParameter([]string{"a", "b", "c"})
+	if err != nil || value != "a|b|c" {
+		t.Errorf("Expected a|b|c, got %v (err: %v)", value, err)
+	}
+
+	value, err = handler.SetParameter([]string(nil))
+	if err != nil || value != nil {
+		t.Errorf("Expected nil for nil slice, got %v (err: %v)", value, err)
+	}
+
+	result, err := handler.GetResult([]byte("x|y"), reflect.TypeOf([]string{}))
+	if err != nil || !reflect.DeepEqual(result, []string{"x", "y"}) {
+		t.Errorf("Expected [x y], got %v (err: %v)", result, err)
+	}
+
+	result, err = handler.GetResult("", reflect.TypeOf([]string{}))
+	if err != nil || len(result.([]string)) != 0 {
+		t.Errorf("Expected empty slice, got %v (err: %v)", result, err)
+	}
+
+	if _, err := handler.SetParameter([]int{1}); err == nil {
+		t.Error("Expected error for non-string slice")
+	}
+}
+
+// TestDecimalStringTypeHandler 测试十进制处理器
+func TestDecimalStringTypeHandler(t *testing.T) {
+	handler := &DecimalStringTypeHandler{}
+
+	result, err := handler.GetResult([]byte("12345678901234567890.123456789"), reflect.TypeOf(""))
+	if err != nil || result != "12345678901234567890.123456789" {
+		t.Errorf("Unexpected decimal result: %v (err: %v)", result, err)
+	}
+
+	result, err = handler.GetResult(float64(0.0000001), reflect.TypeOf(""))
+	if err != nil || result != "0.0000001" {
+		t.Errorf("Expected 0.0000001, got %v (err: %v)", result, err)
+	}
+
+	value, err := handler.SetParameter("99.95")
+	if err != nil || value != "99.95" {
+		t.Errorf("Expected 99.95, got %v (err: %v)", value, err)
+	}
+
+	// 超出 float64 范围的十进制数同样有效
+	for _, valid := range []string{"-0.5", "1e400", "123456789012345678901234567890.000000001"} {
+		if value, err := handler.SetParameter(valid); err != nil || value != valid {
+			t.Errorf("Expected %s to be accepted, got %v (err: %v)", valid, value, err)
+		}
+	}
+
+	for _, invalid := range []string{"abc", "NaN", "Inf", "-infinity", "0x1p3", "1_000", "."} {
+		if _, err := handler.SetParameter(invalid); err == nil {
+			t.Errorf("Expected error for invalid decimal %q", invalid)
+		}
+	}
+}
+
+// TestUUIDBytesTypeHandler 测试 UUID 处理器
+func TestUUIDBytesTypeHandler(t *testing.T) {
+	handler := &UUIDBytesTypeHandler{}
+	text := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+	value, err := handler.SetParameter(text)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data := value.([]byte)
+	if len(data) != 16 || data[0] != 0x6b {
+		t.Errorf("Unexpected uuid bytes: %x", data)
+	}
+
+	result, err := handler.GetResult(data, reflect.TypeOf(""))
+	if err != nil || result != text {
+		t.Errorf("Expected %s, got %v (err: %v)", text, result, err)
+	}
+
+	result, err = handler.GetResult(data, reflect.TypeOf([16]byte{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	array := result.([16]byte)
+	if array[15] != 0xc8 {
+		t.Errorf("Unexpected uuid array: %x", array)
+	}
+
+	value, err = handler.SetParameter(array)
+	if err != nil || len(value.([]byte)) != 16 {
+		t.Errorf("Expected 16 bytes, got %v (err: %v)", value, err)
+	}
+
+	if _, err := handler.SetParameter("not-a-uuid"); err == nil {
+		t.Error("Expected error for invalid uuid")
+	}
+}